@@ -1,65 +1,69 @@
 package main
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
-	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout/cli"
 )
 
-func setupTestCLIAdapter() *CLIAdapter {
-	router := zallocrout.NewRouter()
-	router.AddRoute("CLI", "/user/list", userListCommand, loggingMiddleware, validationMiddleware)
-	router.AddRoute("CLI", "/user/get/:id", userGetCommand, loggingMiddleware, validationMiddleware)
-	router.AddRoute("CLI", "/user/create/:name", userCreateCommand, loggingMiddleware, validationMiddleware)
-	router.AddRoute("CLI", "/config/get/:key", configGetCommand, loggingMiddleware, validationMiddleware)
-	router.AddRoute("CLI", "/help", helpCommand, loggingMiddleware)
-	return &CLIAdapter{router: router}
+func setupTestApp() (*cli.App, *bytes.Buffer) {
+	var out bytes.Buffer
+	app := newApp()
+	cli.WithStdout(&out)(app)
+	return app, &out
 }
 
 func TestCLI_UserList(t *testing.T) {
-	adapter := setupTestCLIAdapter()
-	err := adapter.Execute([]string{"user", "list"})
+	app, _ := setupTestApp()
+	err := app.Run([]string{"user", "list"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
 func TestCLI_UserGet(t *testing.T) {
-	adapter := setupTestCLIAdapter()
-	err := adapter.Execute([]string{"user", "get", "123"})
+	app, _ := setupTestApp()
+	err := app.Run([]string{"user", "get", "123"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
 func TestCLI_UserCreate(t *testing.T) {
-	adapter := setupTestCLIAdapter()
-	err := adapter.Execute([]string{"user", "create", "Alice"})
+	app, out := setupTestApp()
+	err := app.Run([]string{"user", "create", "Alice", "--role", "admin"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if !strings.Contains(out.String(), "Role: admin") {
+		t.Errorf("output should contain role, got: %s", out.String())
+	}
 }
 
 func TestCLI_ConfigGet(t *testing.T) {
-	adapter := setupTestCLIAdapter()
-	err := adapter.Execute([]string{"config", "get", "database.host"})
+	app, _ := setupTestApp()
+	err := app.Run([]string{"config", "get", "database.host"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
 func TestCLI_Help(t *testing.T) {
-	adapter := setupTestCLIAdapter()
-	err := adapter.Execute([]string{"help"})
+	app, out := setupTestApp()
+	err := app.Run([]string{"help"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if !strings.Contains(out.String(), "user create <name>") {
+		t.Errorf("help should list commands, got: %s", out.String())
+	}
 }
 
 func TestCLI_UnknownCommand(t *testing.T) {
-	adapter := setupTestCLIAdapter()
-	err := adapter.Execute([]string{"unknown", "command"})
+	app, _ := setupTestApp()
+	err := app.Run([]string{"unknown", "command"})
 	if err == nil {
 		t.Error("expected error for unknown command")
 	}
@@ -69,28 +73,28 @@ func TestCLI_UnknownCommand(t *testing.T) {
 }
 
 func BenchmarkCLI_UserList(b *testing.B) {
-	adapter := setupTestCLIAdapter()
+	app, _ := setupTestApp()
 	args := []string{"user", "list"}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = adapter.Execute(args)
+		_ = app.Run(args)
 	}
 }
 
 func BenchmarkCLI_UserGet(b *testing.B) {
-	adapter := setupTestCLIAdapter()
+	app, _ := setupTestApp()
 	args := []string{"user", "get", "123"}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = adapter.Execute(args)
+		_ = app.Run(args)
 	}
 }
 
 func BenchmarkCLI_UserCreate(b *testing.B) {
-	adapter := setupTestCLIAdapter()
+	app, _ := setupTestApp()
 	args := []string{"user", "create", "Alice"}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = adapter.Execute(args)
+		_ = app.Run(args)
 	}
 }
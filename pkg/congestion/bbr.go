@@ -9,8 +9,63 @@ import (
 // 特点：不依赖丢包检测，而是基于瓶颈链路带宽和最小RTT调整发送速率
 // ------------------------------
 
+type BBRConfig struct {
+	StartupGain      float64       `json:"startup_gain" yaml:"startup_gain"`             // STARTUP阶段发送速率增益（默认2.0）
+	DrainGain        float64       `json:"drain_gain" yaml:"drain_gain"`                 // DRAIN阶段发送速率增益（默认0.5）
+	ProbeBWGain      float64       `json:"probe_bw_gain" yaml:"probe_bw_gain"`           // PROBE_BW阶段发送速率增益（默认1.25）
+	CwndGain         float64       `json:"cwnd_gain" yaml:"cwnd_gain"`                   // 拥塞窗口增益（默认2.0）
+	BandwidthSamples int           `json:"bandwidth_samples" yaml:"bandwidth_samples"`   // 带宽估算滑动窗口样本数（默认10）
+	ProbeRTTInterval time.Duration `json:"probe_rtt_interval" yaml:"probe_rtt_interval"` // 进入PROBE_RTT的间隔（默认10s）
+	ProbeRTTDuration time.Duration `json:"probe_rtt_duration" yaml:"probe_rtt_duration"` // PROBE_RTT持续时间（默认200ms）
+	LossReduction    float64       `json:"loss_reduction" yaml:"loss_reduction"`         // 丢包后窗口保留比例（默认0.9）
+}
+
+func DefaultBBRConfig() BBRConfig {
+	return BBRConfig{
+		StartupGain:      2.0,
+		DrainGain:        0.5,
+		ProbeBWGain:      1.25,
+		CwndGain:         2.0,
+		BandwidthSamples: 10,
+		ProbeRTTInterval: 10 * time.Second,
+		ProbeRTTDuration: 200 * time.Millisecond,
+		LossReduction:    0.9,
+	}
+}
+
+// 零值字段使用默认值
+func (c BBRConfig) withDefaults() BBRConfig {
+	def := DefaultBBRConfig()
+	if c.StartupGain <= 0 {
+		c.StartupGain = def.StartupGain
+	}
+	if c.DrainGain <= 0 {
+		c.DrainGain = def.DrainGain
+	}
+	if c.ProbeBWGain <= 0 {
+		c.ProbeBWGain = def.ProbeBWGain
+	}
+	if c.CwndGain <= 0 {
+		c.CwndGain = def.CwndGain
+	}
+	if c.BandwidthSamples <= 0 {
+		c.BandwidthSamples = def.BandwidthSamples
+	}
+	if c.ProbeRTTInterval <= 0 {
+		c.ProbeRTTInterval = def.ProbeRTTInterval
+	}
+	if c.ProbeRTTDuration <= 0 {
+		c.ProbeRTTDuration = def.ProbeRTTDuration
+	}
+	if c.LossReduction <= 0 || c.LossReduction > 1 {
+		c.LossReduction = def.LossReduction
+	}
+	return c
+}
+
 type BBRController struct {
 	*BaseController
+	config        BBRConfig
 	bwEstimate    float64
 	bwSamples     []int
 	maxSamples    int
@@ -30,12 +85,18 @@ const (
 )
 
 func NewBBRController(initialCWnd, maxCWnd, packetSize int) *BBRController {
+	return NewBBRControllerWithConfig(DefaultBBRConfig(), initialCWnd, maxCWnd, packetSize)
+}
+
+func NewBBRControllerWithConfig(config BBRConfig, initialCWnd, maxCWnd, packetSize int) *BBRController {
+	config = config.withDefaults()
 	return &BBRController{
 		BaseController: NewBaseController(initialCWnd, maxCWnd, packetSize),
-		bwSamples:      make([]int, 0, 10),
-		maxSamples:     10,
-		pacingGain:     2.0,
-		cwndGain:       2.0,
+		config:         config,
+		bwSamples:      make([]int, 0, config.BandwidthSamples),
+		maxSamples:     config.BandwidthSamples,
+		pacingGain:     config.StartupGain,
+		cwndGain:       config.CwndGain,
 		state:          BBRStartup,
 		lastProbeRTT:   time.Now(),
 	}
@@ -56,29 +117,52 @@ func (b *BBRController) OnAckReceived(ackSize int, rtt time.Duration) {
 	b.ackedBytes += int64(ackSize)
 	b.updateRTT(rtt)
 
-	currentBW := int(float64(ackSize) / rtt.Seconds())
+	// 无投递速率样本时以 ACK字节数/RTT 近似带宽
+	b.onAck(int(float64(ackSize) / rtt.Seconds()))
+}
+
+// OnRateSample 使用投递速率样本估算带宽（实现 RateController）
+func (b *BBRController) OnRateSample(sample RateSample) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ackedBytes += int64(sample.AckedBytes)
+	b.inFlight = sample.BytesInFlight
+	if sample.RTT > 0 {
+		b.updateRTT(sample.RTT)
+	}
+
+	currentBW := int(sample.DeliveryRate())
+	// 应用受限的样本只有高于当前估计时才可信
+	if currentBW == 0 || (sample.IsAppLimited && float64(currentBW) < b.bwEstimate) {
+		currentBW = int(b.bwEstimate)
+	}
+	b.onAck(currentBW)
+}
+
+// onAck 处理带宽样本并推进状态机，调用方需持有锁
+func (b *BBRController) onAck(currentBW int) {
 	b.updateBandwidth(currentBW)
 
 	switch b.state {
 	case BBRStartup:
 		if float64(currentBW) < b.bwEstimate*0.9 {
 			b.state = BBRDrain
-			b.pacingGain = 0.5
+			b.pacingGain = b.config.DrainGain
 		}
 	case BBRDrain:
 		if b.inFlight < int(b.bwEstimate*b.minRTT.Seconds()) {
 			b.state = BBRProbeBW
-			b.pacingGain = 1.25
+			b.pacingGain = b.config.ProbeBWGain
 		}
 	case BBRProbeBW:
-		if time.Since(b.lastProbeRTT) > 10*time.Second && b.state != BBRProbeRTT {
+		if b.now().Sub(b.lastProbeRTT) > b.config.ProbeRTTInterval && b.state != BBRProbeRTT {
 			b.state = BBRProbeRTT
-			b.probeRTTStart = time.Now()
-			b.lastProbeRTT = time.Now()
+			b.probeRTTStart = b.now()
+			b.lastProbeRTT = b.now()
 			b.cwnd = b.packetSize * 4
 		}
 	case BBRProbeRTT:
-		if time.Since(b.probeRTTStart) > 200*time.Millisecond {
+		if b.now().Sub(b.probeRTTStart) > b.config.ProbeRTTDuration {
 			b.state = BBRProbeBW
 		}
 	}
@@ -108,7 +192,7 @@ func (b *BBRController) OnPacketLost() {
 	defer b.mu.Unlock()
 	b.lostBytes += int64(b.packetSize)
 	b.packetsLost++
-	b.cwnd = int(float64(b.cwnd) * 0.9)
+	b.cwnd = int(float64(b.cwnd) * b.config.LossReduction)
 	b.setCongestionWindow(b.cwnd)
 	b.updateStats()
 }
@@ -0,0 +1,521 @@
+package congestion
+
+import (
+	"time"
+)
+
+// ------------------------------
+// BBRv2拥塞控制算法实现（带宽/RTT模型 + 丢包/ECN感知）
+// 特点：在BBR的带宽与最小RTT模型基础上，按轮次统计丢包率与ECN标记率，
+// 通过 inflight_hi/inflight_lo 上下界限制飞行数据量，
+// ProbeBW 细分为 DOWN/CRUISE/REFILL/UP 四个阶段，与CUBIC等基于丢包的流共享链路时更公平
+// ------------------------------
+
+// BBRv2 ProbeBW 阶段常量（STARTUP/DRAIN/PROBE_RTT 与BBR共用）
+const (
+	BBRv2ProbeBWDown   = "PROBE_BW_DOWN"   // 降速阶段：排空探测时积累的队列
+	BBRv2ProbeBWCruise = "PROBE_BW_CRUISE" // 巡航阶段：保留余量稳定发送
+	BBRv2ProbeBWRefill = "PROBE_BW_REFILL" // 填充阶段：重置下界，准备探测
+	BBRv2ProbeBWUp     = "PROBE_BW_UP"     // 探测阶段：提升发送速率并抬高 inflight_hi
+)
+
+type BBRv2Config struct {
+	StartupGain       float64       `json:"startup_gain" yaml:"startup_gain"`               // STARTUP阶段发送速率增益（默认2.77）
+	DrainGain         float64       `json:"drain_gain" yaml:"drain_gain"`                   // DRAIN阶段发送速率增益（默认0.35）
+	ProbeUpGain       float64       `json:"probe_up_gain" yaml:"probe_up_gain"`             // PROBE_BW_UP阶段发送速率增益（默认1.25）
+	ProbeDownGain     float64       `json:"probe_down_gain" yaml:"probe_down_gain"`         // PROBE_BW_DOWN阶段发送速率增益（默认0.9）
+	CwndGain          float64       `json:"cwnd_gain" yaml:"cwnd_gain"`                     // 拥塞窗口增益（默认2.0）
+	LossThreshold     float64       `json:"loss_threshold" yaml:"loss_threshold"`           // 单轮丢包率阈值，超过视为飞行数据过多（默认0.02）
+	ECNThreshold      float64       `json:"ecn_threshold" yaml:"ecn_threshold"`             // 单轮ECN标记率阈值（默认0.5）
+	Beta              float64       `json:"beta" yaml:"beta"`                               // 丢包/ECN过高时上下界的乘性缩减系数（默认0.7）
+	Headroom          float64       `json:"headroom" yaml:"headroom"`                       // 巡航阶段为其他流保留的 inflight_hi 余量比例（默认0.15）
+	BandwidthRounds   int           `json:"bandwidth_rounds" yaml:"bandwidth_rounds"`       // 最大带宽滤波窗口（轮数，默认10）
+	CruiseRounds      int           `json:"cruise_rounds" yaml:"cruise_rounds"`             // 每次带宽探测前的巡航轮数（默认6）
+	StartupLossEvents int           `json:"startup_loss_events" yaml:"startup_loss_events"` // STARTUP阶段单轮丢包事件数达到该值且丢包率过高时退出（默认3）
+	ProbeRTTInterval  time.Duration `json:"probe_rtt_interval" yaml:"probe_rtt_interval"`   // 最小RTT过期时间，过期后进入PROBE_RTT（默认5s）
+	ProbeRTTDuration  time.Duration `json:"probe_rtt_duration" yaml:"probe_rtt_duration"`   // PROBE_RTT持续时间（默认200ms）
+}
+
+func DefaultBBRv2Config() BBRv2Config {
+	return BBRv2Config{
+		StartupGain:       2.77,
+		DrainGain:         0.35,
+		ProbeUpGain:       1.25,
+		ProbeDownGain:     0.9,
+		CwndGain:          2.0,
+		LossThreshold:     0.02,
+		ECNThreshold:      0.5,
+		Beta:              0.7,
+		Headroom:          0.15,
+		BandwidthRounds:   10,
+		CruiseRounds:      6,
+		StartupLossEvents: 3,
+		ProbeRTTInterval:  5 * time.Second,
+		ProbeRTTDuration:  200 * time.Millisecond,
+	}
+}
+
+// 零值字段使用默认值
+func (c BBRv2Config) withDefaults() BBRv2Config {
+	def := DefaultBBRv2Config()
+	if c.StartupGain <= 0 {
+		c.StartupGain = def.StartupGain
+	}
+	if c.DrainGain <= 0 {
+		c.DrainGain = def.DrainGain
+	}
+	if c.ProbeUpGain <= 0 {
+		c.ProbeUpGain = def.ProbeUpGain
+	}
+	if c.ProbeDownGain <= 0 {
+		c.ProbeDownGain = def.ProbeDownGain
+	}
+	if c.CwndGain <= 0 {
+		c.CwndGain = def.CwndGain
+	}
+	if c.LossThreshold <= 0 {
+		c.LossThreshold = def.LossThreshold
+	}
+	if c.ECNThreshold <= 0 {
+		c.ECNThreshold = def.ECNThreshold
+	}
+	if c.Beta <= 0 || c.Beta >= 1 {
+		c.Beta = def.Beta
+	}
+	if c.Headroom < 0 || c.Headroom >= 1 {
+		c.Headroom = def.Headroom
+	}
+	if c.BandwidthRounds <= 0 {
+		c.BandwidthRounds = def.BandwidthRounds
+	}
+	if c.CruiseRounds <= 0 {
+		c.CruiseRounds = def.CruiseRounds
+	}
+	if c.StartupLossEvents <= 0 {
+		c.StartupLossEvents = def.StartupLossEvents
+	}
+	if c.ProbeRTTInterval <= 0 {
+		c.ProbeRTTInterval = def.ProbeRTTInterval
+	}
+	if c.ProbeRTTDuration <= 0 {
+		c.ProbeRTTDuration = def.ProbeRTTDuration
+	}
+	return c
+}
+
+type BBRv2Controller struct {
+	*BaseController
+	config BBRv2Config
+
+	state      string
+	pacingGain float64
+	cwndGain   float64
+
+	// 带宽模型
+	bwSamples     []float64 // 每轮投递速率样本（字节/秒）
+	roundMaxRate  float64   // 本轮投递速率样本最大值（来自 OnRateSample）
+	rateSampled   bool      // 是否收到过投递速率样本（否则按 本轮投递量/RTT 近似）
+	bwEstimate    float64   // 最大带宽估计
+	fullBW        float64   // STARTUP阶段带宽平台检测基准
+	fullBWCount   int       // 带宽未明显增长的连续轮数
+	fullBWReached bool      // 是否已探明瓶颈带宽
+
+	// 轮次（以投递字节数划分，一轮约为一个RTT）
+	delivered       int64 // 累计投递字节数
+	roundEnd        int64 // 本轮结束时的累计投递字节数
+	roundCount      int64 // 已完成轮数
+	phaseRounds     int   // 当前阶段已持续的轮数
+	roundDelivered  int   // 本轮投递字节数
+	roundLost       int   // 本轮丢失字节数
+	roundLossEvents int   // 本轮丢包事件数
+	roundECN        int   // 本轮ECN标记字节数
+	lastLossRate    float64
+	ecnMarked       int64 // 累计ECN标记字节数
+
+	// 飞行数据上下界（0表示不限制）
+	inflightHi int // 基于丢包/ECN的长期上界，UP阶段探测抬高
+	inflightLo int // 非探测阶段出现丢包时的短期下调，REFILL阶段重置
+	probeUpCnt int // UP阶段每轮 inflight_hi 增量（包数，指数增长）
+
+	// 最小RTT与PROBE_RTT
+	minRTTStamp      time.Time
+	probeRTTStart    time.Time
+	probeRTTMin      time.Duration
+	probeRTTRoundEnd bool
+}
+
+func NewBBRv2Controller(initialCWnd, maxCWnd, packetSize int) *BBRv2Controller {
+	return NewBBRv2ControllerWithConfig(DefaultBBRv2Config(), initialCWnd, maxCWnd, packetSize)
+}
+
+func NewBBRv2ControllerWithConfig(config BBRv2Config, initialCWnd, maxCWnd, packetSize int) *BBRv2Controller {
+	config = config.withDefaults()
+	b := &BBRv2Controller{
+		BaseController: NewBaseController(initialCWnd, maxCWnd, packetSize),
+		config:         config,
+		bwSamples:      make([]float64, 0, config.BandwidthRounds),
+	}
+	b.enterState(BBRStartup)
+	b.stats.CurrentState = b.state
+	return b
+}
+
+func (b *BBRv2Controller) OnPacketSent(packetSize int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight += packetSize
+	b.sentBytes += int64(packetSize)
+	b.packetsSent++
+}
+
+func (b *BBRv2Controller) OnAckReceived(ackSize int, rtt time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight -= ackSize
+	if b.inFlight < 0 {
+		b.inFlight = 0
+	}
+	b.onAck(ackSize, rtt)
+}
+
+// OnRateSample 使用投递速率样本估算带宽，并累计样本携带的ECN标记（实现 RateController）
+func (b *BBRv2Controller) OnRateSample(sample RateSample) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight = sample.BytesInFlight
+	if sample.ECNMarkedBytes > 0 {
+		b.roundECN += sample.ECNMarkedBytes
+		b.ecnMarked += int64(sample.ECNMarkedBytes)
+	}
+
+	// 应用受限的样本只有高于当前估计时才可信
+	if rate := sample.DeliveryRate(); rate > 0 && (!sample.IsAppLimited || rate >= b.bwEstimate) {
+		b.rateSampled = true
+		b.roundMaxRate = max(b.roundMaxRate, rate)
+	}
+	b.onAck(sample.AckedBytes, sample.RTT)
+}
+
+// onAck 累计投递量并推进轮次与状态机，调用方需持有锁
+func (b *BBRv2Controller) onAck(ackSize int, rtt time.Duration) {
+	b.ackedBytes += int64(ackSize)
+	b.delivered += int64(ackSize)
+	b.roundDelivered += ackSize
+
+	if rtt > 0 {
+		b.updateRTT(rtt)
+		b.updateMinRTT(rtt)
+	}
+
+	if b.delivered >= b.roundEnd {
+		b.onRoundEnd()
+	}
+	b.checkProbeRTT()
+	b.updateCongestionWindow(ackSize)
+
+	b.stats.CurrentState = b.state
+	b.updateStats()
+	b.stats.LossRate = b.lastLossRate
+}
+
+// OnPacketLost 记录丢包（按一个数据包大小计），在轮次结束时根据丢包率调整上下界
+func (b *BBRv2Controller) OnPacketLost() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lostBytes += int64(b.packetSize)
+	b.packetsLost++
+	b.roundLost += b.packetSize
+	b.roundLossEvents++
+	// 丢失的数据不再占用飞行窗口，重传时会再次计入
+	b.inFlight -= b.packetSize
+	if b.inFlight < 0 {
+		b.inFlight = 0
+	}
+	b.updateStats()
+	b.stats.LossRate = b.lastLossRate
+}
+
+// OnECNMarked 记录收到ECN-CE标记的字节数，与丢包一样在轮次结束时参与过载判断
+func (b *BBRv2Controller) OnECNMarked(markedBytes int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roundECN += markedBytes
+	b.ecnMarked += int64(markedBytes)
+}
+
+func (b *BBRv2Controller) GetCongestionWindow() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.cwnd
+}
+
+func (b *BBRv2Controller) GetSendRate() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.bwEstimate == 0 {
+		if b.rtt == 0 {
+			return 0
+		}
+		return int(float64(b.cwnd) / b.rtt.Seconds())
+	}
+	return int(b.bwEstimate * b.pacingGain)
+}
+
+func (b *BBRv2Controller) GetStatistics() CongestionStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.stats
+}
+
+// InflightBounds 返回当前 inflight_hi/inflight_lo（字节，0表示不限制）
+func (b *BBRv2Controller) InflightBounds() (hi, lo int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.inflightHi, b.inflightLo
+}
+
+// enterState 切换状态并设置对应增益
+func (b *BBRv2Controller) enterState(state string) {
+	b.state = state
+	b.phaseRounds = 0
+	b.cwndGain = b.config.CwndGain
+	switch state {
+	case BBRStartup:
+		b.pacingGain = b.config.StartupGain
+	case BBRDrain:
+		b.pacingGain = b.config.DrainGain
+	case BBRv2ProbeBWDown:
+		b.pacingGain = b.config.ProbeDownGain
+	case BBRv2ProbeBWUp:
+		b.pacingGain = b.config.ProbeUpGain
+		b.probeUpCnt = 1
+	case BBRv2ProbeBWRefill:
+		// 重置短期下界，给带宽探测留出空间
+		b.pacingGain = 1.0
+		b.inflightLo = 0
+	default:
+		b.pacingGain = 1.0
+	}
+}
+
+// updateMinRTT 更新最小RTT及其时间戳（PROBE_RTT期间单独统计）
+func (b *BBRv2Controller) updateMinRTT(rtt time.Duration) {
+	if b.state == BBRProbeRTT {
+		if b.probeRTTMin == 0 || rtt < b.probeRTTMin {
+			b.probeRTTMin = rtt
+		}
+		return
+	}
+	if rtt <= b.minRTT || b.minRTTStamp.IsZero() {
+		b.minRTT = rtt
+		b.minRTTStamp = b.now()
+	}
+}
+
+// onRoundEnd 一轮结束：采样带宽，统计丢包率/ECN标记率并推进状态机
+func (b *BBRv2Controller) onRoundEnd() {
+	b.roundCount++
+	b.phaseRounds++
+
+	if b.rateSampled {
+		if b.roundMaxRate > 0 {
+			b.updateBandwidth(b.roundMaxRate)
+		}
+	} else if b.rtt > 0 && b.roundDelivered > 0 {
+		b.updateBandwidth(float64(b.roundDelivered) / b.rtt.Seconds())
+	}
+	b.roundMaxRate = 0
+
+	lossRate := 0.0
+	if total := b.roundDelivered + b.roundLost; total > 0 {
+		lossRate = float64(b.roundLost) / float64(total)
+	}
+	ecnRate := 0.0
+	if b.roundDelivered > 0 {
+		ecnRate = float64(b.roundECN) / float64(b.roundDelivered)
+	}
+	b.lastLossRate = lossRate
+	tooHigh := lossRate > b.config.LossThreshold || ecnRate > b.config.ECNThreshold
+
+	switch b.state {
+	case BBRStartup:
+		b.checkFullBandwidth()
+		if tooHigh && (b.roundLossEvents >= b.config.StartupLossEvents || ecnRate > b.config.ECNThreshold) {
+			// 启动阶段丢包过多：以当前BDP与本轮投递量为上界，提前结束启动
+			b.inflightHi = max(int(b.bdp()), b.roundDelivered)
+			b.fullBWReached = true
+		}
+		if b.fullBWReached {
+			b.enterState(BBRDrain)
+		}
+	case BBRDrain:
+		if float64(b.inFlight) <= b.bdp() {
+			b.enterState(BBRv2ProbeBWDown)
+		}
+	case BBRv2ProbeBWDown, BBRv2ProbeBWCruise, BBRv2ProbeBWRefill, BBRv2ProbeBWUp:
+		b.updateProbeBW(tooHigh)
+	case BBRProbeRTT:
+		b.probeRTTRoundEnd = true
+	}
+
+	b.roundDelivered = 0
+	b.roundLost = 0
+	b.roundLossEvents = 0
+	b.roundECN = 0
+	b.roundEnd = b.delivered + int64(max(b.inFlight, b.packetSize))
+}
+
+// updateProbeBW ProbeBW 各阶段迁移：DOWN -> CRUISE -> REFILL -> UP -> DOWN
+func (b *BBRv2Controller) updateProbeBW(tooHigh bool) {
+	if tooHigh {
+		b.onInflightTooHigh()
+		if b.state == BBRv2ProbeBWUp {
+			b.enterState(BBRv2ProbeBWDown)
+			return
+		}
+	}
+
+	switch b.state {
+	case BBRv2ProbeBWDown:
+		if float64(b.inFlight) <= b.inflightTarget() {
+			b.enterState(BBRv2ProbeBWCruise)
+		}
+	case BBRv2ProbeBWCruise:
+		if b.phaseRounds >= b.config.CruiseRounds {
+			b.enterState(BBRv2ProbeBWRefill)
+		}
+	case BBRv2ProbeBWRefill:
+		b.enterState(BBRv2ProbeBWUp)
+	case BBRv2ProbeBWUp:
+		// 未出现过载：指数抬高上界
+		if b.inflightHi > 0 {
+			b.inflightHi += b.probeUpCnt * b.packetSize
+			b.probeUpCnt *= 2
+		}
+		// 飞行数据已超过 1.25 倍BDP（队列已建立）时结束探测
+		if float64(b.inFlight) >= b.config.ProbeUpGain*b.bdp() {
+			b.enterState(BBRv2ProbeBWDown)
+		}
+	}
+}
+
+// onInflightTooHigh 丢包率或ECN标记率过高：探测阶段收紧长期上界，其余阶段收紧短期下界
+func (b *BBRv2Controller) onInflightTooHigh() {
+	minInflight := 4 * b.packetSize
+	if b.state == BBRv2ProbeBWUp {
+		hi := int(b.config.Beta * max(b.bdp(), float64(b.cwnd)))
+		b.inflightHi = max(hi, minInflight)
+		return
+	}
+	lo := b.inflightLo
+	if lo == 0 {
+		lo = b.cwnd
+	}
+	// 不低于本轮实际投递量，避免随机丢包把窗口压到链路容量以下
+	b.inflightLo = max(int(b.config.Beta*float64(lo)), b.roundDelivered, minInflight)
+}
+
+// checkFullBandwidth STARTUP阶段连续多轮带宽增长不足25%视为已探明瓶颈带宽
+func (b *BBRv2Controller) checkFullBandwidth() {
+	if b.bwEstimate >= b.fullBW*1.25 {
+		b.fullBW = b.bwEstimate
+		b.fullBWCount = 0
+		return
+	}
+	b.fullBWCount++
+	if b.fullBWCount >= 3 {
+		b.fullBWReached = true
+	}
+}
+
+// checkProbeRTT 最小RTT过期时进入PROBE_RTT，持续足够时间且至少一轮后退出
+func (b *BBRv2Controller) checkProbeRTT() {
+	now := b.now()
+	if b.state != BBRProbeRTT {
+		if !b.minRTTStamp.IsZero() && now.Sub(b.minRTTStamp) > b.config.ProbeRTTInterval {
+			b.enterState(BBRProbeRTT)
+			b.probeRTTStart = now
+			b.probeRTTMin = 0
+			b.probeRTTRoundEnd = false
+		}
+		return
+	}
+
+	if now.Sub(b.probeRTTStart) > b.config.ProbeRTTDuration && b.probeRTTRoundEnd {
+		if b.probeRTTMin > 0 {
+			b.minRTT = b.probeRTTMin
+		}
+		b.minRTTStamp = now
+		if b.fullBWReached {
+			b.enterState(BBRv2ProbeBWCruise)
+		} else {
+			b.enterState(BBRStartup)
+		}
+	}
+}
+
+// updateCongestionWindow 根据BDP与上下界计算拥塞窗口
+func (b *BBRv2Controller) updateCongestionWindow(ackSize int) {
+	minCWnd := 4 * b.packetSize
+	if b.state == BBRProbeRTT {
+		b.setCongestionWindow(minCWnd)
+		return
+	}
+
+	cwnd := b.cwnd + ackSize
+	if target := int(b.bdp() * b.cwndGain); b.fullBWReached && target > 0 && cwnd > target {
+		cwnd = target
+	}
+	// 发送方以窗口驱动（无pacing）时，排空阶段直接把窗口压到目标飞行量以排空队列
+	if b.state == BBRDrain || b.state == BBRv2ProbeBWDown {
+		cwnd = min(cwnd, int(b.inflightTarget()))
+	}
+	if bound := b.inflightBound(); bound > 0 && cwnd > bound {
+		cwnd = bound
+	}
+	if cwnd < minCWnd {
+		cwnd = minCWnd
+	}
+	b.setCongestionWindow(cwnd)
+}
+
+// inflightBound 当前阶段允许的飞行数据上限（0表示不限制）
+func (b *BBRv2Controller) inflightBound() int {
+	bound := b.inflightHi
+	if bound > 0 && b.state == BBRv2ProbeBWCruise {
+		bound = int(float64(bound) * (1 - b.config.Headroom))
+	}
+	if b.inflightLo > 0 && (bound == 0 || b.inflightLo < bound) {
+		bound = b.inflightLo
+	}
+	return bound
+}
+
+// inflightTarget DOWN阶段的目标飞行数据量：BDP与带余量的上界取小
+func (b *BBRv2Controller) inflightTarget() float64 {
+	target := b.bdp()
+	if b.inflightHi > 0 {
+		target = min(target, float64(b.inflightHi)*(1-b.config.Headroom))
+	}
+	return max(target, float64(4*b.packetSize))
+}
+
+// bdp 带宽时延积估计（字节）
+func (b *BBRv2Controller) bdp() float64 {
+	return b.bwEstimate * b.minRTT.Seconds()
+}
+
+// updateBandwidth 最大值滤波：取最近若干轮投递速率样本的最大值
+func (b *BBRv2Controller) updateBandwidth(rate float64) {
+	b.bwSamples = append(b.bwSamples, rate)
+	if len(b.bwSamples) > b.config.BandwidthRounds {
+		b.bwSamples = b.bwSamples[1:]
+	}
+	maxBW := 0.0
+	for _, bw := range b.bwSamples {
+		if bw > maxBW {
+			maxBW = bw
+		}
+	}
+	b.bwEstimate = maxBW
+}
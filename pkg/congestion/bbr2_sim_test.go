@@ -0,0 +1,158 @@
+package congestion_test
+
+// 模拟器 sim 依赖 congestion，链路模拟测试放在外部测试包中以避免循环导入
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/congestion"
+	"github.com/junbin-yang/go-kitbox/pkg/congestion/sim"
+)
+
+// 10Mbps、40ms RTT，BDP=50KB
+func defaultSimLink() sim.LinkConfig {
+	link := sim.DefaultLinkConfig()
+	link.PacketSize = 1000
+	link.Duration = 20 * time.Second
+	link.MaxCWnd = 1000000
+	return link
+}
+
+// simulate 运行模拟，返回各流结果
+func simulate(t *testing.T, link sim.LinkConfig, flows ...sim.Flow) []sim.FlowResult {
+	t.Helper()
+	res, err := sim.Run(link, flows...)
+	if err != nil {
+		t.Fatalf("sim.Run error = %v", err)
+	}
+	return res.Flows
+}
+
+func newSimFlow(t *testing.T, algo congestion.AlgorithmType, link sim.LinkConfig) sim.Flow {
+	t.Helper()
+	f, err := sim.NewFlow(algo, link)
+	if err != nil {
+		t.Fatalf("NewFlow(%s) error = %v", algo, err)
+	}
+	return f
+}
+
+func TestBBRv2ProbeBWCycle(t *testing.T) {
+	link := defaultSimLink()
+	ctrl := congestion.NewBBRv2Controller(10000, link.MaxCWnd, link.PacketSize)
+
+	seen := make(map[string]bool)
+	wrapped := &stateRecorder{BBRv2Controller: ctrl, seen: seen}
+	simulate(t, link, sim.Flow{Name: "bbr2", Controller: wrapped})
+
+	for _, state := range []string{congestion.BBRStartup, congestion.BBRDrain, congestion.BBRv2ProbeBWDown,
+		congestion.BBRv2ProbeBWCruise, congestion.BBRv2ProbeBWRefill, congestion.BBRv2ProbeBWUp} {
+		if !seen[state] {
+			t.Errorf("State %s never visited (visited: %v)", state, seen)
+		}
+	}
+}
+
+// stateRecorder 记录模拟过程中经历的状态
+type stateRecorder struct {
+	*congestion.BBRv2Controller
+	seen map[string]bool
+}
+
+func (s *stateRecorder) OnRateSample(sample congestion.RateSample) {
+	s.BBRv2Controller.OnRateSample(sample)
+	s.seen[s.GetStatistics().CurrentState] = true
+}
+
+func TestSimBBRv2Utilization(t *testing.T) {
+	link := defaultSimLink()
+	res := simulate(t, link, newSimFlow(t, congestion.AlgorithmBBRv2, link))
+
+	util := res[0].Throughput / float64(link.Bandwidth)
+	t.Logf("bbr2 utilization=%.1f%% loss=%.2f%%", util*100, res[0].LossRate*100)
+	if util < 0.8 {
+		t.Errorf("utilization = %.1f%%, want >= 80%%", util*100)
+	}
+}
+
+func TestSimBBRv2RandomLoss(t *testing.T) {
+	// 1% 随机丢包低于丢包率阈值：BBRv2 仍应保持较高吞吐，并优于基于丢包的算法
+	link := defaultSimLink()
+	link.LossRate = 0.01
+
+	throughput := make(map[congestion.AlgorithmType]float64)
+	for _, algo := range []congestion.AlgorithmType{congestion.AlgorithmBBRv2, congestion.AlgorithmCubic, congestion.AlgorithmReno} {
+		res := simulate(t, link, newSimFlow(t, algo, link))
+		throughput[algo] = res[0].Throughput
+		t.Logf("%s throughput=%.0f B/s loss=%.2f%%", algo, throughput[algo], res[0].LossRate*100)
+	}
+
+	if util := throughput[congestion.AlgorithmBBRv2] / float64(link.Bandwidth); util < 0.6 {
+		t.Errorf("bbr2 utilization under random loss = %.1f%%, want >= 60%%", util*100)
+	}
+	if throughput[congestion.AlgorithmBBRv2] <= throughput[congestion.AlgorithmReno] {
+		t.Errorf("bbr2 throughput %.0f should exceed reno %.0f under random loss",
+			throughput[congestion.AlgorithmBBRv2], throughput[congestion.AlgorithmReno])
+	}
+}
+
+func TestSimBBRv2ShallowBuffer(t *testing.T) {
+	// 浅缓冲（0.2倍BDP）：BBRv2 根据丢包率收紧 inflight_hi，在保持吞吐的同时控制重传率
+	link := defaultSimLink()
+	link.BufferSize = 10000
+
+	results := make(map[congestion.AlgorithmType]sim.FlowResult)
+	for _, algo := range []congestion.AlgorithmType{congestion.AlgorithmBBRv2, congestion.AlgorithmBBR, congestion.AlgorithmCubic} {
+		res := simulate(t, link, newSimFlow(t, algo, link))
+		results[algo] = res[0]
+		t.Logf("%s throughput=%.0f B/s loss=%.2f%%", algo, res[0].Throughput, res[0].LossRate*100)
+	}
+
+	bbr2 := results[congestion.AlgorithmBBRv2]
+	if bbr2.LossRate > 0.05 {
+		t.Errorf("bbr2 loss rate = %.2f%%, want <= 5%%", bbr2.LossRate*100)
+	}
+	if util := bbr2.Throughput / float64(link.Bandwidth); util < 0.6 {
+		t.Errorf("bbr2 utilization with shallow buffer = %.1f%%, want >= 60%%", util*100)
+	}
+	// BBR v1 不感知丢包率，浅缓冲下重传率远高于 BBRv2
+	if bbr := results[congestion.AlgorithmBBR]; bbr2.LossRate >= bbr.LossRate {
+		t.Errorf("bbr2 loss rate %.2f%% should be below bbr %.2f%%", bbr2.LossRate*100, bbr.LossRate*100)
+	}
+}
+
+func TestSimBBRv2SharesWithReno(t *testing.T) {
+	// 与基于丢包的Reno共享链路：BBRv2 不应挤占全部带宽
+	link := defaultSimLink()
+	res := simulate(t, link, newSimFlow(t, congestion.AlgorithmBBRv2, link), newSimFlow(t, congestion.AlgorithmReno, link))
+
+	bbr2 := res[0].Throughput
+	reno := res[1].Throughput
+	share := reno / (bbr2 + reno)
+	t.Logf("bbr2=%.0f B/s reno=%.0f B/s reno share=%.1f%%", bbr2, reno, share*100)
+	if share < 0.15 {
+		t.Errorf("reno share = %.1f%%, want >= 15%%", share*100)
+	}
+	if total := (bbr2 + reno) / float64(link.Bandwidth); total < 0.8 {
+		t.Errorf("aggregate utilization = %.1f%%, want >= 80%%", total*100)
+	}
+}
+
+func TestSimBBRv2ECN(t *testing.T) {
+	// 瓶颈在队列超过 0.2 倍BDP 时标记ECN-CE：BBRv2 依据标记率收敛，丢包少于纯丢包驱动
+	link := defaultSimLink()
+	plain := simulate(t, link, newSimFlow(t, congestion.AlgorithmBBRv2, link))[0]
+
+	link.ECNThreshold = 10000
+	marked := simulate(t, link, newSimFlow(t, congestion.AlgorithmBBRv2, link))[0]
+
+	t.Logf("without ecn: throughput=%.0f B/s loss=%.2f%%", plain.Throughput, plain.LossRate*100)
+	t.Logf("with ecn: throughput=%.0f B/s loss=%.2f%%", marked.Throughput, marked.LossRate*100)
+	if marked.LossRate >= plain.LossRate {
+		t.Errorf("loss with ecn %.2f%% should be below %.2f%%", marked.LossRate*100, plain.LossRate*100)
+	}
+	if util := marked.Throughput / float64(link.Bandwidth); util < 0.8 {
+		t.Errorf("utilization with ecn = %.1f%%, want >= 80%%", util*100)
+	}
+}
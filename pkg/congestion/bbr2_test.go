@@ -0,0 +1,88 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBBRv2Startup(t *testing.T) {
+	ctrl := NewBBRv2Controller(2800, 65536, 1400)
+	if ctrl.GetStatistics().CurrentState != BBRStartup {
+		t.Errorf("Initial state = %s, want %s", ctrl.GetStatistics().CurrentState, BBRStartup)
+	}
+
+	// 启动阶段按ACK字节数增长窗口
+	ctrl.OnPacketSent(1400)
+	ctrl.OnAckReceived(1400, 50*time.Millisecond)
+	if ctrl.GetCongestionWindow() <= 2800 {
+		t.Error("Expected cwnd to grow in STARTUP")
+	}
+}
+
+func TestBBRv2ConfigDefaults(t *testing.T) {
+	ctrl := NewBBRv2ControllerWithConfig(BBRv2Config{LossThreshold: 0.05}, 2800, 65536, 1400)
+	if ctrl.config.LossThreshold != 0.05 {
+		t.Errorf("LossThreshold = %v, want 0.05", ctrl.config.LossThreshold)
+	}
+	if ctrl.config.Beta != DefaultBBRv2Config().Beta {
+		t.Errorf("Beta = %v, want default", ctrl.config.Beta)
+	}
+
+	c, err := NewController(AlgorithmBBRv2, 2800, 65536, 1400)
+	if err != nil {
+		t.Fatalf("NewController(bbr2) error = %v", err)
+	}
+	if _, ok := c.(*BBRv2Controller); !ok {
+		t.Errorf("NewController(bbr2) = %T, want *BBRv2Controller", c)
+	}
+}
+
+func TestBBRv2LossBoundsInflight(t *testing.T) {
+	ctrl := NewBBRv2Controller(14000, 1000000, 1400)
+	ctrl.mu.Lock()
+	ctrl.bwEstimate = 1000000
+	ctrl.minRTT = 50 * time.Millisecond
+	ctrl.fullBWReached = true
+	ctrl.enterState(BBRv2ProbeBWUp)
+	ctrl.cwnd = 100000
+	ctrl.mu.Unlock()
+
+	// 一轮内丢包率远超阈值：收紧 inflight_hi 并退出UP阶段
+	for i := 0; i < 10; i++ {
+		ctrl.OnPacketSent(1400)
+		ctrl.OnPacketLost()
+	}
+	ctrl.OnAckReceived(1400, 50*time.Millisecond)
+
+	hi, _ := ctrl.InflightBounds()
+	if hi == 0 || hi >= 100000 {
+		t.Errorf("inflight_hi = %d, expected to be bounded below previous cwnd", hi)
+	}
+	if state := ctrl.GetStatistics().CurrentState; state != BBRv2ProbeBWDown {
+		t.Errorf("State = %s, want %s", state, BBRv2ProbeBWDown)
+	}
+	if ctrl.GetCongestionWindow() > hi {
+		t.Errorf("cwnd = %d exceeds inflight_hi = %d", ctrl.GetCongestionWindow(), hi)
+	}
+}
+
+func TestBBRv2ECNBoundsInflight(t *testing.T) {
+	ctrl := NewBBRv2Controller(14000, 1000000, 1400)
+	ctrl.mu.Lock()
+	ctrl.bwEstimate = 1000000
+	ctrl.minRTT = 50 * time.Millisecond
+	ctrl.fullBWReached = true
+	ctrl.enterState(BBRv2ProbeBWCruise)
+	ctrl.cwnd = 100000
+	ctrl.mu.Unlock()
+
+	// 本轮全部数据被标记ECN-CE：收紧 inflight_lo
+	ctrl.OnPacketSent(1400)
+	ctrl.OnECNMarked(1400)
+	ctrl.OnAckReceived(1400, 50*time.Millisecond)
+
+	_, lo := ctrl.InflightBounds()
+	if lo == 0 || lo >= 100000 {
+		t.Errorf("inflight_lo = %d, expected to be bounded below previous cwnd", lo)
+	}
+}
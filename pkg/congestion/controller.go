@@ -1,7 +1,6 @@
 package congestion
 
 import (
-	"fmt"
 	"sync"
 	"time"
 )
@@ -57,6 +56,7 @@ type BaseController struct {
 	packetsLost        int64
 	fastRetransmits    int64
 	timeoutRetransmits int64
+	now                func() time.Time // 时钟（默认 time.Now）
 }
 
 // 初始化基础控制器
@@ -67,9 +67,21 @@ func NewBaseController(initialCWnd, maxCWnd, packetSize int) *BaseController {
 		maxCWnd:    maxCWnd,
 		packetSize: packetSize,
 		lastUpdate: time.Now(),
+		now:        time.Now,
 	}
 }
 
+// SetClock 替换控制器使用的时钟（如模拟器的虚拟时钟），nil 恢复为 time.Now
+func (b *BaseController) SetClock(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	b.now = now
+	b.lastUpdate = now()
+}
+
 // 更新RTT（通用逻辑：平滑处理新RTT值）
 func (b *BaseController) updateRTT(newRTT time.Duration) {
 	if b.rtt == 0 {
@@ -87,7 +99,7 @@ func (b *BaseController) updateRTT(newRTT time.Duration) {
 
 // 计算发送速率（字节/秒）
 func (b *BaseController) calculateSendRate() int {
-	now := time.Now()
+	now := b.now()
 	elapsed := now.Sub(b.lastUpdate).Seconds()
 	if elapsed == 0 {
 		return 0
@@ -135,21 +147,10 @@ const (
 	AlgorithmBBR   AlgorithmType = "bbr"
 	AlgorithmReno  AlgorithmType = "reno"
 	AlgorithmVegas AlgorithmType = "vegas"
+	AlgorithmBBRv2 AlgorithmType = "bbr2"
 )
 
-// 创建拥塞控制器实例（根据算法类型）
+// 创建拥塞控制器实例（根据算法类型，使用默认配置）
 func NewController(algorithm AlgorithmType, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
-	switch algorithm {
-	case AlgorithmCubic:
-		return NewCubicController(initialCWnd, maxCWnd, packetSize), nil
-	case AlgorithmBBR:
-		return NewBBRController(initialCWnd, maxCWnd, packetSize), nil
-	case AlgorithmReno:
-		return NewRenoController(initialCWnd, maxCWnd, packetSize), nil
-	case AlgorithmVegas:
-		return NewVegasController(initialCWnd, maxCWnd, packetSize), nil
-	default:
-		return nil, fmt.Errorf("不支持的拥塞控制算法: %s（支持的算法：%v）", algorithm,
-			[]AlgorithmType{AlgorithmCubic, AlgorithmBBR, AlgorithmReno, AlgorithmVegas})
-	}
+	return NewControllerWithConfig(algorithm, nil, initialCWnd, maxCWnd, packetSize)
 }
@@ -11,14 +11,26 @@ import (
 // ------------------------------
 
 type CubicConfig struct {
-	Beta float64 // 丢包后窗口缩减系数（默认0.7）
-	C    float64 // CUBIC系数（默认0.4）
+	Beta float64 `json:"beta" yaml:"beta"` // 丢包后窗口缩减系数（默认0.7）
+	C    float64 `json:"c" yaml:"c"`       // CUBIC系数（默认0.4）
 }
 
 func DefaultCubicConfig() CubicConfig {
 	return CubicConfig{Beta: 0.7, C: 0.4}
 }
 
+// 零值字段使用默认值
+func (c CubicConfig) withDefaults() CubicConfig {
+	def := DefaultCubicConfig()
+	if c.Beta <= 0 || c.Beta >= 1 {
+		c.Beta = def.Beta
+	}
+	if c.C <= 0 {
+		c.C = def.C
+	}
+	return c
+}
+
 type CubicController struct {
 	*BaseController
 	beta        float64
@@ -33,6 +45,7 @@ func NewCubicController(initialCWnd, maxCWnd, packetSize int) *CubicController {
 }
 
 func NewCubicControllerWithConfig(config CubicConfig, initialCWnd, maxCWnd, packetSize int) *CubicController {
+	config = config.withDefaults()
 	return &CubicController{
 		BaseController: NewBaseController(initialCWnd, maxCWnd, packetSize),
 		beta:           config.Beta,
@@ -60,7 +73,7 @@ func (c *CubicController) OnAckReceived(ackSize int, rtt time.Duration) {
 	if c.cwnd < c.ssthresh {
 		c.cwnd += c.packetSize
 	} else {
-		elapsed := time.Since(c.epochStart).Seconds()
+		elapsed := c.now().Sub(c.epochStart).Seconds()
 		targetCWnd := int(c.c*(elapsed-c.K)*(elapsed-c.K)*(elapsed-c.K)) + c.lastMaxCWnd
 		if targetCWnd > c.cwnd {
 			inc := targetCWnd - c.cwnd
@@ -83,7 +96,7 @@ func (c *CubicController) OnPacketLost() {
 	c.lastMaxCWnd = c.cwnd
 	c.cwnd = int(float64(c.cwnd) * c.beta)
 	c.ssthresh = c.cwnd
-	c.epochStart = time.Now()
+	c.epochStart = c.now()
 	c.K = math.Pow(float64(c.lastMaxCWnd-c.cwnd)/c.c, 1.0/3.0)
 	c.setCongestionWindow(c.cwnd)
 	c.updateStats()
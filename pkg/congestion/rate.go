@@ -0,0 +1,256 @@
+package congestion
+
+import (
+	"sync"
+	"time"
+)
+
+// ------------------------------
+// 投递速率采样（参考 Linux tcp_rate.c）
+// 发送时记录每个数据包的投递进度快照，确认时根据快照计算该区间的投递速率，
+// 比 "ACK字节数/RTT" 的近似方式更准确，并能标记应用层受限的样本
+// ------------------------------
+
+// RateSample 单次ACK生成的投递速率样本
+type RateSample struct {
+	AckedBytes     int           // 本次ACK新确认的字节数
+	RTT            time.Duration // 本次ACK测得的RTT（0表示无有效测量）
+	Delivered      int64         // 采样区间内投递的字节数
+	Interval       time.Duration // 采样区间时长（发送区间与确认区间取大）
+	BytesInFlight  int           // 处理本次ACK后的飞行字节数
+	LostBytes      int           // 自上一个样本以来检测到的丢失字节数
+	ECNMarkedBytes int           // 本次ACK中被标记ECN-CE的字节数
+	IsAppLimited   bool          // 采样区间内发送受应用层数据量限制（速率可能偏低）
+}
+
+// DeliveryRate 返回样本的投递速率（字节/秒），样本无效时返回0
+func (s RateSample) DeliveryRate() float64 {
+	if s.Interval <= 0 || s.Delivered <= 0 {
+		return 0
+	}
+	return float64(s.Delivered) / s.Interval.Seconds()
+}
+
+// RateController 支持投递速率样本的扩展拥塞控制接口
+// OnRateSample 取代 OnAckReceived 处理ACK；丢包仍通过 OnPacketLost 通知
+type RateController interface {
+	Controller
+	// OnRateSample 收到ACK时调用，携带投递速率、飞行字节数、丢失字节数与ECN标记
+	OnRateSample(sample RateSample)
+}
+
+// AsRateController 将任意控制器转换为 RateController
+// 已实现扩展接口的控制器直接返回；否则包装为适配器，把样本转换为 OnAckReceived(AckedBytes, RTT)
+func AsRateController(ctrl Controller) RateController {
+	if rc, ok := ctrl.(RateController); ok {
+		return rc
+	}
+	return &rateAdapter{Controller: ctrl}
+}
+
+// rateAdapter 适配只实现基础接口的控制器
+type rateAdapter struct {
+	Controller
+}
+
+func (a *rateAdapter) OnRateSample(sample RateSample) {
+	a.OnAckReceived(sample.AckedBytes, sample.RTT)
+}
+
+// sentPacket 数据包发送时的投递进度快照
+type sentPacket struct {
+	seq           uint32
+	size          int
+	sentTime      time.Time
+	delivered     int64     // 发送时的累计投递字节数
+	deliveredTime time.Time // 发送时最近一次投递的时间
+	firstSentTime time.Time // 发送时当前采样区间的起始发送时间
+	isAppLimited  bool
+}
+
+// RateSampler 投递速率采样器，按序列号（字节偏移）跟踪已发送未确认的数据包
+type RateSampler struct {
+	mu sync.Mutex
+
+	packets         map[uint32]*sentPacket
+	delivered       int64     // 累计投递字节数
+	deliveredTime   time.Time // 最近一次投递的时间
+	firstSentTime   time.Time // 当前采样区间的起始发送时间
+	appLimitedUntil int64     // 应用受限标记持续到累计投递达到该值（0表示未受限）
+	inFlight        int       // 飞行字节数
+	lostBytes       int       // 尚未计入样本的丢失字节数
+}
+
+// NewRateSampler 创建投递速率采样器
+func NewRateSampler() *RateSampler {
+	return &RateSampler{
+		packets: make(map[uint32]*sentPacket),
+	}
+}
+
+// OnPacketSent 记录数据包发送（重传时再次调用以刷新发送快照）
+func (s *RateSampler) OnPacketSent(seq uint32, size int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 无飞行数据时开启新的采样区间
+	if s.inFlight == 0 {
+		s.firstSentTime = now
+		s.deliveredTime = now
+	}
+
+	if old, exists := s.packets[seq]; exists {
+		s.inFlight -= old.size
+	}
+	s.packets[seq] = &sentPacket{
+		seq:           seq,
+		size:          size,
+		sentTime:      now,
+		delivered:     s.delivered,
+		deliveredTime: s.deliveredTime,
+		firstSentTime: s.firstSentTime,
+		isAppLimited:  s.appLimitedUntil > 0,
+	}
+	s.inFlight += size
+}
+
+// OnPacketAcked 单个数据包被确认（选择性确认），返回速率样本
+func (s *RateSampler) OnPacketAcked(seq uint32, now time.Time) RateSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.packets[seq]
+	if !ok {
+		return s.emptySample()
+	}
+	delete(s.packets, seq)
+	return s.generateSample(p.size, p, now)
+}
+
+// OnCumulativeAck 累计确认到 ack（不含）之前的所有数据，返回速率样本
+// 部分覆盖的数据包按已确认字节数计入，剩余部分继续跟踪
+func (s *RateSampler) OnCumulativeAck(ack uint32, now time.Time) RateSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acked := 0
+	var ref *sentPacket
+	for seq, p := range s.packets {
+		end := seq + uint32(p.size)
+		var bytes int
+		switch {
+		case seqLEQ(end, ack):
+			bytes = p.size
+			delete(s.packets, seq)
+		case seqLess(seq, ack):
+			bytes = int(ack - seq)
+			delete(s.packets, seq)
+			remain := *p
+			remain.seq = ack
+			remain.size = p.size - bytes
+			s.packets[ack] = &remain
+		default:
+			continue
+		}
+		acked += bytes
+		// 以最近发送的数据包作为采样参考
+		if ref == nil || p.sentTime.After(ref.sentTime) {
+			snapshot := *p
+			ref = &snapshot
+		}
+	}
+
+	if acked == 0 {
+		return s.emptySample()
+	}
+	return s.generateSample(acked, ref, now)
+}
+
+// OnPacketLost 数据包被判定丢失：不再计入飞行字节，丢失字节计入下一个样本
+// 返回丢失的字节数（未跟踪的序列号返回0）
+func (s *RateSampler) OnPacketLost(seq uint32) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.packets[seq]
+	if !ok {
+		return 0
+	}
+	delete(s.packets, seq)
+	s.inFlight -= p.size
+	if s.inFlight < 0 {
+		s.inFlight = 0
+	}
+	s.lostBytes += p.size
+	return p.size
+}
+
+// SetAppLimited 标记发送受应用层限制（待发送数据已耗尽但窗口仍有余量）
+// 直到当前飞行数据全部投递前生成的样本都带有 IsAppLimited 标记
+func (s *RateSampler) SetAppLimited() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appLimitedUntil = s.delivered + int64(s.inFlight)
+	if s.appLimitedUntil == 0 {
+		s.appLimitedUntil = 1
+	}
+}
+
+// InFlight 返回飞行字节数
+func (s *RateSampler) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+// Delivered 返回累计投递字节数
+func (s *RateSampler) Delivered() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delivered
+}
+
+// generateSample 根据参考数据包的发送快照生成样本，调用方需持有锁
+func (s *RateSampler) generateSample(acked int, ref *sentPacket, now time.Time) RateSample {
+	s.delivered += int64(acked)
+	s.deliveredTime = now
+	s.inFlight -= acked
+	if s.inFlight < 0 {
+		s.inFlight = 0
+	}
+
+	sendElapsed := ref.sentTime.Sub(ref.firstSentTime)
+	ackElapsed := now.Sub(ref.deliveredTime)
+	// 下一个采样区间从参考数据包的发送时间开始
+	s.firstSentTime = ref.sentTime
+
+	if s.appLimitedUntil > 0 && s.delivered > s.appLimitedUntil {
+		s.appLimitedUntil = 0
+	}
+
+	sample := RateSample{
+		AckedBytes:    acked,
+		Delivered:     s.delivered - ref.delivered,
+		Interval:      max(sendElapsed, ackElapsed),
+		BytesInFlight: s.inFlight,
+		LostBytes:     s.lostBytes,
+		IsAppLimited:  ref.isAppLimited,
+	}
+	s.lostBytes = 0
+	return sample
+}
+
+// emptySample 未确认新数据时的样本，调用方需持有锁
+func (s *RateSampler) emptySample() RateSample {
+	return RateSample{BytesInFlight: s.inFlight}
+}
+
+// seqLess 序列号比较（支持回绕）
+func seqLess(a, b uint32) bool {
+	return int32(a-b) < 0
+}
+
+// seqLEQ 序列号比较（支持回绕）
+func seqLEQ(a, b uint32) bool {
+	return int32(a-b) <= 0
+}
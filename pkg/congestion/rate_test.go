@@ -0,0 +1,161 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateSamplerDeliveryRate(t *testing.T) {
+	s := NewRateSampler()
+	start := time.Unix(0, 0)
+
+	// 每10ms发送一个1000字节的包，RTT为50ms，稳态投递速率约为100KB/s
+	var sample RateSample
+	for i := 0; i < 25; i++ {
+		now := start.Add(time.Duration(i) * 10 * time.Millisecond)
+		if i >= 5 {
+			sample = s.OnPacketAcked(uint32((i-5)*1000), now)
+		}
+		if i < 20 {
+			s.OnPacketSent(uint32(i*1000), 1000, now)
+		}
+	}
+
+	if sample.AckedBytes != 1000 {
+		t.Errorf("AckedBytes = %d, want 1000", sample.AckedBytes)
+	}
+	rate := sample.DeliveryRate()
+	if rate < 90000 || rate > 110000 {
+		t.Errorf("DeliveryRate = %.0f, want ~100000", rate)
+	}
+	if s.InFlight() != 0 {
+		t.Errorf("InFlight = %d, want 0", s.InFlight())
+	}
+	if s.Delivered() != 20000 {
+		t.Errorf("Delivered = %d, want 20000", s.Delivered())
+	}
+}
+
+func TestRateSamplerCumulativeAck(t *testing.T) {
+	s := NewRateSampler()
+	now := time.Unix(0, 0)
+	s.OnPacketSent(0, 1000, now)
+	s.OnPacketSent(1000, 1000, now)
+	s.OnPacketSent(2000, 1000, now)
+
+	// 部分确认第二个包
+	sample := s.OnCumulativeAck(1500, now.Add(50*time.Millisecond))
+	if sample.AckedBytes != 1500 {
+		t.Errorf("AckedBytes = %d, want 1500", sample.AckedBytes)
+	}
+	if sample.BytesInFlight != 1500 {
+		t.Errorf("BytesInFlight = %d, want 1500", sample.BytesInFlight)
+	}
+
+	// 重复的累计确认不产生新样本
+	if sample := s.OnCumulativeAck(1500, now.Add(60*time.Millisecond)); sample.AckedBytes != 0 || sample.DeliveryRate() != 0 {
+		t.Errorf("duplicate ack sample = %+v, want empty", sample)
+	}
+
+	sample = s.OnCumulativeAck(3000, now.Add(70*time.Millisecond))
+	if sample.AckedBytes != 1500 || sample.BytesInFlight != 0 {
+		t.Errorf("sample = %+v, want 1500 acked and nothing in flight", sample)
+	}
+}
+
+func TestRateSamplerSequenceWrap(t *testing.T) {
+	s := NewRateSampler()
+	now := time.Unix(0, 0)
+	seq := uint32(0xFFFFFFFF - 499)
+	s.OnPacketSent(seq, 1000, now)
+
+	sample := s.OnCumulativeAck(seq+1000, now.Add(10*time.Millisecond))
+	if sample.AckedBytes != 1000 {
+		t.Errorf("AckedBytes across wrap = %d, want 1000", sample.AckedBytes)
+	}
+}
+
+func TestRateSamplerLossAndAppLimited(t *testing.T) {
+	s := NewRateSampler()
+	now := time.Unix(0, 0)
+	s.OnPacketSent(0, 1000, now)
+	s.OnPacketSent(1000, 1000, now)
+
+	if lost := s.OnPacketLost(0); lost != 1000 {
+		t.Errorf("OnPacketLost = %d, want 1000", lost)
+	}
+	if lost := s.OnPacketLost(0); lost != 0 {
+		t.Errorf("second OnPacketLost = %d, want 0", lost)
+	}
+
+	// 待发送数据耗尽：之后发送的包带有应用受限标记
+	s.SetAppLimited()
+	s.OnPacketSent(2000, 1000, now.Add(5*time.Millisecond))
+
+	sample := s.OnPacketAcked(1000, now.Add(50*time.Millisecond))
+	if sample.LostBytes != 1000 {
+		t.Errorf("LostBytes = %d, want 1000", sample.LostBytes)
+	}
+	if sample.IsAppLimited {
+		t.Error("packet sent before SetAppLimited should not be app-limited")
+	}
+
+	sample = s.OnPacketAcked(2000, now.Add(55*time.Millisecond))
+	if !sample.IsAppLimited {
+		t.Error("packet sent after SetAppLimited should be app-limited")
+	}
+	if sample.LostBytes != 0 {
+		t.Errorf("LostBytes should be reported once, got %d", sample.LostBytes)
+	}
+}
+
+func TestAsRateController(t *testing.T) {
+	// 未实现扩展接口的算法经适配器转换为 OnAckReceived
+	reno := NewRenoController(2800, 65536, 1400)
+	rc := AsRateController(reno)
+	if _, ok := rc.(*rateAdapter); !ok {
+		t.Fatalf("AsRateController(reno) = %T, want adapter", rc)
+	}
+	rc.OnPacketSent(1400)
+	rc.OnRateSample(RateSample{AckedBytes: 1400, RTT: 50 * time.Millisecond})
+	if reno.GetCongestionWindow() <= 2800 {
+		t.Error("Expected reno cwnd to grow through adapter")
+	}
+
+	// 已实现扩展接口的算法直接返回
+	for _, algo := range []AlgorithmType{AlgorithmBBR, AlgorithmBBRv2} {
+		ctrl, err := NewController(algo, 10000, 1000000, 1000)
+		if err != nil {
+			t.Fatalf("NewController(%s) error = %v", algo, err)
+		}
+		if rc := AsRateController(ctrl); rc != ctrl {
+			t.Errorf("AsRateController(%s) should return the controller itself", algo)
+		}
+	}
+}
+
+func TestBBRRateSample(t *testing.T) {
+	ctrl := NewBBRController(14000, 1000000, 1400)
+	ctrl.OnPacketSent(1400)
+	ctrl.OnRateSample(RateSample{
+		AckedBytes: 1400,
+		RTT:        50 * time.Millisecond,
+		Delivered:  100000,
+		Interval:   100 * time.Millisecond,
+	})
+	if got := ctrl.GetSendRate(); got != int(1000000*ctrl.config.StartupGain) {
+		t.Errorf("GetSendRate = %d, want bandwidth from rate sample", got)
+	}
+
+	// 低于当前估计的应用受限样本被忽略
+	ctrl.OnRateSample(RateSample{
+		AckedBytes:   1400,
+		RTT:          50 * time.Millisecond,
+		Delivered:    1000,
+		Interval:     100 * time.Millisecond,
+		IsAppLimited: true,
+	})
+	if ctrl.bwEstimate != 1000000 {
+		t.Errorf("bwEstimate = %.0f, app-limited sample should not lower it", ctrl.bwEstimate)
+	}
+}
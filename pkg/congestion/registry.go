@@ -0,0 +1,236 @@
+package congestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ------------------------------
+// 拥塞控制算法注册表
+// 内置算法在包初始化时注册，业务方可通过 Register 注册自定义算法，
+// 之后即可通过名称（如配置文件中的字符串）创建控制器
+// ------------------------------
+
+// Factory 拥塞控制器工厂函数
+// config 为算法专属配置（如 CubicConfig、BBRConfig），为 nil 时使用默认配置
+type Factory func(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[AlgorithmType]Factory{
+		AlgorithmCubic: newCubicFromConfig,
+		AlgorithmBBR:   newBBRFromConfig,
+		AlgorithmReno:  newRenoFromConfig,
+		AlgorithmVegas: newVegasFromConfig,
+		AlgorithmBBRv2: newBBRv2FromConfig,
+	}
+)
+
+// Register 注册拥塞控制算法，名称不可为空且不可重复
+func Register(name AlgorithmType, factory Factory) error {
+	if name == "" {
+		return fmt.Errorf("拥塞控制算法名称不能为空")
+	}
+	if factory == nil {
+		return fmt.Errorf("拥塞控制算法 %s 的工厂函数不能为空", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		return fmt.Errorf("拥塞控制算法已注册: %s", name)
+	}
+	registry[name] = factory
+	return nil
+}
+
+// Unregister 注销拥塞控制算法，返回是否存在
+func Unregister(name AlgorithmType) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; !exists {
+		return false
+	}
+	delete(registry, name)
+	return true
+}
+
+// Algorithms 返回已注册的算法名称（按名称排序）
+func Algorithms() []AlgorithmType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]AlgorithmType, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// NewControllerWithConfig 根据算法名称和配置创建拥塞控制器
+func NewControllerWithConfig(algorithm AlgorithmType, config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+	registryMu.RLock()
+	factory, ok := registry[algorithm]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("不支持的拥塞控制算法: %s（支持的算法：%v）", algorithm, Algorithms())
+	}
+
+	ctrl, err := factory(config, initialCWnd, maxCWnd, packetSize)
+	if err != nil {
+		return nil, fmt.Errorf("创建拥塞控制算法 %s 失败: %w", algorithm, err)
+	}
+	return ctrl, nil
+}
+
+// DecodeConfig 将算法配置解码到 target（指向已填充默认值的配置结构体）
+// 支持：nil（保持默认值）、与目标相同类型的值或指针、
+// 以及从JSON/YAML配置文件解析出的 map（按 json 标签映射字段）
+func DecodeConfig(config interface{}, target interface{}) error {
+	if config == nil {
+		return nil
+	}
+
+	dst := reflect.ValueOf(target)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("配置解码目标必须为非空指针")
+	}
+	elem := dst.Elem()
+
+	src := reflect.ValueOf(config)
+	if src.Type() == elem.Type() {
+		elem.Set(src)
+		return nil
+	}
+	if src.Kind() == reflect.Ptr && src.Type().Elem() == elem.Type() {
+		if !src.IsNil() {
+			elem.Set(src.Elem())
+		}
+		return nil
+	}
+
+	switch config.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		m := normalizeConfigValue(config).(map[string]interface{})
+		if err := parseDurationFields(m, elem.Type()); err != nil {
+			return err
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("配置编码失败: %w", err)
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return fmt.Errorf("配置解码失败: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("配置类型不匹配: 期望 %s，实际 %T", elem.Type(), config)
+	}
+}
+
+// parseDurationFields 将 map 中对应 time.Duration 字段的字符串（如 "200ms"）解析为纳秒数
+func parseDurationFields(m map[string]interface{}, typ reflect.Type) error {
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	durationType := reflect.TypeOf(time.Duration(0))
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type != durationType {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		for key, val := range m {
+			str, ok := val.(string)
+			if !ok || !strings.EqualFold(key, name) {
+				continue
+			}
+			d, err := time.ParseDuration(str)
+			if err != nil {
+				return fmt.Errorf("配置项 %s 不是合法的时间间隔: %w", key, err)
+			}
+			m[key] = int64(d)
+		}
+	}
+	return nil
+}
+
+// normalizeConfigValue 将 YAML 解析出的 map[interface{}]interface{} 转换为可JSON编码的形式
+func normalizeConfigValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[fmt.Sprint(k)] = normalizeConfigValue(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = normalizeConfigValue(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, item := range val {
+			s[i] = normalizeConfigValue(item)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// 内置算法工厂
+
+func newCubicFromConfig(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+	cfg := DefaultCubicConfig()
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	return NewCubicControllerWithConfig(cfg, initialCWnd, maxCWnd, packetSize), nil
+}
+
+func newBBRFromConfig(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+	cfg := DefaultBBRConfig()
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	return NewBBRControllerWithConfig(cfg, initialCWnd, maxCWnd, packetSize), nil
+}
+
+func newRenoFromConfig(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+	cfg := DefaultRenoConfig()
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	return NewRenoControllerWithConfig(cfg, initialCWnd, maxCWnd, packetSize), nil
+}
+
+func newVegasFromConfig(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+	cfg := DefaultVegasConfig()
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	return NewVegasControllerWithConfig(cfg, initialCWnd, maxCWnd, packetSize), nil
+}
+
+func newBBRv2FromConfig(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+	cfg := DefaultBBRv2Config()
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	return NewBBRv2ControllerWithConfig(cfg, initialCWnd, maxCWnd, packetSize), nil
+}
@@ -0,0 +1,127 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+)
+
+// fixedController 固定窗口的自定义算法（用于测试注册）
+type fixedController struct {
+	*BaseController
+}
+
+func (f *fixedController) OnPacketSent(packetSize int)                  {}
+func (f *fixedController) OnAckReceived(ackSize int, rtt time.Duration) {}
+func (f *fixedController) OnPacketLost()                                {}
+func (f *fixedController) GetCongestionWindow() int                     { return f.cwnd }
+func (f *fixedController) GetSendRate() int                             { return 0 }
+func (f *fixedController) GetStatistics() CongestionStats               { return f.stats }
+
+type fixedConfig struct {
+	Window int `json:"window"`
+}
+
+func TestRegisterCustomAlgorithm(t *testing.T) {
+	const name AlgorithmType = "fixed"
+	err := Register(name, func(config interface{}, initialCWnd, maxCWnd, packetSize int) (Controller, error) {
+		cfg := fixedConfig{Window: initialCWnd}
+		if err := DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		return &fixedController{BaseController: NewBaseController(cfg.Window, maxCWnd, packetSize)}, nil
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	defer Unregister(name)
+
+	// 重复注册应失败
+	if err := Register(name, newRenoFromConfig); err == nil {
+		t.Error("Expected error on duplicate registration")
+	}
+
+	// 按名称创建，配置来自配置文件解析出的 map
+	ctrl, err := NewControllerWithConfig(name, map[string]interface{}{"window": 5000}, 2800, 65536, 1400)
+	if err != nil {
+		t.Fatalf("NewControllerWithConfig() error = %v", err)
+	}
+	if ctrl.GetCongestionWindow() != 5000 {
+		t.Errorf("cwnd = %d, want 5000", ctrl.GetCongestionWindow())
+	}
+
+	found := false
+	for _, algo := range Algorithms() {
+		if algo == name {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Algorithms() = %v, missing %s", Algorithms(), name)
+	}
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	if err := Register("", newRenoFromConfig); err == nil {
+		t.Error("Expected error for empty name")
+	}
+	if err := Register("nil-factory", nil); err == nil {
+		t.Error("Expected error for nil factory")
+	}
+	if err := Register(AlgorithmCubic, newRenoFromConfig); err == nil {
+		t.Error("Expected error when overriding builtin algorithm")
+	}
+	if Unregister("not-exist") {
+		t.Error("Unregister() of unknown algorithm should return false")
+	}
+}
+
+func TestNewControllerWithTypedConfig(t *testing.T) {
+	ctrl, err := NewControllerWithConfig(AlgorithmVegas, VegasConfig{Alpha: 2, Beta: 4}, 2800, 65536, 1400)
+	if err != nil {
+		t.Fatalf("NewControllerWithConfig() error = %v", err)
+	}
+	vegas := ctrl.(*VegasController)
+	if vegas.alpha != 2*1400 || vegas.beta != 4*1400 {
+		t.Errorf("alpha/beta = %d/%d, want %d/%d", vegas.alpha, vegas.beta, 2*1400, 4*1400)
+	}
+
+	ctrl, err = NewControllerWithConfig(AlgorithmReno, &RenoConfig{InitialSsthresh: 8000}, 2800, 65536, 1400)
+	if err != nil {
+		t.Fatalf("NewControllerWithConfig() error = %v", err)
+	}
+	if ssthresh := ctrl.(*RenoController).ssthresh; ssthresh != 8000 {
+		t.Errorf("ssthresh = %d, want 8000", ssthresh)
+	}
+
+	// 类型不匹配
+	if _, err := NewControllerWithConfig(AlgorithmBBR, CubicConfig{}, 2800, 65536, 1400); err == nil {
+		t.Error("Expected error for mismatched config type")
+	}
+}
+
+func TestBBRConfigFromMap(t *testing.T) {
+	// 模拟 YAML 解析结果：键为 interface{}，时间为字符串
+	raw := map[interface{}]interface{}{
+		"cwnd_gain":          3.0,
+		"probe_rtt_duration": "100ms",
+	}
+	ctrl, err := NewControllerWithConfig(AlgorithmBBR, raw, 2800, 65536, 1400)
+	if err != nil {
+		t.Fatalf("NewControllerWithConfig() error = %v", err)
+	}
+	bbr := ctrl.(*BBRController)
+	if bbr.cwndGain != 3.0 {
+		t.Errorf("cwndGain = %v, want 3.0", bbr.cwndGain)
+	}
+	if bbr.config.ProbeRTTDuration != 100*time.Millisecond {
+		t.Errorf("ProbeRTTDuration = %v, want 100ms", bbr.config.ProbeRTTDuration)
+	}
+	// 未配置字段保持默认值
+	if bbr.config.ProbeBWGain != DefaultBBRConfig().ProbeBWGain {
+		t.Errorf("ProbeBWGain = %v, want default", bbr.config.ProbeBWGain)
+	}
+
+	if _, err := NewControllerWithConfig(AlgorithmBBR, map[string]interface{}{"probe_rtt_interval": "soon"}, 2800, 65536, 1400); err == nil {
+		t.Error("Expected error for invalid duration")
+	}
+}
@@ -9,15 +9,38 @@ import (
 // 特点：基于丢包检测，包含慢启动、拥塞避免、快速重传和快速恢复
 // ------------------------------
 
+type RenoConfig struct {
+	InitialSsthresh int `json:"initial_ssthresh" yaml:"initial_ssthresh"` // 初始慢启动阈值（字节，默认64KB）
+}
+
+func DefaultRenoConfig() RenoConfig {
+	return RenoConfig{InitialSsthresh: 65536}
+}
+
+// 零值字段使用默认值
+func (c RenoConfig) withDefaults() RenoConfig {
+	if c.InitialSsthresh <= 0 {
+		c.InitialSsthresh = DefaultRenoConfig().InitialSsthresh
+	}
+	return c
+}
+
 type RenoController struct {
 	*BaseController
 	dupAckCount int // 重复ACK计数器（用于检测丢包）
 }
 
 func NewRenoController(initialCWnd, maxCWnd, packetSize int) *RenoController {
-	return &RenoController{
+	return NewRenoControllerWithConfig(DefaultRenoConfig(), initialCWnd, maxCWnd, packetSize)
+}
+
+func NewRenoControllerWithConfig(config RenoConfig, initialCWnd, maxCWnd, packetSize int) *RenoController {
+	config = config.withDefaults()
+	r := &RenoController{
 		BaseController: NewBaseController(initialCWnd, maxCWnd, packetSize),
 	}
+	r.ssthresh = config.InitialSsthresh
+	return r
 }
 
 func (r *RenoController) OnPacketSent(packetSize int) {
@@ -0,0 +1,98 @@
+// congestion-sim 在虚拟时间下的瓶颈链路上对比拥塞控制算法
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/congestion"
+	"github.com/junbin-yang/go-kitbox/pkg/congestion/sim"
+)
+
+func main() {
+	fs := flag.NewFlagSet("congestion-sim", flag.ExitOnError)
+	bandwidth := fs.Float64("bandwidth", 10, "瓶颈带宽（Mbps）")
+	rtt := fs.Duration("rtt", 40*time.Millisecond, "传播往返时延")
+	buffer := fs.Float64("buffer", 1, "瓶颈队列大小（BDP倍数）")
+	loss := fs.Float64("loss", 0, "随机丢包概率（0-1）")
+	ecn := fs.Float64("ecn", 0, "ECN-CE标记的队列阈值（BDP倍数，0表示不标记）")
+	duration := fs.Duration("duration", 30*time.Second, "模拟时长（虚拟时间）")
+	mss := fs.Int("mss", 1400, "数据包大小（字节）")
+	flows := fs.Int("flows", 1, "每种算法独占运行时的流数量")
+	algos := fs.String("algos", "", "参与对比的算法，逗号分隔（默认全部已注册算法）")
+	compete := fs.Bool("compete", false, "额外运行各算法各一个流共享链路的竞争场景")
+	seed := fs.Int64("seed", 1, "随机数种子")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: congestion-sim [options]")
+		fmt.Println()
+		fmt.Println("Compare congestion control algorithms on a simulated bottleneck link.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Example:")
+		fmt.Println("  congestion-sim -bandwidth 100 -rtt 80ms -buffer 0.5 -loss 0.01 -flows 2 -compete")
+	}
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+
+	link := sim.LinkConfig{
+		Bandwidth:  int(*bandwidth * 1e6 / 8),
+		RTT:        *rtt,
+		LossRate:   *loss,
+		PacketSize: *mss,
+		Duration:   *duration,
+		Seed:       *seed,
+	}
+	bdp := link.BDP()
+	link.BufferSize = max(int(*buffer*float64(bdp)), *mss)
+	link.ECNThreshold = int(*ecn * float64(bdp))
+
+	algorithms := congestion.Algorithms()
+	if *algos != "" {
+		algorithms = algorithms[:0]
+		for _, name := range strings.Split(*algos, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				algorithms = append(algorithms, congestion.AlgorithmType(name))
+			}
+		}
+	}
+
+	fmt.Printf("Link: %.2f Mbps, RTT %v, buffer %d bytes (%.2f BDP), loss %.2f%%, duration %v\n",
+		*bandwidth, link.RTT, link.BufferSize, float64(link.BufferSize)/float64(bdp), link.LossRate*100, link.Duration)
+	if link.ECNThreshold > 0 {
+		fmt.Printf("ECN: mark CE when queue > %d bytes\n", link.ECNThreshold)
+	}
+	fmt.Println()
+
+	comparisons, err := sim.CompareAlgorithms(link, algorithms, *flows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Simulation failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("== %d flow(s) per algorithm ==\n", *flows)
+	if err := sim.WriteComparison(os.Stdout, comparisons); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write table: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *compete {
+		res, err := sim.Compete(link, algorithms)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Simulation failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println()
+		fmt.Println("== Competing on shared link ==")
+		if err := res.WriteTable(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write table: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
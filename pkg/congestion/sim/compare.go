@@ -0,0 +1,97 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/congestion"
+)
+
+// 表格中时延的显示精度
+const delayPrecision = 100 * time.Microsecond
+
+// Comparison 单个算法在链路上独占运行的结果
+type Comparison struct {
+	Algorithm congestion.AlgorithmType
+	Result    *Result
+}
+
+// CompareAlgorithms 每种算法分别以 flows 个同算法流独占链路运行一次，便于横向对比
+func CompareAlgorithms(link LinkConfig, algorithms []congestion.AlgorithmType, flows int) ([]Comparison, error) {
+	if flows <= 0 {
+		flows = 1
+	}
+	comparisons := make([]Comparison, 0, len(algorithms))
+	for _, algo := range algorithms {
+		fs := make([]Flow, flows)
+		for i := range fs {
+			f, err := NewFlow(algo, link)
+			if err != nil {
+				return nil, err
+			}
+			f.Name = fmt.Sprintf("%s-%d", algo, i)
+			fs[i] = f
+		}
+		res, err := Run(link, fs...)
+		if err != nil {
+			return nil, fmt.Errorf("模拟算法 %s 失败: %w", algo, err)
+		}
+		comparisons = append(comparisons, Comparison{Algorithm: algo, Result: res})
+	}
+	return comparisons, nil
+}
+
+// Compete 每种算法一个流，共享同一链路竞争带宽
+func Compete(link LinkConfig, algorithms []congestion.AlgorithmType) (*Result, error) {
+	fs := make([]Flow, 0, len(algorithms))
+	for _, algo := range algorithms {
+		f, err := NewFlow(algo, link)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	return Run(link, fs...)
+}
+
+// WriteComparison 以表格形式输出各算法的对比结果
+func WriteComparison(w io.Writer, comparisons []Comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ALGORITHM\tTHROUGHPUT\tUTIL\tFAIRNESS\tAVG QDELAY\tMAX QDELAY\tLOSS")
+	for _, c := range comparisons {
+		r := c.Result
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%\t%.3f\t%v\t%v\t%.2f%%\n",
+			c.Algorithm, formatRate(r.Throughput), r.Utilization*100, r.Fairness,
+			r.AvgQueueDelay.Round(delayPrecision), r.MaxQueueDelay.Round(delayPrecision), r.LossRate*100)
+	}
+	return tw.Flush()
+}
+
+// WriteTable 以表格形式输出各流结果及汇总
+func (r *Result) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLOW\tTHROUGHPUT\tSHARE\tAVG RTT\tAVG QDELAY\tLOSS\tCWND")
+	for _, f := range r.Flows {
+		share := 0.0
+		if r.Throughput > 0 {
+			share = f.Throughput / r.Throughput
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%\t%v\t%v\t%.2f%%\t%d\n",
+			f.Name, formatRate(f.Throughput), share*100,
+			f.AvgRTT.Round(delayPrecision), f.AvgQueueDelay.Round(delayPrecision), f.LossRate*100, f.Stats.CongestionWindow)
+	}
+	fmt.Fprintf(tw, "TOTAL\t%s\t100.0%%\t\t%v\t%.2f%%\t\n",
+		formatRate(r.Throughput), r.AvgQueueDelay.Round(delayPrecision), r.LossRate*100)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Link utilization: %.1f%%  Jain fairness index: %.3f\n", r.Utilization*100, r.Fairness)
+	return err
+}
+
+// formatRate 将字节/秒格式化为 Mbps
+func formatRate(bytesPerSec float64) string {
+	return fmt.Sprintf("%.2f Mbps", bytesPerSec*8/1e6)
+}
@@ -0,0 +1,367 @@
+// Package sim 基于离散事件的瓶颈链路模拟器，用于在虚拟时间下对比拥塞控制算法
+//
+// 模型：所有流共享一条FIFO瓶颈链路（固定带宽、尾部丢弃队列），
+// 数据包可按概率随机丢失，队列超过阈值时可标记ECN-CE；
+// 每个流始终有数据可发，发送量仅受拥塞窗口限制（不模拟pacing）。
+// 丢包在约一个RTT后被发送方检测到（相当于重复ACK），ACK路径无排队。
+package sim
+
+import (
+	"container/heap"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/congestion"
+)
+
+// LinkConfig 瓶颈链路配置
+type LinkConfig struct {
+	Bandwidth    int           // 瓶颈带宽（字节/秒）
+	RTT          time.Duration // 传播往返时延（不含排队）
+	BufferSize   int           // 瓶颈队列大小（字节），超出时尾部丢弃（0表示不限）
+	LossRate     float64       // 随机丢包概率（0-1）
+	ECNThreshold int           // 队列超过该字节数时标记ECN-CE（0表示不标记）
+	PacketSize   int           // 数据包大小（字节）
+	Duration     time.Duration // 模拟时长（虚拟时间）
+	MaxCWnd      int           // 创建控制器时使用的最大拥塞窗口（字节）
+	Seed         int64         // 随机数种子，相同种子结果可复现
+}
+
+// DefaultLinkConfig 默认链路：10Mbps、40ms RTT、1倍BDP缓冲、无随机丢包，模拟30秒
+func DefaultLinkConfig() LinkConfig {
+	return LinkConfig{
+		Bandwidth:  1250000,
+		RTT:        40 * time.Millisecond,
+		BufferSize: 50000,
+		PacketSize: 1400,
+		Duration:   30 * time.Second,
+		MaxCWnd:    4 << 20,
+		Seed:       1,
+	}
+}
+
+// BDP 带宽时延积（字节）
+func (c LinkConfig) BDP() int {
+	return int(float64(c.Bandwidth) * c.RTT.Seconds())
+}
+
+// 零值字段使用默认值
+func (c LinkConfig) withDefaults() LinkConfig {
+	def := DefaultLinkConfig()
+	if c.Bandwidth == 0 {
+		c.Bandwidth = def.Bandwidth
+	}
+	if c.RTT == 0 {
+		c.RTT = def.RTT
+	}
+	if c.PacketSize == 0 {
+		c.PacketSize = def.PacketSize
+	}
+	if c.Duration == 0 {
+		c.Duration = def.Duration
+	}
+	if c.MaxCWnd == 0 {
+		c.MaxCWnd = def.MaxCWnd
+	}
+	return c
+}
+
+func (c LinkConfig) validate() error {
+	if c.Bandwidth < 0 || c.RTT < 0 || c.Duration < 0 || c.PacketSize < 0 || c.MaxCWnd < 0 {
+		return fmt.Errorf("链路参数不能为负数")
+	}
+	if c.BufferSize < 0 || c.ECNThreshold < 0 {
+		return fmt.Errorf("队列大小与ECN阈值不能为负数")
+	}
+	if c.LossRate < 0 || c.LossRate >= 1 {
+		return fmt.Errorf("随机丢包概率必须在 [0, 1) 范围内: %v", c.LossRate)
+	}
+	return nil
+}
+
+// Flow 参与模拟的数据流
+type Flow struct {
+	Name       string                // 流名称（为空时使用序号）
+	Controller congestion.Controller // 拥塞控制器
+	Start      time.Duration         // 开始发送的虚拟时间
+	RTT        time.Duration         // 该流的传播往返时延（0表示使用链路RTT）
+}
+
+// NewFlow 按算法名称创建数据流，控制器参数取自链路配置（初始窗口为10个数据包）
+func NewFlow(algorithm congestion.AlgorithmType, link LinkConfig) (Flow, error) {
+	link = link.withDefaults()
+	ctrl, err := congestion.NewController(algorithm, 10*link.PacketSize, link.MaxCWnd, link.PacketSize)
+	if err != nil {
+		return Flow{}, err
+	}
+	return Flow{Name: string(algorithm), Controller: ctrl}, nil
+}
+
+// FlowResult 单个流的模拟结果
+type FlowResult struct {
+	Name          string
+	Delivered     int64                      // 投递字节数
+	Sent          int64                      // 发送字节数（含重传）
+	Lost          int64                      // 丢失字节数
+	Throughput    float64                    // 投递速率（字节/秒，按流活跃时长计算）
+	LossRate      float64                    // 丢包率（丢失字节/发送字节）
+	AvgRTT        time.Duration              // 平均RTT（含排队）
+	AvgQueueDelay time.Duration              // 平均排队时延
+	MaxQueueDelay time.Duration              // 最大排队时延
+	Stats         congestion.CongestionStats // 模拟结束时控制器的统计信息
+}
+
+// Result 一次模拟的汇总结果
+type Result struct {
+	Link          LinkConfig
+	Flows         []FlowResult
+	Throughput    float64       // 总投递速率（字节/秒）
+	Utilization   float64       // 链路利用率（0-1）
+	Fairness      float64       // 各流吞吐的Jain公平性指数（0-1，1为完全公平）
+	LossRate      float64       // 总丢包率
+	AvgQueueDelay time.Duration // 所有投递数据包的平均排队时延
+	MaxQueueDelay time.Duration // 最大排队时延
+}
+
+// JainIndex 计算Jain公平性指数：(Σx)² / (n·Σx²)
+func JainIndex(values []float64) float64 {
+	var sum, sumSq float64
+	for _, v := range values {
+		sum += v
+		sumSq += v * v
+	}
+	if sumSq == 0 {
+		return 0
+	}
+	return sum * sum / (float64(len(values)) * sumSq)
+}
+
+// clockSetter 支持替换时钟的控制器（内置算法均通过 BaseController 支持）
+type clockSetter interface {
+	SetClock(now func() time.Time)
+}
+
+// flowState 模拟过程中单个流的状态
+type flowState struct {
+	flow     Flow
+	ctrl     congestion.RateController
+	sampler  *congestion.RateSampler
+	rtt      time.Duration
+	nextSeq  uint32
+	inFlight int
+	active   bool
+
+	result       FlowResult
+	rttSum       time.Duration
+	queueSum     time.Duration
+	ackedPackets int64
+}
+
+const (
+	eventStart = iota // 流开始发送
+	eventAck          // 数据包被确认
+	eventLoss         // 检测到丢包
+)
+
+type event struct {
+	at         time.Duration
+	id         uint64 // 入队序号，同一时刻按入队顺序处理
+	kind       int
+	flow       int
+	seq        uint32
+	sentAt     time.Duration
+	queueDelay time.Duration
+	ce         bool
+}
+
+type eventQueue []event
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].id < q[j].id
+}
+func (q eventQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(event)) }
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// Run 在虚拟时间下运行模拟，所有流竞争同一瓶颈链路
+// 支持替换时钟的控制器在模拟期间使用虚拟时钟，结束后恢复为 time.Now
+func Run(link LinkConfig, flows ...Flow) (*Result, error) {
+	link = link.withDefaults()
+	if err := link.validate(); err != nil {
+		return nil, err
+	}
+	if len(flows) == 0 {
+		return nil, fmt.Errorf("至少需要一个数据流")
+	}
+
+	var now, linkFree time.Duration
+	base := time.Now()
+	clock := func() time.Time { return base.Add(now) }
+
+	rng := rand.New(rand.NewSource(link.Seed))
+	events := &eventQueue{}
+	var nextID uint64
+	push := func(e event) {
+		e.id = nextID
+		nextID++
+		heap.Push(events, e)
+	}
+
+	states := make([]*flowState, len(flows))
+	for i, f := range flows {
+		if f.Controller == nil {
+			return nil, fmt.Errorf("数据流 %d 未设置拥塞控制器", i)
+		}
+		if f.Name == "" {
+			f.Name = fmt.Sprintf("flow-%d", i)
+		}
+		rtt := f.RTT
+		if rtt <= 0 {
+			rtt = link.RTT
+		}
+		if setter, ok := f.Controller.(clockSetter); ok {
+			setter.SetClock(clock)
+			defer setter.SetClock(nil)
+		}
+		states[i] = &flowState{
+			flow:    f,
+			ctrl:    congestion.AsRateController(f.Controller),
+			sampler: congestion.NewRateSampler(),
+			rtt:     rtt,
+			result:  FlowResult{Name: f.Name},
+		}
+		push(event{at: f.Start, kind: eventStart, flow: i})
+	}
+
+	mss := link.PacketSize
+	txTime := time.Duration(float64(mss) / float64(link.Bandwidth) * float64(time.Second))
+
+	send := func(flow int) {
+		st := states[flow]
+		for st.inFlight+mss <= st.ctrl.GetCongestionWindow() {
+			seq := st.nextSeq
+			st.nextSeq += uint32(mss)
+			st.ctrl.OnPacketSent(mss)
+			st.sampler.OnPacketSent(seq, mss, clock())
+			st.inFlight += mss
+			st.result.Sent += int64(mss)
+
+			var queueDelay time.Duration
+			if linkFree > now {
+				queueDelay = linkFree - now
+			}
+			queued := int(queueDelay.Seconds() * float64(link.Bandwidth))
+			if (link.BufferSize > 0 && queued+mss > link.BufferSize) || rng.Float64() < link.LossRate {
+				// 尾部丢弃或随机丢包：约一个RTT后由重复ACK检测到
+				push(event{at: now + st.rtt, kind: eventLoss, flow: flow, seq: seq, sentAt: now})
+				continue
+			}
+			linkFree = now + queueDelay + txTime
+			push(event{
+				at:         linkFree + st.rtt,
+				kind:       eventAck,
+				flow:       flow,
+				seq:        seq,
+				sentAt:     now,
+				queueDelay: queueDelay,
+				ce:         link.ECNThreshold > 0 && queued > link.ECNThreshold,
+			})
+		}
+	}
+
+	for events.Len() > 0 {
+		e := heap.Pop(events).(event)
+		if e.at > link.Duration {
+			break
+		}
+		now = e.at
+		st := states[e.flow]
+
+		switch e.kind {
+		case eventStart:
+			st.active = true
+		case eventLoss:
+			st.inFlight -= mss
+			st.result.Lost += int64(mss)
+			st.sampler.OnPacketLost(e.seq)
+			st.ctrl.OnPacketLost()
+		case eventAck:
+			st.inFlight -= mss
+			st.result.Delivered += int64(mss)
+			rtt := now - e.sentAt
+			st.rttSum += rtt
+			st.queueSum += e.queueDelay
+			st.ackedPackets++
+			if e.queueDelay > st.result.MaxQueueDelay {
+				st.result.MaxQueueDelay = e.queueDelay
+			}
+
+			sample := st.sampler.OnPacketAcked(e.seq, clock())
+			sample.RTT = rtt
+			if e.ce {
+				sample.ECNMarkedBytes = mss
+			}
+			st.ctrl.OnRateSample(sample)
+		}
+		if st.active {
+			send(e.flow)
+		}
+	}
+
+	return summarize(link, states), nil
+}
+
+// summarize 汇总各流结果
+func summarize(link LinkConfig, states []*flowState) *Result {
+	res := &Result{Link: link, Flows: make([]FlowResult, len(states))}
+	throughputs := make([]float64, len(states))
+	var delivered, sent, lost, packets int64
+	var queueSum time.Duration
+
+	for i, st := range states {
+		fr := st.result
+		if active := link.Duration - st.flow.Start; active > 0 {
+			fr.Throughput = float64(fr.Delivered) / active.Seconds()
+		}
+		if fr.Sent > 0 {
+			fr.LossRate = float64(fr.Lost) / float64(fr.Sent)
+		}
+		if st.ackedPackets > 0 {
+			fr.AvgRTT = st.rttSum / time.Duration(st.ackedPackets)
+			fr.AvgQueueDelay = st.queueSum / time.Duration(st.ackedPackets)
+		}
+		fr.Stats = st.flow.Controller.GetStatistics()
+
+		res.Flows[i] = fr
+		throughputs[i] = fr.Throughput
+		delivered += fr.Delivered
+		sent += fr.Sent
+		lost += fr.Lost
+		packets += st.ackedPackets
+		queueSum += st.queueSum
+		if fr.MaxQueueDelay > res.MaxQueueDelay {
+			res.MaxQueueDelay = fr.MaxQueueDelay
+		}
+	}
+
+	res.Throughput = float64(delivered) / link.Duration.Seconds()
+	res.Utilization = res.Throughput / float64(link.Bandwidth)
+	res.Fairness = JainIndex(throughputs)
+	if sent > 0 {
+		res.LossRate = float64(lost) / float64(sent)
+	}
+	if packets > 0 {
+		res.AvgQueueDelay = queueSum / time.Duration(packets)
+	}
+	return res
+}
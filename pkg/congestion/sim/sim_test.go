@@ -0,0 +1,235 @@
+package sim
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/congestion"
+)
+
+func testLink() LinkConfig {
+	link := DefaultLinkConfig()
+	link.Duration = 10 * time.Second
+	return link
+}
+
+func newTestFlow(t *testing.T, algo congestion.AlgorithmType, link LinkConfig) Flow {
+	t.Helper()
+	f, err := NewFlow(algo, link)
+	if err != nil {
+		t.Fatalf("NewFlow(%s) error = %v", algo, err)
+	}
+	return f
+}
+
+func TestJainIndex(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{[]float64{1, 1, 1, 1}, 1},
+		{[]float64{1, 0, 0, 0}, 0.25},
+		{[]float64{3, 1}, 0.8},
+		{[]float64{0, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := JainIndex(tt.values); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("JainIndex(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestRunSingleFlow(t *testing.T) {
+	link := testLink()
+	res, err := Run(link, newTestFlow(t, congestion.AlgorithmReno, link))
+	if err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+
+	if res.Utilization < 0.9 || res.Utilization > 1.0 {
+		t.Errorf("Utilization = %.3f, want within [0.9, 1.0]", res.Utilization)
+	}
+	if res.Fairness != 1 {
+		t.Errorf("Fairness of single flow = %v, want 1", res.Fairness)
+	}
+	// 排队时延不超过缓冲区排空时间
+	maxDelay := time.Duration(float64(link.BufferSize) / float64(link.Bandwidth) * float64(time.Second))
+	if res.MaxQueueDelay <= 0 || res.MaxQueueDelay > maxDelay {
+		t.Errorf("MaxQueueDelay = %v, want within (0, %v]", res.MaxQueueDelay, maxDelay)
+	}
+	f := res.Flows[0]
+	if f.Name != string(congestion.AlgorithmReno) {
+		t.Errorf("Flow name = %q, want %q", f.Name, congestion.AlgorithmReno)
+	}
+	if f.AvgRTT < link.RTT {
+		t.Errorf("AvgRTT = %v, should not be below propagation RTT %v", f.AvgRTT, link.RTT)
+	}
+	if f.Stats.PacketsSent == 0 {
+		t.Error("Expected controller statistics to be collected")
+	}
+}
+
+func TestRunRandomLoss(t *testing.T) {
+	link := testLink()
+	link.BufferSize = 0 // 不限队列，只有随机丢包
+	link.LossRate = 0.05
+
+	res, err := Run(link, newTestFlow(t, congestion.AlgorithmBBRv2, link))
+	if err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	if math.Abs(res.LossRate-link.LossRate) > 0.01 {
+		t.Errorf("LossRate = %.4f, want ~%.2f", res.LossRate, link.LossRate)
+	}
+}
+
+func TestRunDeterministic(t *testing.T) {
+	link := testLink()
+	link.LossRate = 0.01
+
+	run := func() *Result {
+		res, err := Run(link, newTestFlow(t, congestion.AlgorithmReno, link), newTestFlow(t, congestion.AlgorithmBBR, link))
+		if err != nil {
+			t.Fatalf("Run error = %v", err)
+		}
+		return res
+	}
+	a, b := run(), run()
+	for i := range a.Flows {
+		if a.Flows[i].Delivered != b.Flows[i].Delivered || a.Flows[i].Lost != b.Flows[i].Lost {
+			t.Errorf("flow %d results differ between runs with the same seed", i)
+		}
+	}
+}
+
+func TestRunFairnessSameAlgorithm(t *testing.T) {
+	link := testLink()
+	link.Duration = 30 * time.Second
+
+	flows := []Flow{newTestFlow(t, congestion.AlgorithmReno, link), newTestFlow(t, congestion.AlgorithmReno, link)}
+	flows[1].Start = time.Second
+	res, err := Run(link, flows...)
+	if err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	if res.Fairness < 0.9 {
+		t.Errorf("Fairness = %.3f, want >= 0.9", res.Fairness)
+	}
+	if res.Utilization < 0.9 {
+		t.Errorf("Utilization = %.3f, want >= 0.9", res.Utilization)
+	}
+}
+
+func TestRunUsesVirtualClock(t *testing.T) {
+	link := testLink()
+	probe := &clockProbe{RenoController: congestion.NewRenoController(10*link.PacketSize, link.MaxCWnd, link.PacketSize)}
+
+	start := time.Now()
+	if _, err := Run(link, Flow{Controller: probe}); err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("simulation took %v, expected virtual time", elapsed)
+	}
+
+	// 控制器看到的时间跨度应接近模拟时长
+	if span := probe.last.Sub(probe.first); span < link.Duration-time.Second || span > link.Duration {
+		t.Errorf("virtual clock span = %v, want ~%v", span, link.Duration)
+	}
+	if probe.now != nil {
+		t.Error("Expected clock to be restored after simulation")
+	}
+}
+
+// clockProbe 记录控制器在模拟过程中看到的时间
+type clockProbe struct {
+	*congestion.RenoController
+	now         func() time.Time
+	first, last time.Time
+}
+
+func (p *clockProbe) SetClock(now func() time.Time) {
+	p.now = now
+	p.RenoController.SetClock(now)
+}
+
+func (p *clockProbe) OnAckReceived(ackSize int, rtt time.Duration) {
+	p.RenoController.OnAckReceived(ackSize, rtt)
+	ts := p.now()
+	if p.first.IsZero() {
+		p.first = ts
+	}
+	p.last = ts
+}
+
+func TestRunValidation(t *testing.T) {
+	link := testLink()
+	if _, err := Run(link); err == nil {
+		t.Error("Expected error without flows")
+	}
+	if _, err := Run(link, Flow{}); err == nil {
+		t.Error("Expected error for flow without controller")
+	}
+	bad := link
+	bad.LossRate = 1.5
+	if _, err := Run(bad, newTestFlow(t, congestion.AlgorithmReno, link)); err == nil {
+		t.Error("Expected error for invalid loss rate")
+	}
+	if _, err := NewFlow("unknown", link); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
+func TestCompareAlgorithms(t *testing.T) {
+	link := testLink()
+	algos := []congestion.AlgorithmType{congestion.AlgorithmReno, congestion.AlgorithmBBRv2}
+
+	comparisons, err := CompareAlgorithms(link, algos, 2)
+	if err != nil {
+		t.Fatalf("CompareAlgorithms error = %v", err)
+	}
+	if len(comparisons) != len(algos) {
+		t.Fatalf("got %d comparisons, want %d", len(comparisons), len(algos))
+	}
+	for i, c := range comparisons {
+		if c.Algorithm != algos[i] || len(c.Result.Flows) != 2 {
+			t.Errorf("comparison %d = %s with %d flows", i, c.Algorithm, len(c.Result.Flows))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteComparison(&buf, comparisons); err != nil {
+		t.Fatalf("WriteComparison error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"ALGORITHM", "reno", "bbr2", "Mbps"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comparison table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompete(t *testing.T) {
+	link := testLink()
+	res, err := Compete(link, []congestion.AlgorithmType{congestion.AlgorithmReno, congestion.AlgorithmBBRv2})
+	if err != nil {
+		t.Fatalf("Compete error = %v", err)
+	}
+	if len(res.Flows) != 2 {
+		t.Fatalf("got %d flows, want 2", len(res.Flows))
+	}
+
+	var buf bytes.Buffer
+	if err := res.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"FLOW", "reno", "bbr2", "TOTAL", "Jain fairness index"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("result table missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -9,6 +9,30 @@ import (
 // 特点：通过比较预期吞吐量和实际吞吐量检测拥塞，避免等到丢包才反应
 // ------------------------------
 
+type VegasConfig struct {
+	Alpha int `json:"alpha" yaml:"alpha"` // 最小允许的吞吐量差异（MSS个数，默认3）
+	Beta  int `json:"beta" yaml:"beta"`   // 最大允许的吞吐量差异（MSS个数，默认6）
+}
+
+func DefaultVegasConfig() VegasConfig {
+	return VegasConfig{Alpha: 3, Beta: 6}
+}
+
+// 零值字段使用默认值
+func (c VegasConfig) withDefaults() VegasConfig {
+	def := DefaultVegasConfig()
+	if c.Alpha <= 0 {
+		c.Alpha = def.Alpha
+	}
+	if c.Beta <= 0 {
+		c.Beta = def.Beta
+	}
+	if c.Beta < c.Alpha {
+		c.Beta = c.Alpha
+	}
+	return c
+}
+
 type VegasController struct {
 	*BaseController
 	alpha        int     // 最小允许的吞吐量差异（字节）
@@ -17,10 +41,15 @@ type VegasController struct {
 }
 
 func NewVegasController(initialCWnd, maxCWnd, packetSize int) *VegasController {
+	return NewVegasControllerWithConfig(DefaultVegasConfig(), initialCWnd, maxCWnd, packetSize)
+}
+
+func NewVegasControllerWithConfig(config VegasConfig, initialCWnd, maxCWnd, packetSize int) *VegasController {
+	config = config.withDefaults()
 	return &VegasController{
 		BaseController: NewBaseController(initialCWnd, maxCWnd, packetSize),
-		alpha:          3 * packetSize, // 通常为3个MSS
-		beta:           6 * packetSize, // 通常为6个MSS
+		alpha:          config.Alpha * packetSize,
+		beta:           config.Beta * packetSize,
 	}
 }
 
@@ -1,17 +1,23 @@
 package fillp
 
 import (
+	"time"
+
 	"github.com/junbin-yang/go-kitbox/pkg/congestion"
 )
 
 // ConnectionConfig FILLP连接配置
 type ConnectionConfig struct {
-	// 拥塞控制算法（可选，默认使用内置算法）
+	// 拥塞控制算法（可选，默认使用内置算法；可为 congestion.Register 注册的自定义算法名）
 	CongestionAlgorithm congestion.AlgorithmType
 
-	// 拥塞控制算法配置（可选）
+	// 拥塞控制算法配置（可选）：对应算法的配置结构体（如 congestion.BBRConfig），
+	// 或从配置文件解析出的 map
 	CongestionConfig interface{}
 
+	// TIME_WAIT持续时间（可选，0使用DefaultTimeWait，负值表示FIN确认后立即释放）
+	TimeWait time.Duration
+
 	// 其他配置项可以在这里扩展
 }
 
@@ -24,43 +24,55 @@ func (c *Connection) initCongestionControl(config ConnectionConfig) error {
 	maxCWnd := int(DefaultWindowSize)
 	packetSize := int(DefaultMTU)
 
-	// 如果是CUBIC且提供了配置
-	if config.CongestionAlgorithm == congestion.AlgorithmCubic {
-		if cubicCfg, ok := config.CongestionConfig.(congestion.CubicConfig); ok {
-			c.ccController = congestion.NewCubicControllerWithConfig(cubicCfg, initialCWnd, maxCWnd, packetSize)
-			return nil
-		}
-	}
-
-	// 使用默认配置创建控制器
-	ctrl, err := congestion.NewController(config.CongestionAlgorithm, initialCWnd, maxCWnd, packetSize)
+	// 按名称从注册表创建控制器（含自定义注册的算法），CongestionConfig 为空时使用算法默认配置
+	ctrl, err := congestion.NewControllerWithConfig(config.CongestionAlgorithm, config.CongestionConfig, initialCWnd, maxCWnd, packetSize)
 	if err != nil {
 		return err
 	}
-	c.ccController = ctrl
+	// 统一通过投递速率样本驱动：BBR/BBRv2 直接使用样本，其他算法经适配器转换为 OnAckReceived
+	c.ccController = congestion.AsRateController(ctrl)
+	c.rateSampler = congestion.NewRateSampler()
 	return nil
 }
 
-// onPacketSent 数据包发送通知
-func (c *Connection) onPacketSent(size int) {
+// onPacketSent 数据包发送通知（seq为数据包起始序列号）
+func (c *Connection) onPacketSent(seq uint32, size int) {
 	if c.useExternalCC && c.ccController != nil {
+		c.rateSampler.OnPacketSent(seq, size, time.Now())
 		c.ccController.OnPacketSent(size)
 	}
 }
 
-// onAckReceived ACK接收通知
-func (c *Connection) onAckReceived(size int, rtt time.Duration) {
+// onAckReceived ACK接收通知（ack为累计确认号，size为本次新确认的字节数）
+func (c *Connection) onAckReceived(ack uint32, size int, rtt time.Duration) {
 	if c.useExternalCC && c.ccController != nil {
-		c.ccController.OnAckReceived(size, rtt)
+		sample := c.rateSampler.OnCumulativeAck(ack, time.Now())
+		if sample.AckedBytes == 0 {
+			// 未被采样器跟踪的数据（如已按丢包剔除后又被确认）
+			sample.AckedBytes = size
+		}
+		sample.RTT = rtt
+		c.ccController.OnRateSample(sample)
 	} else {
 		// 使用内置算法
 		c.updateCongestionWindow(true)
 	}
 }
 
-// onPacketLost 丢包通知
-func (c *Connection) onPacketLost() {
+// onAppLimited 待发送数据耗尽而窗口仍有余量，此后的速率样本标记为应用受限
+func (c *Connection) onAppLimited() {
+	if c.useExternalCC && c.rateSampler != nil {
+		c.rateSampler.SetAppLimited()
+	}
+}
+
+// onPacketLost 丢包通知（seq为被判定丢失并重传的数据包起始序列号）
+func (c *Connection) onPacketLost(seq uint32) {
 	if c.useExternalCC && c.ccController != nil {
+		// 重传后重新计入飞行数据，丢失字节计入下一个速率样本
+		if size := c.rateSampler.OnPacketLost(seq); size > 0 {
+			c.rateSampler.OnPacketSent(seq, size, time.Now())
+		}
 		c.ccController.OnPacketLost()
 	} else {
 		// 使用内置算法
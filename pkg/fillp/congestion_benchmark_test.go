@@ -38,9 +38,9 @@ func BenchmarkCongestionAlgorithms(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
 				// 模拟数据包发送
-				conn.onPacketSent(1400)
+				conn.onPacketSent(uint32(i*1400), 1400)
 				// 模拟ACK接收
-				conn.onAckReceived(1400, 50*1000) // 50ms
+				conn.onAckReceived(uint32((i+1)*1400), 1400, 50*1000) // 50ms
 				// 获取拥塞窗口
 				_ = conn.getCongestionWindow()
 			}
@@ -109,7 +109,7 @@ func BenchmarkPacketSent(b *testing.B) {
 
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				conn.onPacketSent(1400)
+				conn.onPacketSent(uint32(i*1400), 1400)
 			}
 		})
 	}
@@ -143,7 +143,7 @@ func BenchmarkAckReceived(b *testing.B) {
 
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				conn.onAckReceived(1400, 50*1000)
+				conn.onAckReceived(uint32((i+1)*1400), 1400, 50*1000)
 			}
 		})
 	}
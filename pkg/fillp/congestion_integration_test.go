@@ -80,6 +80,33 @@ func TestCubicCustomConfig(t *testing.T) {
 	}
 }
 
+func TestCongestionConfigFromMap(t *testing.T) {
+	// 模拟从配置文件读取的算法名称与参数
+	config := ConnectionConfig{
+		CongestionAlgorithm: congestion.AlgorithmType("vegas"),
+		CongestionConfig:    map[string]interface{}{"alpha": 2, "beta": 4},
+	}
+
+	localAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0}
+	remoteAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9999}
+
+	conn, err := NewConnectionWithConfig(localAddr, remoteAddr, config)
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	defer conn.Close()
+
+	if !conn.UseExternalCC() {
+		t.Error("Expected external CC")
+	}
+
+	// 未注册的算法应报错
+	config.CongestionAlgorithm = "unknown"
+	if _, err := NewConnectionWithConfig(localAddr, remoteAddr, config); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
 func TestQuickConstructors(t *testing.T) {
 	localAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0}
 	remoteAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9999}
@@ -123,8 +150,8 @@ func TestCongestionAdapter(t *testing.T) {
 	defer conn.Close()
 
 	// 模拟数据包发送和ACK
-	conn.onPacketSent(1400)
-	conn.onAckReceived(1400, 50*time.Millisecond)
+	conn.onPacketSent(0, 1400)
+	conn.onAckReceived(1400, 1400, 50*time.Millisecond)
 
 	// 获取统计信息
 	stats := conn.GetCongestionStats()
@@ -137,9 +164,52 @@ func TestCongestionAdapter(t *testing.T) {
 	}
 
 	// 模拟丢包
-	conn.onPacketLost()
+	conn.onPacketLost(1400)
 	stats = conn.GetCongestionStats()
 	if stats.PacketsLost != 1 {
 		t.Errorf("Expected 1 packet lost, got %d", stats.PacketsLost)
 	}
 }
+
+func TestCongestionRateSampling(t *testing.T) {
+	config := ConnectionConfig{
+		CongestionAlgorithm: congestion.AlgorithmBBRv2,
+	}
+
+	localAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0}
+	remoteAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9999}
+
+	conn, err := NewConnectionWithConfig(localAddr, remoteAddr, config)
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	defer conn.Close()
+
+	for i := 0; i < 4; i++ {
+		conn.onPacketSent(uint32(i*1400), 1400)
+	}
+	if inFlight := conn.rateSampler.InFlight(); inFlight != 5600 {
+		t.Errorf("Expected 5600 bytes in flight, got %d", inFlight)
+	}
+
+	// 第二个包丢失并重传：仍在飞行中，丢失字节计入下一个样本
+	conn.onPacketLost(1400)
+	if inFlight := conn.rateSampler.InFlight(); inFlight != 5600 {
+		t.Errorf("Retransmitted packet should stay in flight, got %d", inFlight)
+	}
+
+	// 累计确认前两个包
+	time.Sleep(10 * time.Millisecond)
+	conn.onAckReceived(2800, 2800, 50*time.Millisecond)
+	if delivered := conn.rateSampler.Delivered(); delivered != 2800 {
+		t.Errorf("Expected 2800 bytes delivered, got %d", delivered)
+	}
+	if inFlight := conn.rateSampler.InFlight(); inFlight != 2800 {
+		t.Errorf("Expected 2800 bytes in flight, got %d", inFlight)
+	}
+
+	stats := conn.GetCongestionStats()
+	if stats.PacketsLost != 1 {
+		t.Errorf("Expected 1 packet lost, got %d", stats.PacketsLost)
+	}
+}
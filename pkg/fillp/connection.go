@@ -20,9 +20,12 @@ const (
 	StateIdle       = iota // 空闲状态：初始状态
 	StateConnecting        // 连接中：正在建立连接
 	StateConnected         // 已连接：连接已建立
-	StateClosing           // 关闭中：正在关闭连接
+	StateClosing           // 关闭中：双方均已发送FIN（或正在中止），等待本端FIN被确认
 	StateClosed            // 已关闭：连接已关闭
 	StateListening         // 监听中：服务端正在等待客户端连接
+	StateFinWait           // 半关闭：本端已关闭写方向，仍可接收对端数据
+	StateCloseWait         // 半关闭：对端已关闭写方向，本端仍可发送数据
+	StateTimeWait          // 时间等待：有序关闭完成，保留端口以吸收迟到报文
 )
 
 // 协议常量定义
@@ -35,6 +38,7 @@ const (
 	InitialRTO         = 200 * time.Millisecond // 初始重传超时时间（降低以加快首次重传）
 	MinRTO             = 50 * time.Millisecond  // 最小重传超时时间（本机回环可更激进）
 	MaxRTO             = 10 * time.Second       // 最大重传超时时间
+	DefaultTimeWait    = 2 * time.Second        // 默认TIME_WAIT持续时间
 )
 
 // 数据包类型常量
@@ -47,6 +51,11 @@ const (
 	PacketTypeWindowUpdate        // 窗口更新报文
 )
 
+// 数据包标志位
+const (
+	FlagAbort = 1 << iota // 中止标志(FIN携带)：对端立即释放连接，不再等待数据排空
+)
+
 // Connection 表示一个FILLP连接
 type Connection struct {
 	mu sync.RWMutex // 读写锁，保护连接状态和属性
@@ -64,8 +73,9 @@ type Connection struct {
 	ssthresh      uint32 // 慢启动阈值（内置算法使用）
 
 	// 拥塞控制（可选外部算法）
-	useExternalCC bool                  // 是否使用外部拥塞控制
-	ccController  congestion.Controller // 外部拥塞控制器
+	useExternalCC bool                      // 是否使用外部拥塞控制
+	ccController  congestion.RateController // 外部拥塞控制器
+	rateSampler   *congestion.RateSampler   // 投递速率采样器（仅外部拥塞控制）
 
 	// 序列号
 	sendSeq    uint32 // 发送序列号
@@ -101,6 +111,17 @@ type Connection struct {
 	ackTimer      *time.Timer   // ACK延迟定时器
 	delayedAckDur time.Duration // 延迟ACK时长
 
+	// 半关闭与有序关闭
+	finPending  bool          // 已请求关闭写方向，待发送缓冲区排空后发送FIN
+	finSent     bool          // 本端FIN已发送
+	finSeq      uint32        // 本端FIN占用的序列号
+	finAcked    bool          // 本端FIN已被确认
+	finAckedCh  chan struct{} // 本端FIN被确认时关闭
+	activeClose bool          // 是否由本端先发起关闭（决定是否进入TIME_WAIT）
+	peerFin     atomic.Bool   // 已收到对端FIN（读方向已关闭）
+	timeWait    time.Duration // TIME_WAIT持续时间
+	closeOnce   sync.Once     // 保证连接资源只释放一次
+
 	// 上下文
 	ctx    context.Context    // 上下文
 	cancel context.CancelFunc // 取消函数
@@ -179,6 +200,8 @@ func NewConnection(localAddr, remoteAddr net.Addr) (*Connection, error) {
 		closeChan:     make(chan struct{}),
 		listenChan:    make(chan struct{}, 1),
 		delayedAckDur: 40 * time.Millisecond, // 延迟ACK 40ms
+		finAckedCh:    make(chan struct{}),
+		timeWait:      DefaultTimeWait,
 		ctx:           ctx,
 		cancel:        cancel,
 		createdTime:   time.Now(),
@@ -319,7 +342,10 @@ func (c *Connection) Listen() error {
 
 // 发送数据到连接
 func (c *Connection) Send(data []byte) error {
-	if atomic.LoadInt32(&c.state) != StateConnected {
+	if !c.canWrite() {
+		if c.isEstablished() {
+			return fmt.Errorf("connection write side closed")
+		}
 		return fmt.Errorf("connection not established")
 	}
 
@@ -350,11 +376,15 @@ func (c *Connection) Send(data []byte) error {
 
 // 从连接接收数据
 func (c *Connection) Receive() ([]byte, error) {
-	if atomic.LoadInt32(&c.state) != StateConnected {
+	if !c.isEstablished() {
 		return nil, fmt.Errorf("connection not established")
 	}
 
 	for {
+		// 对端已关闭写方向：读尽剩余数据后返回EOF
+		if c.peerFin.Load() {
+			return c.readRemaining()
+		}
 		select {
 		case <-c.recvReady:
 			// 直接读取可用数据，避免二次等待导致空读取
@@ -381,12 +411,16 @@ func (c *Connection) Receive() ([]byte, error) {
 
 // 带超时的接收方法
 func (c *Connection) ReceiveWithTimeout(timeout time.Duration) ([]byte, error) {
-	if atomic.LoadInt32(&c.state) != StateConnected {
+	if !c.isEstablished() {
 		return nil, fmt.Errorf("connection not established")
 	}
 	deadline := time.NewTimer(timeout)
 	defer deadline.Stop()
 	for {
+		// 对端已关闭写方向：读尽剩余数据后返回EOF
+		if c.peerFin.Load() {
+			return c.readRemaining()
+		}
 		select {
 		case <-c.recvReady:
 			// 直接读取缓冲区可用数据，避免二次等待导致的空读
@@ -413,38 +447,36 @@ func (c *Connection) ReceiveWithTimeout(timeout time.Duration) ([]byte, error) {
 	}
 }
 
-// 关闭连接
+// 关闭连接（立即释放，发送缓冲区中未确认的数据将被丢弃；需要排空数据请使用 Shutdown）
 func (c *Connection) Close() error {
 	// 检查并更新状态为关闭中
-	if !c.compareAndSwapState(StateConnected, StateClosing) &&
-		!c.compareAndSwapState(StateConnecting, StateClosing) {
-		return nil // 已处于关闭或关闭中状态
+	state := atomic.LoadInt32(&c.state)
+	switch state {
+	case StateConnected, StateConnecting, StateFinWait, StateCloseWait:
+		if !c.compareAndSwapState(state, StateClosing) {
+			return c.Close() // 状态被并发修改，重新判断
+		}
+	case StateClosing, StateTimeWait:
+		// 有序关闭进行中：直接终止
+		c.teardown()
+		return nil
+	default:
+		return nil // 已关闭或尚未建立连接
 	}
 
 	c.logger.Debugf("Closing connection")
 
-	// 发送FIN包通知对方关闭连接
-	err := c.sendFin()
-	if err != nil {
-		return err
-	}
-
-	// 取消上下文
-	c.cancel()
-
-	// 关闭UDP连接
-	if c.conn != nil {
-		c.conn.Close()
-	}
-
-	// 更新状态为已关闭
-	atomic.StoreInt32(&c.state, StateClosed)
+	// 发送带中止标志的FIN包通知对方立即关闭连接
+	err := c.sendFin(FlagAbort)
 
-	close(c.closeChan)
+	c.teardown()
 
-	c.logger.Debugf("Connection closed: bytesSent=%d bytesReceived=%d", c.stats.BytesSent, c.stats.BytesReceived)
+	return err
+}
 
-	return nil
+// 返回连接当前状态
+func (c *Connection) State() int32 {
+	return atomic.LoadInt32(&c.state)
 }
 
 // 设置流量控制窗口大小
@@ -495,9 +527,10 @@ func (c *Connection) sendSyn() error {
 }
 
 // sendFin 发送FIN包关闭连接
-func (c *Connection) sendFin() error {
+func (c *Connection) sendFin(flags uint8) error {
 	packet := &Packet{
 		Type:      PacketTypeFin,
+		Flags:     flags,
 		Sequence:  c.sendSeq,
 		Timestamp: uint32(time.Since(c.createdTime).Milliseconds()),
 	}
@@ -551,7 +584,7 @@ func (c *Connection) sendPacket(packet *Packet) error {
 
 	// 通知拥塞控制：数据包已发送
 	if packet.Type == PacketTypeData {
-		c.onPacketSent(len(packet.Data))
+		c.onPacketSent(packet.Sequence, len(packet.Data))
 	}
 
 	return nil
@@ -721,6 +754,7 @@ func (c *Connection) sendWorker() {
 		case <-c.sendReady:
 			// 发送待处理数据
 			c.sendPendingData()
+			c.flushFin()
 		}
 	}
 }
@@ -790,6 +824,12 @@ func (c *Connection) processPacket(packet *Packet) {
 func (c *Connection) handleDataPacket(packet *Packet) {
 	c.logger.Debugf("RX Data: seq=%d expected=%d len=%d", packet.Sequence, c.receiveSeq, len(packet.Data))
 
+	// 对端FIN之后的数据不再接收（TIME_WAIT只在收到对端FIN后进入，迟到的数据包同样只回复确认）
+	if c.peerFin.Load() {
+		_ = c.sendAckPacket(c.receiveSeq, packet.Timestamp)
+		return
+	}
+
 	// 忽略空数据包：不推进序列，回显当前累计ACK，避免状态卡死
 	if len(packet.Data) == 0 {
 		_ = c.sendAckPacket(c.receiveSeq, packet.Timestamp)
@@ -878,6 +918,12 @@ func (c *Connection) handleAckPacket(packet *Packet) {
 	// 基于累计ACK进行裁切，保留未确认尾部
 	c.retransQueue.TrimUpTo(packet.Ack)
 
+	// 本端FIN被确认：停止重传FIN
+	if c.finSent && !c.finAcked && packet.Ack > c.finSeq {
+		c.retransQueue.Remove(c.finSeq)
+		c.onFinAcked()
+	}
+
 	// 处理ACK前进/重复ACK（快速重传）
 	if packet.Ack > c.sendAck {
 		// ACK 前进：更新确认号，重置重复计数，并调整拥塞窗口
@@ -887,13 +933,14 @@ func (c *Connection) handleAckPacket(packet *Packet) {
 
 		// 通知拥塞控制：收到ACK
 		if rtt > 0 && rtt < 60*time.Second {
-			c.onAckReceived(int(packet.Ack-oldSendAck), rtt)
+			c.onAckReceived(packet.Ack, int(packet.Ack-oldSendAck), rtt)
 		} else {
 			c.updateCongestionWindow(true)
 		}
 
 		// ACK前进释放了窗口，立刻尝试发送以"补洞"，避免等待 sendReady 才发送
 		c.sendPendingData()
+		c.trySendFin()
 	} else if packet.Ack == c.sendAck {
 		// 重复ACK：计数+1，达到3次触发快速重传最早未确认片段
 		c.dupAckCount++
@@ -906,7 +953,7 @@ func (c *Connection) handleAckPacket(packet *Packet) {
 					entry.NextRetrans = time.Now().Add(c.rto).UnixMilli()
 					c.retransQueue.Update(entry)
 					// 通知拥塞控制：丢包
-					c.onPacketLost()
+					c.onPacketLost(entry.Sequence)
 				}
 			}
 			// 重置重复计数以避免频繁触发
@@ -949,11 +996,47 @@ func (c *Connection) handleSynPacket(packet *Packet) {
 
 // handleFinPacket 处理结束报文
 func (c *Connection) handleFinPacket(packet *Packet) {
-	// 发送FIN-ACK响应
-	_ = c.sendAckPacket(packet.Sequence, packet.Timestamp)
+	// 中止关闭：发送FIN-ACK响应后立即关闭连接
+	if packet.Flags&FlagAbort != 0 {
+		_ = c.sendAckPacket(packet.Sequence+1, packet.Timestamp)
+		c.Close()
+		return
+	}
+
+	// 重传的FIN（之前的确认丢失）：重新确认
+	if c.peerFin.Load() && packet.Sequence+1 == c.receiveSeq {
+		_ = c.sendAckPacket(c.receiveSeq, packet.Timestamp)
+		return
+	}
+
+	// FIN需按序处理：之前的数据尚未全部到达时回复当前进度，等待对端重传
+	if packet.Sequence != c.receiveSeq {
+		c.logger.Debugf("Out-of-order FIN: expected=%d received=%d", c.receiveSeq, packet.Sequence)
+		_ = c.sendAckPacket(c.receiveSeq, packet.Timestamp)
+		return
+	}
+
+	// FIN占用一个序列号
+	c.receiveSeq++
+	c.peerFin.Store(true)
+	_ = c.sendAckPacket(c.receiveSeq, packet.Timestamp)
 
-	// 关闭连接
-	c.Close()
+	switch atomic.LoadInt32(&c.state) {
+	case StateConnected:
+		c.compareAndSwapState(StateConnected, StateCloseWait)
+	case StateFinWait:
+		if c.finAcked {
+			c.enterTimeWait()
+		} else {
+			c.compareAndSwapState(StateFinWait, StateClosing)
+		}
+	}
+
+	// 唤醒等待中的Receive，使其读尽数据后返回EOF
+	select {
+	case c.recvReady <- struct{}{}:
+	default:
+	}
 }
 
 // 处理保活报文（回复ACK即可）
@@ -971,12 +1054,14 @@ func (c *Connection) handleWindowUpdate(packet *Packet) {
 
 // sendPendingData 发送缓冲区中的待发送数据（基于滑动窗口：限制未确认字节数）
 func (c *Connection) sendPendingData() {
-	if atomic.LoadInt32(&c.state) != StateConnected {
+	switch atomic.LoadInt32(&c.state) {
+	case StateConnected, StateFinWait, StateCloseWait, StateClosing:
+	default:
 		return
 	}
 	for {
 		// 无数据或对端窗口为0则退出
-		if c.sendBuffer.Readable() == 0 || c.sendWindow == 0 {
+		if c.sendWindow == 0 {
 			break
 		}
 
@@ -985,8 +1070,15 @@ func (c *Connection) sendPendingData() {
 
 		// 本次允许的最大发送量 = min(对端窗口, 拥塞窗口) - inFlight
 		cwnd := c.sendWindow
-		if c.congestionWnd < cwnd {
-			cwnd = c.congestionWnd
+		if congestionWnd := c.getCongestionWindow(); congestionWnd < cwnd {
+			cwnd = congestionWnd
+		}
+		if c.sendBuffer.Readable() == 0 {
+			// 窗口仍有余量但无数据可发：发送受应用层限制
+			if inFlight < cwnd {
+				c.onAppLimited()
+			}
+			break
 		}
 		if inFlight >= cwnd {
 			// 已达到窗口上限，等待ACK推进
@@ -1067,7 +1159,7 @@ func (c *Connection) checkRetransmissions() {
 
 		// 通知拥塞控制：超时丢包（仅第一次重传时通知）
 		if p.Attempts == 1 {
-			c.onPacketLost()
+			c.onPacketLost(p.Sequence)
 		}
 
 		c.logger.Debugf("Retransmitted packet: sequence=%d retries=%d", p.Sequence, p.Attempts)
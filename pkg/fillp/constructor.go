@@ -14,6 +14,10 @@ func NewConnectionWithConfig(localAddr, remoteAddr net.Addr, config ConnectionCo
 		return nil, err
 	}
 
+	if config.TimeWait != 0 {
+		conn.timeWait = config.TimeWait
+	}
+
 	// 初始化拥塞控制
 	if err := conn.initCongestionControl(config); err != nil {
 		conn.Close()
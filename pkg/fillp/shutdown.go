@@ -0,0 +1,186 @@
+package fillp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// 半关闭与有序关闭
+//
+// 状态迁移（与TCP类似）：
+//   主动关闭: Connected -CloseWrite-> FinWait -收到FIN-> Closing -FIN被确认-> TimeWait -> Closed
+//             Connected -CloseWrite-> FinWait -FIN被确认-> FinWait -收到FIN-> TimeWait -> Closed
+//   FIN被确认后仍停留在FinWait，继续接收并确认对端数据，直到收到对端FIN才进入TimeWait
+//   被动关闭: Connected -收到FIN-> CloseWait -CloseWrite-> Closing -FIN被确认-> Closed
+// Close 为中止关闭，任意状态下立即释放连接。
+
+// CloseWrite 半关闭：关闭写方向，待发送缓冲区排空后发送FIN，仍可继续接收对端数据
+func (c *Connection) CloseWrite() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.finPending {
+		return nil // 已关闭写方向
+	}
+
+	switch atomic.LoadInt32(&c.state) {
+	case StateConnected:
+		if !c.compareAndSwapState(StateConnected, StateFinWait) {
+			return fmt.Errorf("connection state changed concurrently")
+		}
+		c.activeClose = true
+	case StateCloseWait:
+		if !c.compareAndSwapState(StateCloseWait, StateClosing) {
+			return fmt.Errorf("connection state changed concurrently")
+		}
+	default:
+		return fmt.Errorf("connection not established")
+	}
+
+	c.logger.Debugf("Write side closed: pending=%d", c.sendBuffer.Readable())
+
+	c.finPending = true
+	c.sendPendingData()
+	c.trySendFin()
+	return nil
+}
+
+// Shutdown 有序关闭：关闭写方向，等待已发送数据和FIN全部被确认后返回
+// 返回后仍可读取对端剩余数据，收到对端FIN后进入TIME_WAIT；被动关闭方（已收到对端FIN）返回时连接已释放
+// ctx 到期时强制关闭连接并返回 ctx 的错误
+func (c *Connection) Shutdown(ctx context.Context) error {
+	if err := c.CloseWrite(); err != nil {
+		return err
+	}
+
+	select {
+	case <-c.finAckedCh:
+		return nil
+	case <-c.closeChan:
+		// 被动关闭方FIN被确认后立即释放连接，两个通道可能同时就绪，以FIN是否被确认为准
+		select {
+		case <-c.finAckedCh:
+			return nil
+		default:
+		}
+		return fmt.Errorf("connection closed before shutdown completed")
+	case <-ctx.Done():
+		c.logger.Warnf("Shutdown deadline exceeded, closing connection: unacked=%d", c.retransQueue.Size())
+		c.Close()
+		return ctx.Err()
+	}
+}
+
+// SetTimeWait 设置TIME_WAIT持续时间（<=0 表示FIN确认后立即释放）
+func (c *Connection) SetTimeWait(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeWait = d
+}
+
+// isEstablished 连接是否已建立（含半关闭与关闭过程中的状态）
+func (c *Connection) isEstablished() bool {
+	switch atomic.LoadInt32(&c.state) {
+	case StateConnected, StateFinWait, StateCloseWait, StateClosing, StateTimeWait:
+		return true
+	}
+	return false
+}
+
+// canWrite 写方向是否可用
+func (c *Connection) canWrite() bool {
+	state := atomic.LoadInt32(&c.state)
+	return state == StateConnected || state == StateCloseWait
+}
+
+// readRemaining 对端已关闭写方向时读取接收缓冲区剩余数据，读尽后返回io.EOF
+func (c *Connection) readRemaining() ([]byte, error) {
+	n := c.receiveBuffer.Readable()
+	if n == 0 {
+		return nil, io.EOF
+	}
+	return c.receiveBuffer.Read(n)
+}
+
+// flushFin 加锁尝试发送FIN（供发送协程使用）
+func (c *Connection) flushFin() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trySendFin()
+}
+
+// trySendFin 发送缓冲区排空后发送FIN，调用方需持有c.mu
+// FIN与数据包一样由 sendPacket 加入重传队列，按RTO和指数退避重传，直到对端确认 finSeq+1；
+// 对端在之前的数据到齐前会丢弃FIN，同样依靠重传送达
+func (c *Connection) trySendFin() {
+	if !c.finPending || c.finSent || c.sendBuffer.Readable() > 0 {
+		return
+	}
+
+	c.finSeq = c.sendSeq
+	if err := c.sendFin(0); err != nil {
+		c.logger.Errorf("Failed to send FIN: %v", err)
+		return
+	}
+	// FIN占用一个序列号
+	c.sendSeq++
+	c.finSent = true
+	c.logger.Debugf("TX FIN: seq=%d", c.finSeq)
+}
+
+// onFinAcked 本端FIN被确认，调用方需持有c.mu
+func (c *Connection) onFinAcked() {
+	c.finAcked = true
+	close(c.finAckedCh)
+	c.logger.Debugf("FIN acknowledged: seq=%d", c.finSeq)
+
+	if atomic.LoadInt32(&c.state) != StateClosing {
+		return // FinWait：继续等待对端FIN或Shutdown
+	}
+	if c.activeClose {
+		c.enterTimeWait()
+	} else {
+		// 被动关闭方无需TIME_WAIT
+		c.teardown()
+	}
+}
+
+// enterTimeWait 进入TIME_WAIT并在超时后释放连接，调用方需持有c.mu
+func (c *Connection) enterTimeWait() {
+	state := atomic.LoadInt32(&c.state)
+	if state != StateFinWait && state != StateClosing {
+		return
+	}
+	if !c.compareAndSwapState(state, StateTimeWait) {
+		return
+	}
+	if c.timeWait <= 0 {
+		c.teardown()
+		return
+	}
+	c.logger.Debugf("Entering TIME_WAIT: duration=%v", c.timeWait)
+	time.AfterFunc(c.timeWait, c.teardown)
+}
+
+// teardown 释放连接资源（仅执行一次）
+func (c *Connection) teardown() {
+	c.closeOnce.Do(func() {
+		// 取消上下文
+		c.cancel()
+
+		// 关闭UDP连接
+		if c.conn != nil {
+			c.conn.Close()
+		}
+
+		// 更新状态为已关闭
+		atomic.StoreInt32(&c.state, StateClosed)
+
+		close(c.closeChan)
+
+		c.logger.Debugf("Connection closed: bytesSent=%d bytesReceived=%d", c.stats.BytesSent, c.stats.BytesReceived)
+	})
+}
@@ -0,0 +1,409 @@
+package fillp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newConnectedPair 建立一对已连接的客户端/服务端
+func newConnectedPair(t *testing.T, port int) (*Connection, *Connection) {
+	t.Helper()
+	serverAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}
+
+	server, err := NewConnection(serverAddr, nil)
+	if err != nil {
+		t.Fatalf("创建服务端失败: %v", err)
+	}
+	serverReady := make(chan error, 1)
+	go func() { serverReady <- server.Listen() }()
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := NewConnection(nil, serverAddr)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	if err := <-serverReady; err != nil {
+		t.Fatalf("服务端监听失败: %v", err)
+	}
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+// receiveAll 持续接收直到EOF
+func receiveAll(t *testing.T, conn *Connection, timeout time.Duration) []byte {
+	t.Helper()
+	var received []byte
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		data, err := conn.ReceiveWithTimeout(500 * time.Millisecond)
+		if errors.Is(err, io.EOF) {
+			return received
+		}
+		if err != nil {
+			continue
+		}
+		received = append(received, data...)
+	}
+	t.Fatalf("接收超时，未收到EOF，已接收 %d 字节", len(received))
+	return nil
+}
+
+// waitState 等待连接进入指定状态
+func waitState(t *testing.T, conn *Connection, want int32, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if conn.State() == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("状态未变为 %d，当前 %d", want, conn.State())
+}
+
+func TestCloseWrite_HalfClose(t *testing.T) {
+	client, server := newConnectedPair(t, 9201)
+
+	if err := client.Send([]byte("request")); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if err := client.CloseWrite(); err != nil {
+		t.Fatalf("半关闭失败: %v", err)
+	}
+	if client.State() != StateFinWait {
+		t.Errorf("客户端状态应为 FinWait，实际 %d", client.State())
+	}
+
+	// 半关闭后不允许继续写
+	if err := client.Send([]byte("more")); err == nil {
+		t.Error("半关闭后发送应返回错误")
+	}
+
+	// 服务端读到数据后收到EOF
+	if got := receiveAll(t, server, 3*time.Second); string(got) != "request" {
+		t.Errorf("接收数据不匹配: %q", got)
+	}
+	if server.State() != StateCloseWait {
+		t.Errorf("服务端状态应为 CloseWait，实际 %d", server.State())
+	}
+
+	// 服务端仍可发送响应
+	if err := server.Send([]byte("response")); err != nil {
+		t.Fatalf("服务端发送失败: %v", err)
+	}
+	if err := server.CloseWrite(); err != nil {
+		t.Fatalf("服务端半关闭失败: %v", err)
+	}
+	if got := receiveAll(t, client, 3*time.Second); string(got) != "response" {
+		t.Errorf("接收响应不匹配: %q", got)
+	}
+
+	// 被动关闭方直接关闭，主动关闭方进入TIME_WAIT
+	waitState(t, server, StateClosed, 3*time.Second)
+	waitState(t, client, StateTimeWait, 3*time.Second)
+}
+
+func TestShutdown_DrainsData(t *testing.T) {
+	client, server := newConnectedPair(t, 9202)
+	client.SetTimeWait(300 * time.Millisecond)
+
+	payload := bytes.Repeat([]byte("0123456789"), 3000)
+	if err := client.Send(payload); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- client.Shutdown(ctx) }()
+
+	if got := receiveAll(t, server, 10*time.Second); !bytes.Equal(got, payload) {
+		t.Errorf("数据未完整送达: 期望 %d 字节，实际 %d 字节", len(payload), len(got))
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("有序关闭失败: %v", err)
+	}
+	// 对端尚未关闭写方向：FIN已确认但仍停留在FinWait继续接收
+	if state := client.State(); state != StateFinWait {
+		t.Errorf("客户端状态应为 FinWait，实际 %d", state)
+	}
+
+	// 对端在半关闭后发送的数据仍能送达
+	if err := server.Send([]byte("late response")); err != nil {
+		t.Fatalf("服务端发送失败: %v", err)
+	}
+	if err := server.CloseWrite(); err != nil {
+		t.Fatalf("服务端半关闭失败: %v", err)
+	}
+	if got := receiveAll(t, client, 3*time.Second); string(got) != "late response" {
+		t.Errorf("有序关闭后接收数据不匹配: %q", got)
+	}
+
+	waitState(t, server, StateClosed, 3*time.Second)
+	waitState(t, client, StateClosed, 3*time.Second)
+}
+
+func TestShutdown_PassiveClose(t *testing.T) {
+	// 被动关闭方FIN被确认时同时关闭finAckedCh和closeChan，多次执行以覆盖select的随机选择
+	for i, port := range []int{9203, 9204, 9205, 9206, 9207} {
+		client, server := newConnectedPair(t, port)
+		client.SetTimeWait(100 * time.Millisecond)
+
+		// 对端半关闭
+		if err := client.CloseWrite(); err != nil {
+			t.Fatalf("第%d次: 客户端半关闭失败: %v", i, err)
+		}
+		if got := receiveAll(t, server, 3*time.Second); len(got) != 0 {
+			t.Fatalf("第%d次: 不应收到数据: %q", i, got)
+		}
+
+		if err := server.Send([]byte("bye")); err != nil {
+			t.Fatalf("第%d次: 服务端发送失败: %v", i, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := server.Shutdown(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("第%d次: 被动关闭方有序关闭失败: %v", i, err)
+		}
+		if got := receiveAll(t, client, 3*time.Second); string(got) != "bye" {
+			t.Errorf("第%d次: 接收数据不匹配: %q", i, got)
+		}
+		waitState(t, server, StateClosed, 3*time.Second)
+		waitState(t, client, StateClosed, 3*time.Second)
+	}
+}
+
+func TestHandleDataPacket_FinWaitAfterFinAcked(t *testing.T) {
+	conn, _ := NewConnection(nil, nil)
+	conn.conn, _ = net.ListenPacket("udp", "127.0.0.1:0")
+	defer conn.Close()
+	conn.state = StateFinWait
+	conn.finAcked = true
+	conn.receiveSeq = 100
+
+	// FIN被确认后仍接收对端数据
+	conn.handleDataPacket(&Packet{Type: PacketTypeData, Sequence: 100, Data: []byte("abc")})
+	if conn.receiveSeq != 103 || conn.receiveBuffer.Readable() != 3 {
+		t.Errorf("FinWait应继续接收数据: receiveSeq=%d readable=%d", conn.receiveSeq, conn.receiveBuffer.Readable())
+	}
+
+	// 收到对端FIN后才进入TIME_WAIT
+	conn.handleFinPacket(&Packet{Type: PacketTypeFin, Sequence: 103})
+	if conn.State() != StateTimeWait {
+		t.Errorf("收到对端FIN后应进入 TimeWait，实际 %d", conn.State())
+	}
+}
+
+func TestShutdown_DeadlineExceeded(t *testing.T) {
+	// 对端不响应任何报文
+	peer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("创建对端失败: %v", err)
+	}
+	defer peer.Close()
+
+	conn, _ := NewConnection(nil, peer.LocalAddr())
+	conn.conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("创建本端失败: %v", err)
+	}
+	conn.state = StateConnected
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := conn.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误，实际 %v", err)
+	}
+	if conn.State() != StateClosed {
+		t.Errorf("超时后应强制关闭，实际状态 %d", conn.State())
+	}
+}
+
+func TestHandleFinPacket_OutOfOrder(t *testing.T) {
+	conn, _ := NewConnection(nil, nil)
+	conn.state = StateConnected
+	conn.receiveSeq = 100
+
+	// 数据未全部到达时FIN不生效
+	conn.handleFinPacket(&Packet{Type: PacketTypeFin, Sequence: 120})
+	if conn.peerFin.Load() || conn.State() != StateConnected {
+		t.Error("乱序FIN不应关闭读方向")
+	}
+
+	conn.handleFinPacket(&Packet{Type: PacketTypeFin, Sequence: 100})
+	if !conn.peerFin.Load() || conn.State() != StateCloseWait {
+		t.Errorf("按序FIN应进入 CloseWait，实际状态 %d", conn.State())
+	}
+	if conn.receiveSeq != 101 {
+		t.Errorf("FIN应占用一个序列号，receiveSeq=%d", conn.receiveSeq)
+	}
+}
+
+// lossyProxy 转发客户端与服务端之间的UDP报文，丢弃客户端发出的、类型为 dropType 的前 drop 个报文
+type lossyProxy struct {
+	front    net.PacketConn // 面向客户端
+	back     net.PacketConn // 面向服务端
+	dropType uint8
+	drop     int32
+	dropped  atomic.Int32
+	client   atomic.Pointer[net.Addr]
+}
+
+func newLossyProxy(t *testing.T, server net.Addr, dropType uint8, drop int32) *lossyProxy {
+	t.Helper()
+	front, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("创建代理失败: %v", err)
+	}
+	back, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		front.Close()
+		t.Fatalf("创建代理失败: %v", err)
+	}
+	p := &lossyProxy{front: front, back: back, dropType: dropType, drop: drop}
+	t.Cleanup(func() {
+		front.Close()
+		back.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 65536)
+		for {
+			n, addr, err := front.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			p.client.Store(&addr)
+			// 中止FIN（Close发出）不丢弃
+			if n > 1 && buf[0] == p.dropType && buf[1]&FlagAbort == 0 && p.dropped.Load() < p.drop {
+				p.dropped.Add(1)
+				continue
+			}
+			_, _ = back.WriteTo(buf[:n], server)
+		}
+	}()
+	go func() {
+		buf := make([]byte, 65536)
+		for {
+			n, _, err := back.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if addr := p.client.Load(); addr != nil {
+				_, _ = front.WriteTo(buf[:n], *addr)
+			}
+		}
+	}()
+	return p
+}
+
+// newProxiedPair 建立经过丢包代理的一对连接
+func newProxiedPair(t *testing.T, port int, dropType uint8, drop int32) (*Connection, *Connection, *lossyProxy) {
+	t.Helper()
+	serverAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}
+	server, err := NewConnection(serverAddr, nil)
+	if err != nil {
+		t.Fatalf("创建服务端失败: %v", err)
+	}
+	serverReady := make(chan error, 1)
+	go func() { serverReady <- server.Listen() }()
+	time.Sleep(100 * time.Millisecond)
+
+	proxy := newLossyProxy(t, serverAddr, dropType, drop)
+	client, err := NewConnection(nil, proxy.front.LocalAddr())
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	if err := <-serverReady; err != nil {
+		t.Fatalf("服务端监听失败: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server, proxy
+}
+
+func TestShutdown_RetransmitsLostFin(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     int
+		dropType uint8
+	}{
+		{"首个FIN丢失", 9208, PacketTypeFin},
+		{"FIN之前的数据丢失", 9209, PacketTypeData}, // FIN先于数据到达，被对端丢弃后依靠重传送达
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server, proxy := newProxiedPair(t, tt.port, tt.dropType, 1)
+
+			if err := client.Send([]byte("payload")); err != nil {
+				t.Fatalf("发送失败: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := client.Shutdown(ctx); err != nil {
+				t.Fatalf("有序关闭失败: %v", err)
+			}
+			if got := proxy.dropped.Load(); got != 1 {
+				t.Fatalf("期望丢弃1个报文，实际 %d", got)
+			}
+			if got := receiveAll(t, server, 3*time.Second); string(got) != "payload" {
+				t.Errorf("接收数据不匹配: %q", got)
+			}
+			waitState(t, server, StateCloseWait, 2*time.Second)
+		})
+	}
+}
+
+func TestTrySendFin_QueuedForRetransmission(t *testing.T) {
+	peer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("创建对端失败: %v", err)
+	}
+	defer peer.Close()
+
+	conn, _ := NewConnection(nil, peer.LocalAddr())
+	conn.conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("创建本端失败: %v", err)
+	}
+	defer conn.Close()
+	conn.state = StateConnected
+	conn.sendSeq = 500
+	conn.sendAck = 500
+
+	if err := conn.CloseWrite(); err != nil {
+		t.Fatalf("半关闭失败: %v", err)
+	}
+	if entry := conn.retransQueue.PeekEarliest(); entry == nil || entry.Sequence != 500 {
+		t.Fatalf("FIN应加入重传队列: %+v", entry)
+	}
+
+	// 对端确认FIN后停止重传
+	conn.handleAckPacket(&Packet{Type: PacketTypeAck, Ack: 501})
+	if conn.retransQueue.Size() != 0 {
+		t.Errorf("FIN被确认后重传队列应为空，实际 %d", conn.retransQueue.Size())
+	}
+	if !conn.finAcked {
+		t.Error("FIN应被标记为已确认")
+	}
+}
@@ -1,7 +1,9 @@
 package netconn
 
 import (
+	"crypto/tls"
 	"net"
+	"time"
 
 	"github.com/junbin-yang/go-kitbox/pkg/fillp"
 )
@@ -35,9 +37,23 @@ func (t *TCPConnection) RemoteAddr() net.Addr {
 	return t.conn.RemoteAddr()
 }
 
+// SetWriteDeadline 设置写截止时间
+func (t *TCPConnection) SetWriteDeadline(deadline time.Time) error {
+	return t.conn.SetWriteDeadline(deadline)
+}
+
+// ConnectionState 返回TLS连接状态，非TLS连接返回false
+func (t *TCPConnection) ConnectionState() (tls.ConnectionState, bool) {
+	if tlsConn, ok := t.conn.(*tls.Conn); ok {
+		return tlsConn.ConnectionState(), true
+	}
+	return tls.ConnectionState{}, false
+}
+
 // UDPConnection UDP连接适配器（基于FILLP）
 type UDPConnection struct {
-	conn *fillp.Connection
+	conn    *fillp.Connection
+	pending []byte // 上次读取未拷贝完的数据
 }
 
 func NewUDPConnection(conn *fillp.Connection) *UDPConnection {
@@ -45,11 +61,15 @@ func NewUDPConnection(conn *fillp.Connection) *UDPConnection {
 }
 
 func (u *UDPConnection) Read(b []byte) (n int, err error) {
-	data, err := u.conn.Receive()
-	if err != nil {
-		return 0, err
+	if len(u.pending) == 0 {
+		data, err := u.conn.Receive()
+		if err != nil {
+			return 0, err
+		}
+		u.pending = data
 	}
-	n = copy(b, data)
+	n = copy(b, u.pending)
+	u.pending = u.pending[n:]
 	return n, nil
 }
 
@@ -0,0 +1,210 @@
+package netconn
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RejectReason 连接被拒绝的原因
+type RejectReason int
+
+const (
+	RejectNone     RejectReason = iota // 未拒绝
+	RejectDenied                       // 命中 Deny 列表或不在 Allow 列表中
+	RejectHook                         // OnAccept 返回 false
+	RejectMaxConns                     // 超过最大连接总数
+	RejectPerIP                        // 超过单IP最大连接数
+	RejectRate                         // 超过接受速率
+)
+
+// AdmissionOption 服务端准入控制，按 Deny/Allow、OnAccept、MaxConns、MaxConnsPerIP、AcceptRate 的顺序检查
+// 被拒绝的连接在握手和回调前直接关闭，计入 GetMetrics 的拒绝统计
+type AdmissionOption struct {
+	MaxConns      int                        // 最大连接总数（0表示不限）
+	MaxConnsPerIP int                        // 单个远程IP的最大连接数（0表示不限）
+	AcceptRate    float64                    // 每秒接受的新连接数，令牌桶限速（0表示不限）
+	AcceptBurst   int                        // 令牌桶容量（0=AcceptRate 向上取整，至少为1）
+	Allow         []string                   // 允许的 CIDR 或IP，非空时仅允许匹配的地址
+	Deny          []string                   // 拒绝的 CIDR 或IP，优先于 Allow
+	OnAccept      func(remote net.Addr) bool // 自定义准入策略，返回 false 拒绝连接
+}
+
+// admission 准入控制状态
+type admission struct {
+	opt    AdmissionOption
+	allow  []*net.IPNet
+	deny   []*net.IPNet
+	bucket *tokenBucket
+
+	mu    sync.Mutex
+	total int
+	perIP map[string]int
+}
+
+// newAdmission 解析准入配置，opt 为nil时返回nil（不限制）
+func newAdmission(opt *AdmissionOption) (*admission, error) {
+	if opt == nil {
+		return nil, nil
+	}
+	a := &admission{
+		opt:   *opt,
+		perIP: make(map[string]int),
+	}
+
+	var err error
+	if a.allow, err = parseCIDRs(opt.Allow); err != nil {
+		return nil, err
+	}
+	if a.deny, err = parseCIDRs(opt.Deny); err != nil {
+		return nil, err
+	}
+	if opt.AcceptRate > 0 {
+		burst := opt.AcceptBurst
+		if burst <= 0 {
+			burst = max(1, int(opt.AcceptRate+0.999))
+		}
+		a.bucket = newTokenBucket(opt.AcceptRate, burst)
+	}
+	return a, nil
+}
+
+// admit 检查是否接受连接，接受时占用连接计数，需在连接结束后调用 release
+func (a *admission) admit(remote net.Addr) RejectReason {
+	ip := addrIP(remote)
+	if ip != nil {
+		if matchCIDRs(a.deny, ip) || (len(a.allow) > 0 && !matchCIDRs(a.allow, ip)) {
+			return RejectDenied
+		}
+	}
+	if a.opt.OnAccept != nil && !a.opt.OnAccept(remote) {
+		return RejectHook
+	}
+
+	key := ipKey(remote)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.opt.MaxConns > 0 && a.total >= a.opt.MaxConns {
+		return RejectMaxConns
+	}
+	if a.opt.MaxConnsPerIP > 0 && a.perIP[key] >= a.opt.MaxConnsPerIP {
+		return RejectPerIP
+	}
+	if a.bucket != nil && !a.bucket.take(time.Now()) {
+		return RejectRate
+	}
+	a.total++
+	a.perIP[key]++
+	return RejectNone
+}
+
+// release 释放连接计数
+func (a *admission) release(remote net.Addr) {
+	key := ipKey(remote)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total--
+	if a.perIP[key] <= 1 {
+		delete(a.perIP, key)
+	} else {
+		a.perIP[key]--
+	}
+}
+
+// tokenBucket 令牌桶，调用方负责加锁
+type tokenBucket struct {
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 创建令牌桶，初始为满
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// take 取一个令牌，令牌不足时返回 false
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// parseCIDRs 解析 CIDR 列表，单个IP视为 /32 或 /128
+func parseCIDRs(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid CIDR or IP: %q", s)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR or IP: %q", s)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// matchCIDRs 判断IP是否属于任一网段
+func matchCIDRs(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// addrIP 提取地址中的IP，无法解析时返回nil
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	case nil:
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// ipKey 单IP计数使用的键
+func ipKey(addr net.Addr) string {
+	if ip := addrIP(addr); ip != nil {
+		return ip.String()
+	}
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
@@ -0,0 +1,144 @@
+package netconn
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func tcpAddr(ip string, port int) net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP(ip), Port: port}
+}
+
+func TestAdmissionRules(t *testing.T) {
+	a, err := newAdmission(&AdmissionOption{
+		MaxConns:      3,
+		MaxConnsPerIP: 2,
+		Allow:         []string{"10.0.0.0/8", "192.168.1.1"},
+		Deny:          []string{"10.0.0.66"},
+		OnAccept: func(remote net.Addr) bool {
+			return remote.(*net.TCPAddr).Port != 666
+		},
+	})
+	if err != nil {
+		t.Fatalf("newAdmission error = %v", err)
+	}
+
+	tests := []struct {
+		remote net.Addr
+		want   RejectReason
+	}{
+		{tcpAddr("172.16.0.1", 1), RejectDenied}, // 不在白名单
+		{tcpAddr("10.0.0.66", 1), RejectDenied},  // 黑名单优先
+		{tcpAddr("10.0.0.1", 666), RejectHook},   // 自定义策略
+		{tcpAddr("10.0.0.1", 1), RejectNone},
+		{tcpAddr("10.0.0.1", 2), RejectNone},
+		{tcpAddr("10.0.0.1", 3), RejectPerIP}, // 单IP上限
+		{tcpAddr("192.168.1.1", 1), RejectNone},
+		{tcpAddr("10.0.0.2", 1), RejectMaxConns}, // 总数上限
+	}
+	for _, tt := range tests {
+		if got := a.admit(tt.remote); got != tt.want {
+			t.Errorf("admit(%v) = %v, want %v", tt.remote, got, tt.want)
+		}
+	}
+
+	// 释放后重新获得名额
+	a.release(tcpAddr("10.0.0.1", 1))
+	if got := a.admit(tcpAddr("10.0.0.2", 1)); got != RejectNone {
+		t.Errorf("admit after release = %v, want RejectNone", got)
+	}
+
+	if _, err := newAdmission(&AdmissionOption{Deny: []string{"not-an-ip"}}); err == nil {
+		t.Error("invalid CIDR should fail")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(10, 2)
+	now := b.last
+
+	if !b.take(now) || !b.take(now) {
+		t.Fatal("burst tokens should be available")
+	}
+	if b.take(now) {
+		t.Error("bucket should be empty")
+	}
+	// 100ms 补充1个令牌
+	if !b.take(now.Add(100 * time.Millisecond)) {
+		t.Error("token should be refilled after 100ms")
+	}
+	if b.take(now.Add(100 * time.Millisecond)) {
+		t.Error("only one token should be refilled")
+	}
+	// 补充不超过容量
+	now = now.Add(10 * time.Second)
+	taken := 0
+	for b.take(now) {
+		taken++
+	}
+	if taken != 2 {
+		t.Errorf("took %d tokens after long idle, want 2", taken)
+	}
+}
+
+func TestServerAdmission(t *testing.T) {
+	server := NewBaseServer(nil)
+	err := server.StartBaseListener(&ServerOption{
+		Protocol:  ProtocolTCP,
+		Addr:      "127.0.0.1",
+		Port:      18104,
+		Admission: &AdmissionOption{MaxConns: 2},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.StopBaseListener()
+	time.Sleep(100 * time.Millisecond)
+
+	var clients []*BaseClient
+	for i := 0; i < 2; i++ {
+		client := NewBaseClient(nil, nil)
+		if _, err := client.ConnectSimple(ProtocolTCP, "127.0.0.1", 18104); err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer client.Close()
+		clients = append(clients, client)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// 第3个连接被接受后立即关闭
+	rejected := make(chan error, 1)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnClosed: func(fd int, connType ConnectionType, reason error) {
+			rejected <- reason
+		},
+	})
+	if _, err := client.ConnectSimple(ProtocolTCP, "127.0.0.1", 18104); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	select {
+	case <-rejected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection over MaxConns should be closed")
+	}
+
+	metrics := server.GetMetrics()
+	if metrics.ActiveConns != 2 || metrics.TotalAccepted != 2 || metrics.RejectedMaxConns != 1 || metrics.TotalRejected != 1 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+
+	// 断开一个连接后允许新连接
+	clients[0].Close()
+	time.Sleep(100 * time.Millisecond)
+	client = NewBaseClient(nil, nil)
+	if _, err := client.ConnectSimple(ProtocolTCP, "127.0.0.1", 18104); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	time.Sleep(100 * time.Millisecond)
+	if metrics := server.GetMetrics(); metrics.ActiveConns != 2 || metrics.TotalAccepted != 3 {
+		t.Errorf("metrics after reconnect = %+v", metrics)
+	}
+}
@@ -1,23 +1,37 @@
 package netconn
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/junbin-yang/go-kitbox/pkg/fillp"
 )
 
 // BaseClient 统一的客户端
 type BaseClient struct {
-	connMgr  *ConnectionManager
-	callback *BaseListenerCallback
-	fd       int
-	protocol ProtocolType
-	running  bool
-	mu       sync.RWMutex
-	stopChan chan struct{}
+	connMgr      *ConnectionManager
+	callback     *BaseListenerCallback
+	fd           int
+	protocol     ProtocolType
+	framer       Framer
+	maxFrameSize int
+	running      bool
+	mu           sync.RWMutex
+	stopChan     chan struct{}
+
+	// 断线重连
+	opt          *ClientOption
+	reconnect    *ReconnectPolicy
+	reconnecting bool
+	pending      [][]byte // 断线期间缓存的待发送数据
+	pendingMu    sync.Mutex
+	flushMu      sync.RWMutex // 重连后发送缓存数据期间持有写锁，新的发送等待其完成
 }
 
 // NewBaseClient 创建客户端实例
@@ -37,27 +51,29 @@ func (c *BaseClient) Connect(opt *ClientOption) (int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.running {
+	if c.running || c.reconnecting {
 		return -1, errors.New("already connected")
 	}
 
 	c.protocol = opt.Protocol
+	c.framer = opt.Framer
+	c.maxFrameSize = opt.MaxFrameSize
 	c.stopChan = make(chan struct{})
+	c.opt = opt
+	c.reconnect = opt.Reconnect.withDefaults()
 
-	var conn NetConnection
-	var err error
-
-	if opt.Protocol == ProtocolTCP {
-		conn, err = c.connectTCP(opt)
-	} else {
-		conn, err = c.connectUDP(opt)
-	}
-
+	conn, err := c.dial(opt)
 	if err != nil {
 		return -1, err
 	}
 
 	c.fd = c.connMgr.RegisterConn(conn, ConnectionTypeClient)
+	if c.framer != nil {
+		_ = c.connMgr.SetFramer(c.fd, c.framer)
+	}
+	if opt.WriteQueue != nil {
+		_ = c.connMgr.EnableWriteQueue(c.fd, opt.WriteQueue, c.callback)
+	}
 	c.running = true
 
 	// 触发连接回调
@@ -67,17 +83,45 @@ func (c *BaseClient) Connect(opt *ClientOption) (int, error) {
 	}
 
 	// 启动接收循环
-	go c.receiveLoop()
+	go c.receiveLoop(conn, c.fd, c.stopChan)
 
 	return c.fd, nil
 }
 
-// connectTCP 连接TCP服务器
-func (c *BaseClient) connectTCP(opt *ClientOption) (NetConnection, error) {
-	timeout := opt.Timeout
-	if timeout == 0 {
-		timeout = DefaultConnectTimeout
+// dial 按协议建立连接
+func (c *BaseClient) dial(opt *ClientOption) (NetConnection, error) {
+	if opt.TLSConfig != nil && !opt.Protocol.supportsTLS() {
+		return nil, errTLSRequiresTCP
+	}
+
+	switch opt.Protocol {
+	case ProtocolTCP:
+		conn, err := c.dialTCP(opt)
+		if err != nil {
+			return nil, err
+		}
+		return NewTCPConnection(conn), nil
+	case ProtocolUDP:
+		return c.connectUDP(opt)
+	case ProtocolUnix, ProtocolUnixPacket:
+		return c.connectUnix(opt)
+	case ProtocolWebSocket:
+		return c.connectWebSocket(opt)
+	}
+	return nil, errors.New("unsupported protocol")
+}
+
+// connectTimeout 返回连接超时
+func connectTimeout(opt *ClientOption) time.Duration {
+	if opt.Timeout == 0 {
+		return DefaultConnectTimeout
 	}
+	return opt.Timeout
+}
+
+// dialTCP 建立TCP连接，按配置启用保活和TLS
+func (c *BaseClient) dialTCP(opt *ClientOption) (net.Conn, error) {
+	timeout := connectTimeout(opt)
 
 	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", opt.RemoteIP, opt.RemotePort), timeout)
 	if err != nil {
@@ -93,7 +137,52 @@ func (c *BaseClient) connectTCP(opt *ClientOption) (NetConnection, error) {
 		}
 	}
 
-	return NewTCPConnection(conn), nil
+	if opt.TLSConfig != nil {
+		tlsConfig := opt.TLSConfig
+		if tlsConfig.ServerName == "" && !tlsConfig.InsecureSkipVerify {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = opt.RemoteIP
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	return conn, nil
+}
+
+// connectWebSocket 建立TCP（或TLS）连接后完成WebSocket握手
+func (c *BaseClient) connectWebSocket(opt *ClientOption) (NetConnection, error) {
+	conn, err := c.dialTCP(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	path := opt.WebSocketPath
+	if path == "" {
+		path = DefaultWebSocketPath
+	}
+	host := net.JoinHostPort(opt.RemoteIP, strconv.Itoa(opt.RemotePort))
+	wsConn, err := dialWebSocket(conn, host, path, connectTimeout(opt))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return wsConn, nil
+}
+
+// connectUnix 连接Unix域套接字，RemoteIP 为套接字路径
+func (c *BaseClient) connectUnix(opt *ClientOption) (NetConnection, error) {
+	conn, err := net.DialTimeout(unixNetwork(opt.Protocol), opt.RemoteIP, connectTimeout(opt))
+	if err != nil {
+		return nil, err
+	}
+	return NewUnixConnection(conn.(*net.UnixConn), opt.Protocol == ProtocolUnixPacket), nil
 }
 
 // connectUDP 连接UDP/FILLP服务器
@@ -128,61 +217,104 @@ func (c *BaseClient) ConnectSimple(protocol ProtocolType, remoteIP string, remot
 }
 
 // receiveLoop 接收数据循环
-func (c *BaseClient) receiveLoop() {
+func (c *BaseClient) receiveLoop(conn NetConnection, fd int, stopChan chan struct{}) {
+	var reason error
 	defer func() {
 		c.mu.Lock()
-		c.running = false
+		// 连接已被 Close 或重新 Connect 时不再处理
+		current := c.stopChan == stopChan
+		if current {
+			c.running = false
+			c.connMgr.UnregisterConn(fd)
+		}
+		stopped := isClosed(stopChan)
+		shouldReconnect := current && !stopped && c.reconnect != nil
+		if shouldReconnect {
+			c.reconnecting = true
+		}
 		c.mu.Unlock()
 
 		if c.callback != nil && c.callback.OnDisconnected != nil {
-			c.callback.OnDisconnected(c.fd, ConnectionTypeClient)
+			c.callback.OnDisconnected(fd, ConnectionTypeClient)
 		}
-	}()
-
-	conn, ok := c.connMgr.GetConn(c.fd)
-	if !ok {
-		return
-	}
-
-	buf := make([]byte, DefaultBufSize)
-	offset := 0
-
-	for {
-		select {
-		case <-c.stopChan:
-			return
-		default:
+		if c.callback != nil && c.callback.OnClosed != nil {
+			c.callback.OnClosed(fd, ConnectionTypeClient, reason)
 		}
 
-		n, err := conn.Read(buf[offset:])
-		if err != nil {
-			return
+		if shouldReconnect {
+			go c.reconnectLoop(fd, stopChan)
 		}
+	}()
 
-		offset += n
-		if c.callback != nil && c.callback.OnDataReceived != nil {
-			processed := c.callback.OnDataReceived(c.fd, ConnectionTypeClient, buf, offset)
-			if processed < 0 {
-				return
-			}
-			if processed > 0 {
-				copy(buf, buf[processed:offset])
-				offset -= processed
-			}
-		}
+	c.mu.RLock()
+	hb := newHeartbeat(c.connMgr, fd, c.opt.Heartbeat)
+	c.mu.RUnlock()
+	reader := &connReader{
+		conn:         conn,
+		fd:           fd,
+		connType:     ConnectionTypeClient,
+		callback:     c.callback,
+		framer:       c.framer,
+		maxFrameSize: c.maxFrameSize,
+		state:        c.connMgr.getState(fd),
+		heartbeat:    hb,
+	}
+
+	done := make(chan struct{})
+	if hb != nil {
+		go hb.run(done)
 	}
+	err := reader.run(stopChan)
+	close(done)
+	reason = c.connMgr.closeReason(fd, err)
 }
 
 // SendBytes 发送数据
 func (c *BaseClient) SendBytes(data []byte) error {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	if !c.running {
+		defer c.mu.RUnlock()
+		if c.reconnecting {
+			return c.enqueue(data)
+		}
+		return errors.New("not connected")
+	}
+	fd := c.fd
+	c.mu.RUnlock()
+	c.waitFlush()
 
+	// 写队列的阻塞策略可能等待，发送时不持有锁以免阻塞 Close
+	return c.connMgr.SendBytes(fd, data)
+}
+
+// SendMessage 使用帧编解码器编码后发送消息
+func (c *BaseClient) SendMessage(msg []byte) error {
+	c.mu.RLock()
 	if !c.running {
+		defer c.mu.RUnlock()
+		if c.reconnecting {
+			if c.framer == nil {
+				return errors.New("framer not set")
+			}
+			frame, err := c.framer.Encode(msg)
+			if err != nil {
+				return err
+			}
+			return c.enqueue(frame)
+		}
 		return errors.New("not connected")
 	}
+	fd := c.fd
+	c.mu.RUnlock()
+	c.waitFlush()
+
+	return c.connMgr.SendMessage(fd, msg)
+}
 
-	return c.connMgr.SendBytes(c.fd, data)
+// waitFlush 等待重连后的缓存数据发送完成，使新数据排在缓存数据之后
+func (c *BaseClient) waitFlush() {
+	c.flushMu.RLock()
+	c.flushMu.RUnlock()
 }
 
 // Close 关闭连接
@@ -190,6 +322,14 @@ func (c *BaseClient) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.reconnecting {
+		// 停止重连并丢弃缓存数据
+		close(c.stopChan)
+		c.reconnecting = false
+		c.clearPending()
+		return
+	}
+
 	if !c.running {
 		return
 	}
@@ -206,6 +346,13 @@ func (c *BaseClient) GetFd() int {
 	return c.fd
 }
 
+// IsReconnecting 检查是否正在断线重连
+func (c *BaseClient) IsReconnecting() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reconnecting
+}
+
 // IsConnected 检查是否已连接
 func (c *BaseClient) IsConnected() bool {
 	c.mu.RLock()
@@ -224,3 +371,15 @@ func (c *BaseClient) GetConnInfo() *ConnectOption {
 
 	return c.connMgr.GetConnInfo(c.fd)
 }
+
+// GetConnMetrics 获取当前连接的指标快照，未连接时返回nil；断线重连后重新计数
+func (c *BaseClient) GetConnMetrics() *ConnMetrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.running {
+		return nil
+	}
+
+	return c.connMgr.GetConnMetrics(c.fd)
+}
@@ -0,0 +1,298 @@
+package netconn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/junbin-yang/go-kitbox/pkg/binpack"
+)
+
+var (
+	// ErrFrameTooLarge 帧长度超过最大帧限制
+	ErrFrameTooLarge = errors.New("frame too large")
+	// ErrInvalidFrame 帧格式非法
+	ErrInvalidFrame = errors.New("invalid frame")
+)
+
+// Framer 帧编解码器：从字节流中切分完整消息，并为发送的消息添加帧格式
+type Framer interface {
+	// Decode 从 buf 头部解析一帧，返回消息和消耗的字节数
+	// 数据不足一帧时返回 (nil, 0, nil)；返回的消息可引用 buf，仅在回调期间有效
+	Decode(buf []byte) (msg []byte, n int, err error)
+	// Encode 将消息编码为一帧
+	Encode(msg []byte) ([]byte, error)
+}
+
+// LengthFieldFramer 长度前缀帧：长度字段 + 消息体（长度不含长度字段本身）
+type LengthFieldFramer struct {
+	fieldSize    int
+	order        binary.ByteOrder
+	maxFrameSize int
+}
+
+// NewLengthFieldFramer 创建长度前缀帧编解码器
+// fieldSize 为长度字段字节数（1/2/4），order 为字节序，maxFrameSize 为消息体上限（0表示仅受字段宽度限制）
+func NewLengthFieldFramer(fieldSize int, order binary.ByteOrder, maxFrameSize int) (*LengthFieldFramer, error) {
+	if fieldSize != 1 && fieldSize != 2 && fieldSize != 4 {
+		return nil, fmt.Errorf("invalid length field size: %d", fieldSize)
+	}
+	if order == nil {
+		order = binary.BigEndian
+	}
+	return &LengthFieldFramer{fieldSize: fieldSize, order: order, maxFrameSize: maxFrameSize}, nil
+}
+
+func (f *LengthFieldFramer) Decode(buf []byte) ([]byte, int, error) {
+	if len(buf) < f.fieldSize {
+		return nil, 0, nil
+	}
+	length := f.readLength(buf)
+	if f.maxFrameSize > 0 && length > f.maxFrameSize {
+		return nil, 0, ErrFrameTooLarge
+	}
+	total := f.fieldSize + length
+	if len(buf) < total {
+		return nil, 0, nil
+	}
+	return buf[f.fieldSize:total], total, nil
+}
+
+func (f *LengthFieldFramer) Encode(msg []byte) ([]byte, error) {
+	if len(msg) > f.maxLength() || (f.maxFrameSize > 0 && len(msg) > f.maxFrameSize) {
+		return nil, ErrFrameTooLarge
+	}
+	frame := make([]byte, f.fieldSize+len(msg))
+	switch f.fieldSize {
+	case 1:
+		frame[0] = byte(len(msg))
+	case 2:
+		f.order.PutUint16(frame, uint16(len(msg)))
+	case 4:
+		f.order.PutUint32(frame, uint32(len(msg)))
+	}
+	copy(frame[f.fieldSize:], msg)
+	return frame, nil
+}
+
+func (f *LengthFieldFramer) readLength(buf []byte) int {
+	switch f.fieldSize {
+	case 1:
+		return int(buf[0])
+	case 2:
+		return int(f.order.Uint16(buf))
+	default:
+		return int(f.order.Uint32(buf))
+	}
+}
+
+// maxLength 长度字段可表示的最大值
+func (f *LengthFieldFramer) maxLength() int {
+	if f.fieldSize == 4 {
+		return int(^uint32(0) >> 1)
+	}
+	return 1<<(8*f.fieldSize) - 1
+}
+
+// DelimiterFramer 分隔符帧：消息 + 分隔符（如 "\n"、"\r\n"）
+type DelimiterFramer struct {
+	delimiter    []byte
+	maxFrameSize int
+}
+
+// NewDelimiterFramer 创建分隔符帧编解码器，maxFrameSize 为消息上限（0表示不限）
+func NewDelimiterFramer(delimiter []byte, maxFrameSize int) (*DelimiterFramer, error) {
+	if len(delimiter) == 0 {
+		return nil, errors.New("delimiter must not be empty")
+	}
+	return &DelimiterFramer{delimiter: append([]byte(nil), delimiter...), maxFrameSize: maxFrameSize}, nil
+}
+
+func (f *DelimiterFramer) Decode(buf []byte) ([]byte, int, error) {
+	idx := bytes.Index(buf, f.delimiter)
+	if idx < 0 {
+		if f.maxFrameSize > 0 && len(buf) > f.maxFrameSize+len(f.delimiter) {
+			return nil, 0, ErrFrameTooLarge
+		}
+		return nil, 0, nil
+	}
+	if f.maxFrameSize > 0 && idx > f.maxFrameSize {
+		return nil, 0, ErrFrameTooLarge
+	}
+	return buf[:idx], idx + len(f.delimiter), nil
+}
+
+func (f *DelimiterFramer) Encode(msg []byte) ([]byte, error) {
+	if f.maxFrameSize > 0 && len(msg) > f.maxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+	if bytes.Contains(msg, f.delimiter) {
+		return nil, ErrInvalidFrame
+	}
+	frame := make([]byte, 0, len(msg)+len(f.delimiter))
+	frame = append(frame, msg...)
+	return append(frame, f.delimiter...), nil
+}
+
+// FixedSizeFramer 定长帧：每帧固定字节数
+type FixedSizeFramer struct {
+	size int
+}
+
+// NewFixedSizeFramer 创建定长帧编解码器
+func NewFixedSizeFramer(size int) (*FixedSizeFramer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid frame size: %d", size)
+	}
+	return &FixedSizeFramer{size: size}, nil
+}
+
+func (f *FixedSizeFramer) Decode(buf []byte) ([]byte, int, error) {
+	if len(buf) < f.size {
+		return nil, 0, nil
+	}
+	return buf[:f.size], f.size, nil
+}
+
+func (f *FixedSizeFramer) Encode(msg []byte) ([]byte, error) {
+	if len(msg) != f.size {
+		return nil, ErrInvalidFrame
+	}
+	return msg, nil
+}
+
+// BinpackHeaderFramer 以 binpack 结构体为帧头的帧：定长帧头 + 消息体
+// 帧头中的长度字段表示消息体长度；Decode 返回完整帧（帧头 + 消息体），便于业务方解码帧头
+type BinpackHeaderFramer struct {
+	typ          reflect.Type
+	codec        binpack.Codec
+	headerSize   int
+	lengthIndex  int
+	maxFrameSize int
+}
+
+// NewBinpackHeaderFramer 创建 binpack 帧头编解码器
+// header 为帧头结构体（值或指针，字段使用 bin 标签且均为定长），lengthField 为表示消息体长度的整数字段名，
+// maxFrameSize 为消息体上限（0表示不限）
+func NewBinpackHeaderFramer(header interface{}, lengthField string, maxFrameSize int) (*BinpackHeaderFramer, error) {
+	typ := reflect.TypeOf(header)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("header must be a struct")
+	}
+
+	field, ok := typ.FieldByName(lengthField)
+	if !ok {
+		return nil, fmt.Errorf("length field %s not found", lengthField)
+	}
+	switch field.Type.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int, reflect.Uint:
+	default:
+		return nil, fmt.Errorf("length field %s must be an integer", lengthField)
+	}
+
+	codec, err := binpack.CompileCodec(typ)
+	if err != nil {
+		return nil, err
+	}
+	// 以零值编码结果确定帧头长度
+	zero, err := codec.Encode(reflect.New(typ).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return &BinpackHeaderFramer{
+		typ:          typ,
+		codec:        codec,
+		headerSize:   len(zero),
+		lengthIndex:  field.Index[0],
+		maxFrameSize: maxFrameSize,
+	}, nil
+}
+
+// HeaderSize 返回帧头长度
+func (f *BinpackHeaderFramer) HeaderSize() int {
+	return f.headerSize
+}
+
+func (f *BinpackHeaderFramer) Decode(buf []byte) ([]byte, int, error) {
+	if len(buf) < f.headerSize {
+		return nil, 0, nil
+	}
+	length, err := f.bodyLength(buf[:f.headerSize])
+	if err != nil {
+		return nil, 0, err
+	}
+	total := f.headerSize + length
+	if len(buf) < total {
+		return nil, 0, nil
+	}
+	return buf[:total], total, nil
+}
+
+// Encode 校验完整帧（帧头 + 消息体）的长度字段后原样返回；构造帧请使用 Frame
+func (f *BinpackHeaderFramer) Encode(msg []byte) ([]byte, error) {
+	if len(msg) < f.headerSize {
+		return nil, ErrInvalidFrame
+	}
+	length, err := f.bodyLength(msg[:f.headerSize])
+	if err != nil {
+		return nil, err
+	}
+	if f.headerSize+length != len(msg) {
+		return nil, ErrInvalidFrame
+	}
+	return msg, nil
+}
+
+// Frame 设置帧头的长度字段并拼接消息体，header 需为帧头结构体指针
+func (f *BinpackHeaderFramer) Frame(header interface{}, body []byte) ([]byte, error) {
+	val := reflect.ValueOf(header)
+	if val.Kind() != reflect.Ptr || val.Elem().Type() != f.typ {
+		return nil, fmt.Errorf("header must be *%s", f.typ)
+	}
+	if f.maxFrameSize > 0 && len(body) > f.maxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+
+	lengthField := val.Elem().Field(f.lengthIndex)
+	if lengthField.CanUint() {
+		lengthField.SetUint(uint64(len(body)))
+	} else {
+		lengthField.SetInt(int64(len(body)))
+	}
+
+	frame := make([]byte, f.headerSize+len(body))
+	if _, err := f.codec.EncodeTo(frame, header); err != nil {
+		return nil, err
+	}
+	copy(frame[f.headerSize:], body)
+	return frame, nil
+}
+
+// bodyLength 解码帧头并读取消息体长度
+func (f *BinpackHeaderFramer) bodyLength(headerBytes []byte) (int, error) {
+	header := reflect.New(f.typ)
+	if err := f.codec.Decode(headerBytes, header.Interface()); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+	}
+
+	lengthField := header.Elem().Field(f.lengthIndex)
+	var length int64
+	if lengthField.CanUint() {
+		length = int64(lengthField.Uint())
+	} else {
+		length = lengthField.Int()
+	}
+	if length < 0 {
+		return 0, ErrInvalidFrame
+	}
+	if f.maxFrameSize > 0 && length > int64(f.maxFrameSize) {
+		return 0, ErrFrameTooLarge
+	}
+	return int(length), nil
+}
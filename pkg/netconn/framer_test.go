@@ -0,0 +1,242 @@
+package netconn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLengthFieldFramer(t *testing.T) {
+	for _, size := range []int{1, 2, 4} {
+		for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+			f, err := NewLengthFieldFramer(size, order, 0)
+			if err != nil {
+				t.Fatalf("NewLengthFieldFramer(%d) error = %v", size, err)
+			}
+
+			frame, err := f.Encode([]byte("hello"))
+			if err != nil {
+				t.Fatalf("Encode error = %v", err)
+			}
+			if len(frame) != size+5 {
+				t.Errorf("frame length = %d, want %d", len(frame), size+5)
+			}
+
+			// 不完整的帧
+			if msg, n, err := f.Decode(frame[:len(frame)-1]); msg != nil || n != 0 || err != nil {
+				t.Errorf("Decode(partial) = %q, %d, %v", msg, n, err)
+			}
+
+			// 两帧粘包
+			buf := append(append([]byte{}, frame...), frame...)
+			msg, n, err := f.Decode(buf)
+			if err != nil || n != len(frame) || string(msg) != "hello" {
+				t.Errorf("Decode = %q, %d, %v", msg, n, err)
+			}
+		}
+	}
+
+	if _, err := NewLengthFieldFramer(3, binary.BigEndian, 0); err == nil {
+		t.Error("Expected error for invalid field size")
+	}
+
+	f, _ := NewLengthFieldFramer(1, nil, 0)
+	if _, err := f.Encode(make([]byte, 256)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Encode(256 bytes) error = %v, want ErrFrameTooLarge", err)
+	}
+
+	f, _ = NewLengthFieldFramer(4, binary.BigEndian, 10)
+	if _, _, err := f.Decode([]byte{0, 0, 0, 11}); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Decode error = %v, want ErrFrameTooLarge", err)
+	}
+}
+
+func TestDelimiterFramer(t *testing.T) {
+	f, err := NewDelimiterFramer([]byte("\r\n"), 8)
+	if err != nil {
+		t.Fatalf("NewDelimiterFramer error = %v", err)
+	}
+
+	msg, n, err := f.Decode([]byte("ping\r\npong"))
+	if err != nil || n != 6 || string(msg) != "ping" {
+		t.Errorf("Decode = %q, %d, %v", msg, n, err)
+	}
+	if msg, n, err := f.Decode([]byte("pong")); msg != nil || n != 0 || err != nil {
+		t.Errorf("Decode(partial) = %q, %d, %v", msg, n, err)
+	}
+	if _, _, err := f.Decode([]byte("0123456789ab")); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Decode(long) error = %v, want ErrFrameTooLarge", err)
+	}
+
+	frame, err := f.Encode([]byte("ping"))
+	if err != nil || string(frame) != "ping\r\n" {
+		t.Errorf("Encode = %q, %v", frame, err)
+	}
+	if _, err := f.Encode([]byte("a\r\nb")); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("Encode with delimiter error = %v, want ErrInvalidFrame", err)
+	}
+
+	if _, err := NewDelimiterFramer(nil, 0); err == nil {
+		t.Error("Expected error for empty delimiter")
+	}
+}
+
+func TestFixedSizeFramer(t *testing.T) {
+	f, err := NewFixedSizeFramer(4)
+	if err != nil {
+		t.Fatalf("NewFixedSizeFramer error = %v", err)
+	}
+
+	msg, n, err := f.Decode([]byte("abcdef"))
+	if err != nil || n != 4 || string(msg) != "abcd" {
+		t.Errorf("Decode = %q, %d, %v", msg, n, err)
+	}
+	if _, err := f.Encode([]byte("abc")); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("Encode(short) error = %v, want ErrInvalidFrame", err)
+	}
+	if _, err := NewFixedSizeFramer(0); err == nil {
+		t.Error("Expected error for zero size")
+	}
+}
+
+type testFrameHeader struct {
+	Magic  uint16 `bin:"0:2:be"`
+	Type   uint8  `bin:"2:1"`
+	Length uint32 `bin:"3:4:be"`
+}
+
+func TestBinpackHeaderFramer(t *testing.T) {
+	f, err := NewBinpackHeaderFramer(testFrameHeader{}, "Length", 1024)
+	if err != nil {
+		t.Fatalf("NewBinpackHeaderFramer error = %v", err)
+	}
+	if f.HeaderSize() != 7 {
+		t.Errorf("HeaderSize = %d, want 7", f.HeaderSize())
+	}
+
+	frame, err := f.Frame(&testFrameHeader{Magic: 0xCAFE, Type: 1}, []byte("body"))
+	if err != nil {
+		t.Fatalf("Frame error = %v", err)
+	}
+	if len(frame) != 11 {
+		t.Fatalf("frame length = %d, want 11", len(frame))
+	}
+	if out, err := f.Encode(frame); err != nil || !bytes.Equal(out, frame) {
+		t.Errorf("Encode(valid frame) = %v, %v", out, err)
+	}
+	if _, err := f.Encode(frame[:10]); !errors.Is(err, ErrInvalidFrame) {
+		t.Errorf("Encode(truncated) error = %v, want ErrInvalidFrame", err)
+	}
+
+	msg, n, err := f.Decode(append(frame, 0xCA))
+	if err != nil || n != 11 || !bytes.Equal(msg, frame) {
+		t.Errorf("Decode = %v, %d, %v", msg, n, err)
+	}
+	if msg, n, err := f.Decode(frame[:8]); msg != nil || n != 0 || err != nil {
+		t.Errorf("Decode(partial) = %v, %d, %v", msg, n, err)
+	}
+
+	if _, err := NewBinpackHeaderFramer(testFrameHeader{}, "Missing", 0); err == nil {
+		t.Error("Expected error for unknown length field")
+	}
+	if _, err := NewBinpackHeaderFramer(42, "Length", 0); err == nil {
+		t.Error("Expected error for non-struct header")
+	}
+}
+
+func TestFramedLargeMessage(t *testing.T) {
+	framer, _ := NewLengthFieldFramer(4, binary.BigEndian, 0)
+
+	server := NewBaseServer(nil)
+	received := make(chan []byte, 4)
+	serverCallback := &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			received <- append([]byte(nil), msg...)
+			_ = server.SendMessage(fd, msg) // 回显
+		},
+	}
+	opt := &ServerOption{Protocol: ProtocolTCP, Addr: "127.0.0.1", Port: 18084, Framer: framer}
+	if err := server.StartBaseListener(opt, serverCallback); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() { _ = server.StopBaseListener() }()
+	time.Sleep(100 * time.Millisecond)
+
+	echoed := make(chan []byte, 4)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			echoed <- append([]byte(nil), msg...)
+		},
+	})
+	if _, err := client.Connect(&ClientOption{Protocol: ProtocolTCP, RemoteIP: "127.0.0.1", RemotePort: 18084, Framer: framer}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// 超过默认缓冲区大小的消息
+	large := bytes.Repeat([]byte("0123456789"), 10000)
+	if err := client.SendMessage(large); err != nil {
+		t.Fatalf("SendMessage error = %v", err)
+	}
+	if err := client.SendMessage([]byte("small")); err != nil {
+		t.Fatalf("SendMessage error = %v", err)
+	}
+
+	for _, want := range [][]byte{large, []byte("small")} {
+		for _, ch := range []chan []byte{received, echoed} {
+			select {
+			case msg := <-ch:
+				if !bytes.Equal(msg, want) {
+					t.Errorf("message length = %d, want %d", len(msg), len(want))
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("timeout waiting for message")
+			}
+		}
+	}
+}
+
+func TestFrameTooLargeDisconnects(t *testing.T) {
+	framer, _ := NewLengthFieldFramer(4, binary.BigEndian, 0)
+
+	server := NewBaseServer(nil)
+	disconnected := make(chan int, 1)
+	serverCallback := &BaseListenerCallback{
+		OnDisconnected: func(fd int, connType ConnectionType) {
+			disconnected <- fd
+		},
+		OnMessage: func(fd int, msg []byte) {},
+	}
+	opt := &ServerOption{Protocol: ProtocolTCP, Addr: "127.0.0.1", Port: 18085, Framer: framer, MaxFrameSize: 4096}
+	if err := server.StartBaseListener(opt, serverCallback); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() { _ = server.StopBaseListener() }()
+	time.Sleep(100 * time.Millisecond)
+
+	client := NewBaseClient(nil, nil)
+	if _, err := client.Connect(&ClientOption{Protocol: ProtocolTCP, RemoteIP: "127.0.0.1", RemotePort: 18085, Framer: framer}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	_ = client.SendMessage(make([]byte, 8192))
+
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("server should close connection when frame exceeds MaxFrameSize")
+	}
+}
+
+func TestSendMessageWithoutFramer(t *testing.T) {
+	mgr := NewConnectionManager()
+	if err := mgr.SendMessage(1, []byte("x")); err == nil {
+		t.Error("Expected error for unknown fd")
+	}
+	if err := mgr.SetFramer(1, nil); err == nil {
+		t.Error("Expected error for unknown fd")
+	}
+}
@@ -0,0 +1,283 @@
+package netconn
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// SetAttr 设置连接属性（如用户ID、房间号），连接注销时自动清除
+func (m *ConnectionManager) SetAttr(fd int, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.connMap[fd]; !ok {
+		return errors.New("connection not found")
+	}
+
+	attrs, ok := m.attrMap[fd]
+	if !ok {
+		attrs = make(map[string]string)
+		m.attrMap[fd] = attrs
+	}
+	if old, ok := attrs[key]; ok {
+		m.unindexAttrLocked(fd, key, old)
+	}
+	attrs[key] = value
+
+	values, ok := m.attrIndex[key]
+	if !ok {
+		values = make(map[string]map[int]struct{})
+		m.attrIndex[key] = values
+	}
+	fds, ok := values[value]
+	if !ok {
+		fds = make(map[int]struct{})
+		values[value] = fds
+	}
+	fds[fd] = struct{}{}
+	return nil
+}
+
+// GetAttr 获取连接属性
+func (m *ConnectionManager) GetAttr(fd int, key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.attrMap[fd][key]
+	return value, ok
+}
+
+// GetAttrs 获取连接的全部属性（副本）
+func (m *ConnectionManager) GetAttrs(fd int) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	attrs := make(map[string]string, len(m.attrMap[fd]))
+	for k, v := range m.attrMap[fd] {
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// DelAttr 删除连接属性
+func (m *ConnectionManager) DelAttr(fd int, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if value, ok := m.attrMap[fd][key]; ok {
+		delete(m.attrMap[fd], key)
+		m.unindexAttrLocked(fd, key, value)
+	}
+}
+
+// FindByAttr 查找属性 key 等于 value 的所有连接，按fd升序返回
+func (m *ConnectionManager) FindByAttr(key, value string) []int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedFds(m.attrIndex[key][value])
+}
+
+// JoinGroup 将连接加入分组，分组在首个成员加入时创建
+func (m *ConnectionManager) JoinGroup(fd int, group string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.connMap[fd]; !ok {
+		return errors.New("connection not found")
+	}
+
+	members, ok := m.groupMap[group]
+	if !ok {
+		members = make(map[int]struct{})
+		m.groupMap[group] = members
+	}
+	members[fd] = struct{}{}
+
+	groups, ok := m.connGroupMap[fd]
+	if !ok {
+		groups = make(map[string]struct{})
+		m.connGroupMap[fd] = groups
+	}
+	groups[group] = struct{}{}
+	return nil
+}
+
+// LeaveGroup 将连接移出分组，分组在最后一个成员离开时删除
+func (m *ConnectionManager) LeaveGroup(fd int, group string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.leaveGroupLocked(fd, group)
+}
+
+// GetGroupMembers 获取分组内的所有连接，按fd升序返回
+func (m *ConnectionManager) GetGroupMembers(group string) []int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedFds(m.groupMap[group])
+}
+
+// GetConnGroups 获取连接所在的分组
+func (m *ConnectionManager) GetConnGroups(fd int) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	groups := make([]string, 0, len(m.connGroupMap[fd]))
+	for group := range m.connGroupMap[fd] {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// GetGroups 获取所有非空分组
+func (m *ConnectionManager) GetGroups() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	groups := make([]string, 0, len(m.groupMap))
+	for group := range m.groupMap {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Broadcast 向分组内所有连接发送数据，返回成功入队或写出的连接数
+// 已启用写队列的连接经队列异步写出，队列已满时按连接的溢出策略处理，OverflowBlock 策略（默认）下本次跳过该连接；
+// 未启用写队列的连接并发同步写出，慢连接最多阻塞本次广播 DefaultBroadcastWriteTimeout，超时的连接被关闭
+func (m *ConnectionManager) Broadcast(group string, data []byte) int {
+	data = append([]byte(nil), data...)
+	return m.broadcast(group, false, func(int) ([]byte, error) {
+		return data, nil
+	})
+}
+
+// BroadcastMessage 使用各连接的帧编解码器编码后向分组广播消息，未设置编解码器的连接被跳过
+func (m *ConnectionManager) BroadcastMessage(group string, msg []byte) int {
+	return m.broadcast(group, true, func(fd int) ([]byte, error) {
+		framer, ok := m.GetFramer(fd)
+		if !ok {
+			return nil, errors.New("framer not set")
+		}
+		frame, err := framer.Encode(msg)
+		if err != nil {
+			return nil, err
+		}
+		// 部分编解码器原样返回消息，拷贝以免调用方复用缓冲区
+		return append([]byte(nil), frame...), nil
+	})
+}
+
+// broadcast 向分组成员发送数据，message 表示按消息计数
+// 已启用写队列的连接投递到队列；未启用的连接并发同步写出，每次写出最长 DefaultBroadcastWriteTimeout，超时关闭该连接
+func (m *ConnectionManager) broadcast(group string, message bool, encode func(fd int) ([]byte, error)) int {
+	var sent atomic.Int32
+	var wg sync.WaitGroup
+	for _, fd := range m.GetGroupMembers(group) {
+		data, err := encode(fd)
+		if err != nil {
+			continue
+		}
+
+		m.mu.RLock()
+		conn, ok := m.connMap[fd]
+		q := m.writeQueueMap[fd]
+		state := m.stateMap[fd]
+		m.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		if q != nil {
+			if q.send(data, false) == nil {
+				if message {
+					state.recordMessageOut()
+				}
+				sent.Add(1)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := writeWithTimeout(conn, [][]byte{data}, DefaultBroadcastWriteTimeout); err != nil {
+				state.recordError()
+				if errors.Is(err, ErrWriteTimeout) {
+					// 部分写出的数据已破坏流的边界，关闭连接
+					state.setReason(err)
+					conn.Close()
+				}
+				return
+			}
+			state.recordWrite(len(data))
+			if message {
+				state.recordMessageOut()
+			}
+			sent.Add(1)
+		}()
+	}
+	wg.Wait()
+	return int(sent.Load())
+}
+
+// removeConnStateLocked 清除连接的属性、分组和写队列，调用方需持有写锁
+func (m *ConnectionManager) removeConnStateLocked(fd int) {
+	for key, value := range m.attrMap[fd] {
+		m.unindexAttrLocked(fd, key, value)
+	}
+	delete(m.attrMap, fd)
+
+	for group := range m.connGroupMap[fd] {
+		m.leaveGroupLocked(fd, group)
+	}
+
+	if q, ok := m.writeQueueMap[fd]; ok {
+		q.stop()
+		delete(m.writeQueueMap, fd)
+	}
+}
+
+// leaveGroupLocked 将连接移出分组，调用方需持有写锁
+func (m *ConnectionManager) leaveGroupLocked(fd int, group string) {
+	if members, ok := m.groupMap[group]; ok {
+		delete(members, fd)
+		if len(members) == 0 {
+			delete(m.groupMap, group)
+		}
+	}
+	if groups, ok := m.connGroupMap[fd]; ok {
+		delete(groups, group)
+		if len(groups) == 0 {
+			delete(m.connGroupMap, fd)
+		}
+	}
+}
+
+// unindexAttrLocked 从属性索引中移除，调用方需持有写锁
+func (m *ConnectionManager) unindexAttrLocked(fd int, key, value string) {
+	values, ok := m.attrIndex[key]
+	if !ok {
+		return
+	}
+	if fds, ok := values[value]; ok {
+		delete(fds, fd)
+		if len(fds) == 0 {
+			delete(values, value)
+		}
+	}
+	if len(values) == 0 {
+		delete(m.attrIndex, key)
+	}
+}
+
+// sortedFds 将fd集合转为升序切片
+func sortedFds(set map[int]struct{}) []int {
+	fds := make([]int, 0, len(set))
+	for fd := range set {
+		fds = append(fds, fd)
+	}
+	sort.Ints(fds)
+	return fds
+}
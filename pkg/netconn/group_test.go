@@ -0,0 +1,218 @@
+package netconn
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// registerPipe 注册 net.Pipe 的一端，返回fd和对端
+func registerPipe(t *testing.T, mgr *ConnectionManager) (int, net.Conn) {
+	t.Helper()
+	local, remote := net.Pipe()
+	t.Cleanup(func() {
+		local.Close()
+		remote.Close()
+	})
+	return mgr.RegisterConn(NewTCPConnection(local), ConnectionTypeClient), remote
+}
+
+func TestConnAttributes(t *testing.T) {
+	mgr := NewConnectionManager()
+	fd1, _ := registerPipe(t, mgr)
+	fd2, _ := registerPipe(t, mgr)
+
+	if err := mgr.SetAttr(fd1, "user", "alice"); err != nil {
+		t.Fatalf("SetAttr error = %v", err)
+	}
+	_ = mgr.SetAttr(fd1, "room", "lobby")
+	_ = mgr.SetAttr(fd2, "room", "lobby")
+
+	if v, ok := mgr.GetAttr(fd1, "user"); !ok || v != "alice" {
+		t.Errorf("GetAttr = %q, %v", v, ok)
+	}
+	if got := mgr.FindByAttr("room", "lobby"); !reflect.DeepEqual(got, []int{fd1, fd2}) {
+		t.Errorf("FindByAttr(room=lobby) = %v", got)
+	}
+
+	// 修改属性值后索引同步更新
+	_ = mgr.SetAttr(fd2, "room", "game")
+	if got := mgr.FindByAttr("room", "lobby"); !reflect.DeepEqual(got, []int{fd1}) {
+		t.Errorf("FindByAttr after update = %v", got)
+	}
+
+	mgr.DelAttr(fd1, "user")
+	if _, ok := mgr.GetAttr(fd1, "user"); ok {
+		t.Error("attribute should be deleted")
+	}
+	if got := mgr.FindByAttr("user", "alice"); len(got) != 0 {
+		t.Errorf("FindByAttr after delete = %v", got)
+	}
+	if attrs := mgr.GetAttrs(fd1); !reflect.DeepEqual(attrs, map[string]string{"room": "lobby"}) {
+		t.Errorf("GetAttrs = %v", attrs)
+	}
+
+	// 注销后属性和索引被清除
+	mgr.UnregisterConn(fd1)
+	if got := mgr.FindByAttr("room", "lobby"); len(got) != 0 {
+		t.Errorf("FindByAttr after unregister = %v", got)
+	}
+	if err := mgr.SetAttr(fd1, "user", "bob"); err == nil {
+		t.Error("SetAttr on unregistered fd should fail")
+	}
+}
+
+func TestConnGroups(t *testing.T) {
+	mgr := NewConnectionManager()
+	fd1, _ := registerPipe(t, mgr)
+	fd2, _ := registerPipe(t, mgr)
+
+	_ = mgr.JoinGroup(fd1, "room-1")
+	_ = mgr.JoinGroup(fd2, "room-1")
+	_ = mgr.JoinGroup(fd1, "vip")
+
+	if got := mgr.GetGroupMembers("room-1"); !reflect.DeepEqual(got, []int{fd1, fd2}) {
+		t.Errorf("GetGroupMembers = %v", got)
+	}
+	if got := mgr.GetConnGroups(fd1); !reflect.DeepEqual(got, []string{"room-1", "vip"}) {
+		t.Errorf("GetConnGroups = %v", got)
+	}
+
+	mgr.LeaveGroup(fd1, "vip")
+	if got := mgr.GetGroups(); !reflect.DeepEqual(got, []string{"room-1"}) {
+		t.Errorf("GetGroups = %v, empty group should be removed", got)
+	}
+
+	mgr.UnregisterConn(fd2)
+	if got := mgr.GetGroupMembers("room-1"); !reflect.DeepEqual(got, []int{fd1}) {
+		t.Errorf("GetGroupMembers after unregister = %v", got)
+	}
+	if err := mgr.JoinGroup(fd2, "room-1"); err == nil {
+		t.Error("JoinGroup on unregistered fd should fail")
+	}
+}
+
+func TestBroadcastSlowConsumer(t *testing.T) {
+	mgr := NewConnectionManager()
+	fast1, peer1 := registerPipe(t, mgr)
+	fast2, peer2 := registerPipe(t, mgr)
+	slow, _ := registerPipe(t, mgr) // 对端从不读取，写操作一直阻塞
+
+	for _, fd := range []int{fast1, fast2, slow} {
+		_ = mgr.JoinGroup(fd, "all")
+		if err := mgr.EnableWriteQueue(fd, nil, nil); err != nil {
+			t.Fatalf("EnableWriteQueue error = %v", err)
+		}
+	}
+
+	const count = 10
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < count; i++ {
+			if n := mgr.Broadcast("all", []byte("msg!")); n != 3 {
+				t.Errorf("Broadcast delivered to %d connections, want 3", n)
+			}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Broadcast blocked on slow consumer")
+	}
+
+	for _, peer := range []net.Conn{peer1, peer2} {
+		buf := make([]byte, 4*count)
+		_ = peer.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := io.ReadFull(peer, buf); err != nil {
+			t.Fatalf("fast consumer read error = %v", err)
+		}
+	}
+}
+
+func TestBroadcastWithoutWriteQueue(t *testing.T) {
+	mgr := NewConnectionManager()
+	fast, peer := registerPipe(t, mgr)
+	slow, _ := registerPipe(t, mgr) // 对端从不读取
+	other, otherPeer := registerPipe(t, mgr)
+	for _, fd := range []int{fast, slow, other} {
+		_ = mgr.JoinGroup(fd, "all")
+	}
+
+	go io.Copy(io.Discard, otherPeer)
+	received := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 4)
+		_, _ = io.ReadFull(peer, buf)
+		received <- buf
+	}()
+
+	// 未启用写队列的连接同步写出，慢连接超时后被关闭，不会无限阻塞广播
+	start := time.Now()
+	if n := mgr.Broadcast("all", []byte("msg!")); n != 2 {
+		t.Errorf("Broadcast delivered to %d connections, want 2", n)
+	}
+	if elapsed := time.Since(start); elapsed > DefaultBroadcastWriteTimeout+time.Second {
+		t.Errorf("Broadcast took %v, want bounded by DefaultBroadcastWriteTimeout", elapsed)
+	}
+	if got := <-received; string(got) != "msg!" {
+		t.Errorf("received %q", got)
+	}
+	if err := mgr.SendBytes(slow, []byte("x")); err == nil {
+		t.Error("SendBytes on timed out connection should fail")
+	}
+
+	// 广播不会隐式创建写队列：SendBytes 仍同步返回写错误
+	if got := mgr.QueuedBytes(fast); got != 0 {
+		t.Errorf("QueuedBytes = %d, want 0", got)
+	}
+	peer.Close()
+	if err := mgr.SendBytes(fast, []byte("after")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("SendBytes after Broadcast error = %v, want %v", err, io.ErrClosedPipe)
+	}
+
+	// 广播之后仍可显式启用写队列
+	var high atomic.Int32
+	cb := &BaseListenerCallback{OnWriteHighWatermark: func(fd int, queued int) { high.Add(1) }}
+	if err := mgr.EnableWriteQueue(other, &WriteQueueOption{HighWatermark: 1}, cb); err != nil {
+		t.Fatalf("EnableWriteQueue after Broadcast error = %v", err)
+	}
+	if err := mgr.SendBytes(other, []byte("queued")); err != nil {
+		t.Fatalf("SendBytes error = %v", err)
+	}
+	if high.Load() != 1 {
+		t.Errorf("high watermark callback count = %d, want 1", high.Load())
+	}
+}
+
+func TestGroupsConcurrentDisconnect(t *testing.T) {
+	mgr := NewConnectionManager()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fd, _ := registerPipe(t, mgr)
+			_ = mgr.SetAttr(fd, "room", "r")
+			_ = mgr.JoinGroup(fd, "r")
+			mgr.Broadcast("r", []byte("x"))
+			mgr.UnregisterConn(fd)
+			_ = mgr.JoinGroup(fd, "r")
+		}()
+	}
+	wg.Wait()
+
+	if got := mgr.GetGroupMembers("r"); len(got) != 0 {
+		t.Errorf("members after disconnect = %v", got)
+	}
+	if got := mgr.FindByAttr("room", "r"); len(got) != 0 {
+		t.Errorf("attribute index after disconnect = %v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package netconn
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrReadIdleTimeout 超过 ReadIdleTimeout 未收到数据，连接被关闭
+	ErrReadIdleTimeout = errors.New("read idle timeout")
+	// ErrWriteIdleTimeout 超过 WriteIdleTimeout 未成功写出数据，连接被关闭
+	ErrWriteIdleTimeout = errors.New("write idle timeout")
+)
+
+// 默认心跳消息
+var (
+	DefaultPingMessage = []byte("\x00netconn:ping")
+	DefaultPongMessage = []byte("\x00netconn:pong")
+)
+
+// minHeartbeatCheckInterval 空闲检测的最小检查间隔
+const minHeartbeatCheckInterval = 10 * time.Millisecond
+
+// HeartbeatOption 应用层心跳与空闲检测配置，TCP 与 FILLP 行为一致
+// Ping/Pong 经连接的 Framer 编码收发（需同时设置 OnMessage），不会交给 OnMessage；未配置 Framer 时仅做空闲检测
+type HeartbeatOption struct {
+	PingInterval     time.Duration // 超过该时间未写出数据时发送 Ping（0表示不发送）
+	ReadIdleTimeout  time.Duration // 超过该时间未收到任何数据时关闭连接（0表示不检测）
+	WriteIdleTimeout time.Duration // 超过该时间未成功写出任何数据时关闭连接（0表示不检测）
+	Ping             []byte        // 心跳请求消息（nil=DefaultPingMessage），收到后自动回复 Pong
+	Pong             []byte        // 心跳响应消息（nil=DefaultPongMessage）
+}
+
+// connState 连接的读写活跃时间、流量统计和关闭原因
+type connState struct {
+	lastRead    atomic.Int64 // UnixNano
+	lastWrite   atomic.Int64
+	connectedAt time.Time
+	traffic     trafficStats
+	mu          sync.Mutex
+	reason      error
+}
+
+// newConnState 创建连接状态，活跃时间和连接时长从注册时开始计算
+func newConnState() *connState {
+	s := &connState{connectedAt: time.Now()}
+	now := s.connectedAt.UnixNano()
+	s.lastRead.Store(now)
+	s.lastWrite.Store(now)
+	return s
+}
+
+// touchRead 记录读活跃时间
+func (s *connState) touchRead() {
+	if s != nil {
+		s.lastRead.Store(time.Now().UnixNano())
+	}
+}
+
+// touchWrite 记录写活跃时间
+func (s *connState) touchWrite() {
+	if s != nil {
+		s.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+// setReason 记录关闭原因，只保留第一个
+func (s *connState) setReason(reason error) {
+	if s == nil || reason == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.reason == nil {
+		s.reason = reason
+	}
+	s.mu.Unlock()
+}
+
+// getReason 获取关闭原因
+func (s *connState) getReason() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason
+}
+
+// heartbeat 连接的心跳发送与空闲检测
+type heartbeat struct {
+	fd      int
+	opt     HeartbeatOption
+	connMgr *ConnectionManager
+	state   *connState
+	pinging atomic.Bool
+}
+
+// newHeartbeat 创建心跳，opt 为nil时返回nil
+func newHeartbeat(connMgr *ConnectionManager, fd int, opt *HeartbeatOption) *heartbeat {
+	if opt == nil {
+		return nil
+	}
+	h := &heartbeat{
+		fd:      fd,
+		opt:     *opt,
+		connMgr: connMgr,
+		state:   connMgr.getState(fd),
+	}
+	if h.opt.Ping == nil {
+		h.opt.Ping = DefaultPingMessage
+	}
+	if h.opt.Pong == nil {
+		h.opt.Pong = DefaultPongMessage
+	}
+	return h
+}
+
+// handleMessage 处理心跳消息（收到 Ping 时回复 Pong），返回 true 表示消息已被消费
+func (h *heartbeat) handleMessage(msg []byte) bool {
+	if bytes.Equal(msg, h.opt.Ping) {
+		_ = h.connMgr.SendMessage(h.fd, h.opt.Pong)
+		return true
+	}
+	return bytes.Equal(msg, h.opt.Pong)
+}
+
+// run 定期检查空闲时间并发送 Ping，done 关闭时退出
+func (h *heartbeat) run(done <-chan struct{}) {
+	if h.state == nil {
+		return
+	}
+	interval := h.checkInterval()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UnixNano()
+		if h.opt.ReadIdleTimeout > 0 && time.Duration(now-h.state.lastRead.Load()) >= h.opt.ReadIdleTimeout {
+			_ = h.connMgr.CloseWithReason(h.fd, ErrReadIdleTimeout)
+			return
+		}
+		idle := time.Duration(now - h.state.lastWrite.Load())
+		if h.opt.WriteIdleTimeout > 0 && idle >= h.opt.WriteIdleTimeout {
+			_ = h.connMgr.CloseWithReason(h.fd, ErrWriteIdleTimeout)
+			return
+		}
+		if h.opt.PingInterval > 0 && idle >= h.opt.PingInterval {
+			h.ping()
+		}
+	}
+}
+
+// ping 异步发送 Ping，避免同步写阻塞空闲检测；上一个 Ping 未写出时跳过
+func (h *heartbeat) ping() {
+	if _, ok := h.connMgr.GetFramer(h.fd); !ok {
+		return
+	}
+	if !h.pinging.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer h.pinging.Store(false)
+		_ = h.connMgr.SendMessage(h.fd, h.opt.Ping)
+	}()
+}
+
+// checkInterval 检查间隔取各超时时间最小值的 1/4
+func (h *heartbeat) checkInterval() time.Duration {
+	interval := time.Duration(0)
+	for _, d := range []time.Duration{h.opt.PingInterval, h.opt.ReadIdleTimeout, h.opt.WriteIdleTimeout} {
+		if d > 0 && (interval == 0 || d < interval) {
+			interval = d
+		}
+	}
+	if interval == 0 {
+		return 0
+	}
+	return max(interval/4, minHeartbeatCheckInterval)
+}
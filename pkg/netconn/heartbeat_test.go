@@ -0,0 +1,211 @@
+package netconn
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startHeartbeatServer 启动服务端，通过 reasons 报告连接的关闭原因
+func startHeartbeatServer(t *testing.T, opt *ServerOption, callback *BaseListenerCallback) (*BaseServer, chan error) {
+	t.Helper()
+	reasons := make(chan error, 1)
+	if callback == nil {
+		callback = &BaseListenerCallback{}
+	}
+	callback.OnClosed = func(fd int, connType ConnectionType, reason error) {
+		reasons <- reason
+	}
+	server := NewBaseServer(nil)
+	if err := server.StartBaseListener(opt, callback); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = server.StopBaseListener() })
+	time.Sleep(100 * time.Millisecond)
+	return server, reasons
+}
+
+// waitReason 等待关闭原因
+func waitReason(t *testing.T, reasons chan error, timeout time.Duration) error {
+	t.Helper()
+	select {
+	case reason := <-reasons:
+		return reason
+	case <-time.After(timeout):
+		t.Fatal("timeout waiting for connection close")
+		return nil
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		port      int
+		heartbeat *HeartbeatOption
+		want      error
+	}{
+		{"ReadIdle", 18099, &HeartbeatOption{ReadIdleTimeout: 200 * time.Millisecond}, ErrReadIdleTimeout},
+		{"WriteIdle", 18100, &HeartbeatOption{WriteIdleTimeout: 200 * time.Millisecond}, ErrWriteIdleTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, reasons := startHeartbeatServer(t, &ServerOption{
+				Protocol:  ProtocolTCP,
+				Addr:      "127.0.0.1",
+				Port:      tt.port,
+				Heartbeat: tt.heartbeat,
+			}, nil)
+
+			clientReasons := make(chan error, 1)
+			client := NewBaseClient(nil, &BaseListenerCallback{
+				OnClosed: func(fd int, connType ConnectionType, reason error) {
+					clientReasons <- reason
+				},
+			})
+			if _, err := client.ConnectSimple(ProtocolTCP, "127.0.0.1", tt.port); err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			defer client.Close()
+
+			start := time.Now()
+			if reason := waitReason(t, reasons, 2*time.Second); !errors.Is(reason, tt.want) {
+				t.Errorf("server close reason = %v, want %v", reason, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+				t.Errorf("connection closed after %v, before idle timeout", elapsed)
+			}
+			if reason := waitReason(t, clientReasons, 2*time.Second); !errors.Is(reason, io.EOF) {
+				t.Errorf("client close reason = %v, want EOF", reason)
+			}
+		})
+	}
+}
+
+func TestHeartbeatKeepsConnectionAlive(t *testing.T) {
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	var serverMessages atomic.Int32
+	_, reasons := startHeartbeatServer(t, &ServerOption{
+		Protocol:  ProtocolTCP,
+		Addr:      "127.0.0.1",
+		Port:      18101,
+		Framer:    framer,
+		Heartbeat: &HeartbeatOption{ReadIdleTimeout: 300 * time.Millisecond},
+	}, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) { serverMessages.Add(1) },
+	})
+
+	var clientMessages atomic.Int32
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) { clientMessages.Add(1) },
+	})
+	_, err := client.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18101,
+		Framer:     framer,
+		Heartbeat: &HeartbeatOption{
+			PingInterval:    50 * time.Millisecond,
+			ReadIdleTimeout: 300 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case reason := <-reasons:
+		t.Fatalf("connection closed despite heartbeat: %v", reason)
+	case <-time.After(time.Second):
+	}
+	if !client.IsConnected() {
+		t.Fatal("client should still be connected")
+	}
+	if n, m := serverMessages.Load(), clientMessages.Load(); n != 0 || m != 0 {
+		t.Errorf("ping/pong delivered to OnMessage: server=%d client=%d", n, m)
+	}
+
+	// 业务消息正常投递
+	if err := client.SendMessage([]byte("data")); err != nil {
+		t.Fatalf("SendMessage error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := serverMessages.Load(); n != 1 {
+		t.Errorf("server received %d messages, want 1", n)
+	}
+}
+
+func TestHeartbeatDeadPeerFILLP(t *testing.T) {
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	// 服务端未启用心跳，不回复 Pong，模拟无响应的对端
+	startHeartbeatServer(t, &ServerOption{
+		Protocol: ProtocolUDP,
+		Addr:     "127.0.0.1",
+		Port:     18102,
+		Framer:   framer,
+	}, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {},
+	})
+
+	reasons := make(chan error, 1)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {},
+		OnClosed: func(fd int, connType ConnectionType, reason error) {
+			reasons <- reason
+		},
+	})
+	_, err := client.Connect(&ClientOption{
+		Protocol:   ProtocolUDP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18102,
+		Framer:     framer,
+		Heartbeat: &HeartbeatOption{
+			PingInterval:    50 * time.Millisecond,
+			ReadIdleTimeout: 300 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if reason := waitReason(t, reasons, 3*time.Second); !errors.Is(reason, ErrReadIdleTimeout) {
+		t.Errorf("close reason = %v, want ErrReadIdleTimeout", reason)
+	}
+	if client.IsConnected() {
+		t.Error("client should be disconnected")
+	}
+}
+
+func TestCloseWithReason(t *testing.T) {
+	errKicked := errors.New("kicked")
+	connected := make(chan int, 1)
+	server, reasons := startHeartbeatServer(t, &ServerOption{
+		Protocol: ProtocolTCP,
+		Addr:     "127.0.0.1",
+		Port:     18103,
+	}, &BaseListenerCallback{
+		OnConnected: func(fd int, connType ConnectionType, connOpt *ConnectOption) {
+			if connType == ConnectionTypeClient {
+				connected <- fd
+			}
+		},
+	})
+
+	client := NewBaseClient(nil, nil)
+	if _, err := client.ConnectSimple(ProtocolTCP, "127.0.0.1", 18103); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	fd := <-connected
+	if err := server.ConnManager().CloseWithReason(fd, errKicked); err != nil {
+		t.Fatalf("CloseWithReason error = %v", err)
+	}
+	if reason := waitReason(t, reasons, 2*time.Second); !errors.Is(reason, errKicked) {
+		t.Errorf("close reason = %v, want %v", reason, errKicked)
+	}
+}
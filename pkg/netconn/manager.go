@@ -1,6 +1,7 @@
 package netconn
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -12,8 +13,17 @@ import (
 type ConnectionManager struct {
 	connMap     map[int]NetConnection
 	connTypeMap map[int]ConnectionType
+	framerMap   map[int]Framer
+	stateMap    map[int]*connState
 	mu          sync.RWMutex
 	nextFd      int64 // 全局自增FD，从1000开始
+
+	// 连接属性与分组
+	attrMap       map[int]map[string]string
+	attrIndex     map[string]map[string]map[int]struct{} // key -> value -> fd集合
+	groupMap      map[string]map[int]struct{}
+	connGroupMap  map[int]map[string]struct{}
+	writeQueueMap map[int]*writeQueue
 }
 
 // NewConnectionManager 创建新的连接管理器
@@ -21,7 +31,15 @@ func NewConnectionManager() *ConnectionManager {
 	return &ConnectionManager{
 		connMap:     make(map[int]NetConnection),
 		connTypeMap: make(map[int]ConnectionType),
+		framerMap:   make(map[int]Framer),
+		stateMap:    make(map[int]*connState),
 		nextFd:      1000,
+
+		attrMap:       make(map[int]map[string]string),
+		attrIndex:     make(map[string]map[string]map[int]struct{}),
+		groupMap:      make(map[string]map[int]struct{}),
+		connGroupMap:  make(map[int]map[string]struct{}),
+		writeQueueMap: make(map[int]*writeQueue),
 	}
 }
 
@@ -37,10 +55,20 @@ func (m *ConnectionManager) RegisterConn(conn NetConnection, connType Connection
 	m.mu.Lock()
 	m.connMap[fd] = conn
 	m.connTypeMap[fd] = connType
+	m.stateMap[fd] = newConnState()
 	m.mu.Unlock()
 	return fd
 }
 
+// registerConnWithFd 使用指定的虚拟fd注册连接（断线重连后保持fd不变）
+func (m *ConnectionManager) registerConnWithFd(fd int, conn NetConnection, connType ConnectionType) {
+	m.mu.Lock()
+	m.connMap[fd] = conn
+	m.connTypeMap[fd] = connType
+	m.stateMap[fd] = newConnState()
+	m.mu.Unlock()
+}
+
 // UnregisterConn 注销连接
 func (m *ConnectionManager) UnregisterConn(fd int) {
 	m.mu.Lock()
@@ -48,6 +76,9 @@ func (m *ConnectionManager) UnregisterConn(fd int) {
 		conn.Close()
 		delete(m.connMap, fd)
 		delete(m.connTypeMap, fd)
+		delete(m.framerMap, fd)
+		delete(m.stateMap, fd)
+		m.removeConnStateLocked(fd)
 	}
 	m.mu.Unlock()
 }
@@ -81,6 +112,47 @@ func (m *ConnectionManager) CloseConn(fd int) error {
 	err := conn.Close()
 	delete(m.connMap, fd)
 	delete(m.connTypeMap, fd)
+	delete(m.framerMap, fd)
+	delete(m.stateMap, fd)
+	m.removeConnStateLocked(fd)
+	return err
+}
+
+// CloseWithReason 以指定原因关闭连接，连接的接收循环退出后通过 OnClosed 回调报告该原因
+// 与 CloseConn 不同，连接由接收循环负责注销
+func (m *ConnectionManager) CloseWithReason(fd int, reason error) error {
+	m.mu.RLock()
+	conn, ok := m.connMap[fd]
+	state := m.stateMap[fd]
+	m.mu.RUnlock()
+	if !ok {
+		return errors.New("connection not found")
+	}
+
+	state.setReason(reason)
+	return conn.Close()
+}
+
+// getState 获取连接状态
+func (m *ConnectionManager) getState(fd int) *connState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stateMap[fd]
+}
+
+// closeReason 接收循环退出后确定连接的关闭原因，需在注销连接前调用
+// 优先使用 CloseWithReason/写队列记录的原因；已被本地关闭或停止时为 ErrConnClosed；否则为读取错误（对端关闭时为 io.EOF）
+func (m *ConnectionManager) closeReason(fd int, err error) error {
+	state := m.getState(fd)
+	if state == nil {
+		return ErrConnClosed
+	}
+	if reason := state.getReason(); reason != nil {
+		return reason
+	}
+	if err == nil {
+		return ErrConnClosed
+	}
 	return err
 }
 
@@ -92,19 +164,132 @@ func (m *ConnectionManager) CloseAll() {
 	for _, conn := range m.connMap {
 		conn.Close()
 	}
+	for _, q := range m.writeQueueMap {
+		q.stop()
+	}
 	m.connMap = make(map[int]NetConnection)
 	m.connTypeMap = make(map[int]ConnectionType)
+	m.framerMap = make(map[int]Framer)
+	m.stateMap = make(map[int]*connState)
+	m.attrMap = make(map[int]map[string]string)
+	m.attrIndex = make(map[string]map[string]map[int]struct{})
+	m.groupMap = make(map[string]map[int]struct{})
+	m.connGroupMap = make(map[int]map[string]struct{})
+	m.writeQueueMap = make(map[int]*writeQueue)
 }
 
 // SendBytes 通过虚拟fd发送数据
+// 连接启用写队列时数据入队后立即返回，由写协程异步写出；否则在调用方协程同步写出
 func (m *ConnectionManager) SendBytes(fd int, data []byte) error {
-	conn, ok := m.GetConn(fd)
+	return m.write(fd, data)
+}
+
+// EnableWriteQueue 为连接启用异步写队列，opt 为nil时使用默认配置
+// callback 中的 OnWriteHighWatermark/OnWriteLowWatermark 用于生产方限流
+func (m *ConnectionManager) EnableWriteQueue(fd int, opt *WriteQueueOption, callback *BaseListenerCallback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conn, ok := m.connMap[fd]
 	if !ok {
 		return errors.New("connection not found")
 	}
+	if _, ok := m.writeQueueMap[fd]; ok {
+		return errors.New("write queue already enabled")
+	}
+	m.writeQueueMap[fd] = newWriteQueue(fd, conn, m.stateMap[fd], opt, callback)
+	return nil
+}
 
-	_, err := conn.Write(data)
-	return err
+// QueuedBytes 返回连接写队列中尚未写出的字节数，未启用写队列时返回0
+func (m *ConnectionManager) QueuedBytes(fd int) int {
+	m.mu.RLock()
+	q, ok := m.writeQueueMap[fd]
+	m.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return q.queuedBytes()
+}
+
+// write 发送数据，有写队列时拷贝后入队
+func (m *ConnectionManager) write(fd int, data []byte) error {
+	m.mu.RLock()
+	conn, ok := m.connMap[fd]
+	q := m.writeQueueMap[fd]
+	state := m.stateMap[fd]
+	m.mu.RUnlock()
+	if !ok {
+		return errors.New("connection not found")
+	}
+
+	if q != nil {
+		err := q.send(append([]byte(nil), data...), true)
+		if err != nil {
+			state.recordError()
+		}
+		return err
+	}
+	if _, err := conn.Write(data); err != nil {
+		state.recordError()
+		return err
+	}
+	state.recordWrite(len(data))
+	return nil
+}
+
+// SetFramer 设置连接的帧编解码器
+func (m *ConnectionManager) SetFramer(fd int, framer Framer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.connMap[fd]; !ok {
+		return errors.New("connection not found")
+	}
+	if framer == nil {
+		delete(m.framerMap, fd)
+	} else {
+		m.framerMap[fd] = framer
+	}
+	return nil
+}
+
+// GetFramer 获取连接的帧编解码器
+func (m *ConnectionManager) GetFramer(fd int) (Framer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	framer, ok := m.framerMap[fd]
+	return framer, ok
+}
+
+// SendMessage 使用连接的帧编解码器编码消息后发送
+func (m *ConnectionManager) SendMessage(fd int, msg []byte) error {
+	if _, ok := m.GetConn(fd); !ok {
+		return errors.New("connection not found")
+	}
+	framer, ok := m.GetFramer(fd)
+	if !ok {
+		return errors.New("framer not set")
+	}
+
+	frame, err := framer.Encode(msg)
+	if err != nil {
+		return err
+	}
+	if err := m.write(fd, frame); err != nil {
+		return err
+	}
+	m.getState(fd).recordMessageOut()
+	return nil
+}
+
+// GetConnMetrics 获取连接的指标快照，连接不存在时返回nil
+func (m *ConnectionManager) GetConnMetrics(fd int) *ConnMetrics {
+	state := m.getState(fd)
+	if state == nil {
+		return nil
+	}
+	return state.metrics()
 }
 
 // GetConnInfo 获取连接的完整信息
@@ -117,20 +302,36 @@ func (m *ConnectionManager) GetConnInfo(fd int) *ConnectOption {
 	localAddr := conn.LocalAddr()
 	remoteAddr := conn.RemoteAddr()
 
-	connType, _ := m.GetConnType(fd)
-	protocol := ProtocolTCP
-	if connType == ConnectionTypeClient {
-		if _, ok := conn.(*UDPConnection); ok {
-			protocol = ProtocolUDP
-		}
-	}
-
-	return &ConnectOption{
-		Protocol:     protocol,
+	connOpt := &ConnectOption{
+		Protocol:     connProtocol(conn),
 		LocalSocket:  addrToSocketOption(localAddr),
 		RemoteSocket: addrToSocketOption(remoteAddr),
 		NetConn:      conn,
 	}
+	if tlsConn, ok := conn.(interface {
+		ConnectionState() (tls.ConnectionState, bool)
+	}); ok {
+		if state, ok := tlsConn.ConnectionState(); ok {
+			connOpt.TLS = &state
+		}
+	}
+	return connOpt
+}
+
+// connProtocol 根据连接适配器类型判断协议
+func connProtocol(conn NetConnection) ProtocolType {
+	switch c := conn.(type) {
+	case *UDPConnection:
+		return ProtocolUDP
+	case *WebSocketConnection:
+		return ProtocolWebSocket
+	case *UnixConnection:
+		if c.packet {
+			return ProtocolUnixPacket
+		}
+		return ProtocolUnix
+	}
+	return ProtocolTCP
 }
 
 // GetAllFds 获取所有活跃的虚拟fd
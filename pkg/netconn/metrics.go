@@ -0,0 +1,187 @@
+package netconn
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// serverMetrics 服务器指标统计
+type serverMetrics struct {
+	activeConns      atomic.Int64 // 当前连接数
+	totalAccepted    atomic.Int64 // 总接受数
+	rejectedDenied   atomic.Int64 // Deny/Allow 拒绝数
+	rejectedHook     atomic.Int64 // OnAccept 拒绝数
+	rejectedMaxConns atomic.Int64 // 连接总数超限拒绝数
+	rejectedPerIP    atomic.Int64 // 单IP连接数超限拒绝数
+	rejectedRate     atomic.Int64 // 接受速率超限拒绝数
+
+	// 已关闭连接的累计流量与连接时长，当前连接的部分在快照时合并
+	closedTraffic  trafficStats
+	closedDuration atomic.Int64
+}
+
+// recordAccept 记录接受连接
+func (m *serverMetrics) recordAccept() {
+	m.totalAccepted.Add(1)
+	m.activeConns.Add(1)
+}
+
+// recordRelease 记录连接结束
+func (m *serverMetrics) recordRelease() {
+	m.activeConns.Add(-1)
+}
+
+// recordReject 记录拒绝连接
+func (m *serverMetrics) recordReject(reason RejectReason) {
+	switch reason {
+	case RejectDenied:
+		m.rejectedDenied.Add(1)
+	case RejectHook:
+		m.rejectedHook.Add(1)
+	case RejectMaxConns:
+		m.rejectedMaxConns.Add(1)
+	case RejectPerIP:
+		m.rejectedPerIP.Add(1)
+	case RejectRate:
+		m.rejectedRate.Add(1)
+	}
+}
+
+// recordClosed 连接结束时累计其流量和连接时长
+func (m *serverMetrics) recordClosed(state *connState) {
+	if state == nil {
+		return
+	}
+	m.closedTraffic.add(&state.traffic)
+	m.closedDuration.Add(int64(time.Since(state.connectedAt)))
+}
+
+// snapshot 生成快照，live 为当前连接的状态
+func (m *serverMetrics) snapshot(live []*connState) *ServerMetrics {
+	s := &ServerMetrics{
+		ActiveConns:      m.activeConns.Load(),
+		TotalAccepted:    m.totalAccepted.Load(),
+		RejectedDenied:   m.rejectedDenied.Load(),
+		RejectedHook:     m.rejectedHook.Load(),
+		RejectedMaxConns: m.rejectedMaxConns.Load(),
+		RejectedPerIP:    m.rejectedPerIP.Load(),
+		RejectedRate:     m.rejectedRate.Load(),
+	}
+	s.TotalRejected = s.RejectedDenied + s.RejectedHook + s.RejectedMaxConns + s.RejectedPerIP + s.RejectedRate
+
+	var traffic trafficStats
+	traffic.add(&m.closedTraffic)
+	duration := time.Duration(m.closedDuration.Load())
+	for _, state := range live {
+		if state != nil {
+			traffic.add(&state.traffic)
+			duration += time.Since(state.connectedAt)
+		}
+	}
+	s.BytesIn = traffic.bytesIn.Load()
+	s.BytesOut = traffic.bytesOut.Load()
+	s.MessagesIn = traffic.messagesIn.Load()
+	s.MessagesOut = traffic.messagesOut.Load()
+	s.Errors = traffic.errors.Load()
+	s.ConnDuration = duration
+	return s
+}
+
+// ServerMetrics 服务器指标快照
+type ServerMetrics struct {
+	ActiveConns      int64 // 当前连接数
+	TotalAccepted    int64 // 总接受数
+	TotalRejected    int64 // 总拒绝数
+	RejectedDenied   int64 // Deny/Allow 拒绝数
+	RejectedHook     int64 // OnAccept 拒绝数
+	RejectedMaxConns int64 // 连接总数超限拒绝数
+	RejectedPerIP    int64 // 单IP连接数超限拒绝数
+	RejectedRate     int64 // 接受速率超限拒绝数
+
+	// 以下为所有连接（含已关闭）的累计值
+	BytesIn      int64         // 接收字节数
+	BytesOut     int64         // 发送字节数（已写出到连接）
+	MessagesIn   int64         // 交给 OnMessage 的消息数
+	MessagesOut  int64         // SendMessage/BroadcastMessage 成功发送或入队的消息数
+	Errors       int64         // 解码失败、帧过大和写失败次数
+	ConnDuration time.Duration // 累计连接时长
+}
+
+// trafficStats 流量计数
+type trafficStats struct {
+	bytesIn     atomic.Int64
+	bytesOut    atomic.Int64
+	messagesIn  atomic.Int64
+	messagesOut atomic.Int64
+	errors      atomic.Int64
+}
+
+// add 累加另一组计数
+func (t *trafficStats) add(o *trafficStats) {
+	t.bytesIn.Add(o.bytesIn.Load())
+	t.bytesOut.Add(o.bytesOut.Load())
+	t.messagesIn.Add(o.messagesIn.Load())
+	t.messagesOut.Add(o.messagesOut.Load())
+	t.errors.Add(o.errors.Load())
+}
+
+// ConnMetrics 单个连接的指标快照
+type ConnMetrics struct {
+	BytesIn     int64         // 接收字节数
+	BytesOut    int64         // 发送字节数（已写出到连接）
+	MessagesIn  int64         // 交给 OnMessage 的消息数
+	MessagesOut int64         // SendMessage/BroadcastMessage 成功发送或入队的消息数
+	Errors      int64         // 解码失败、帧过大和写失败次数
+	ConnectedAt time.Time     // 连接建立时间
+	Duration    time.Duration // 已连接时长
+}
+
+// recordRead 记录接收的字节数
+func (s *connState) recordRead(n int) {
+	if s != nil {
+		s.touchRead()
+		s.traffic.bytesIn.Add(int64(n))
+	}
+}
+
+// recordWrite 记录写出的字节数
+func (s *connState) recordWrite(n int) {
+	if s != nil {
+		s.touchWrite()
+		s.traffic.bytesOut.Add(int64(n))
+	}
+}
+
+// recordMessageIn 记录交给 OnMessage 的消息
+func (s *connState) recordMessageIn() {
+	if s != nil {
+		s.traffic.messagesIn.Add(1)
+	}
+}
+
+// recordMessageOut 记录发送的消息
+func (s *connState) recordMessageOut() {
+	if s != nil {
+		s.traffic.messagesOut.Add(1)
+	}
+}
+
+// recordError 记录错误
+func (s *connState) recordError() {
+	if s != nil {
+		s.traffic.errors.Add(1)
+	}
+}
+
+// metrics 生成连接指标快照
+func (s *connState) metrics() *ConnMetrics {
+	return &ConnMetrics{
+		BytesIn:     s.traffic.bytesIn.Load(),
+		BytesOut:    s.traffic.bytesOut.Load(),
+		MessagesIn:  s.traffic.messagesIn.Load(),
+		MessagesOut: s.traffic.messagesOut.Load(),
+		Errors:      s.traffic.errors.Load(),
+		ConnectedAt: s.connectedAt,
+		Duration:    time.Since(s.connectedAt),
+	}
+}
@@ -0,0 +1,111 @@
+package netconn
+
+import "errors"
+
+// errCallbackClosed OnDataReceived 返回负值，要求关闭连接
+var errCallbackClosed = errors.New("closed by callback")
+
+// connReader 连接接收循环，服务端与客户端共用
+// 接收缓冲区从 DefaultBufSize 开始按需倍增，上限为 maxFrameSize；缓冲区已满仍无法取出数据时返回 ErrFrameTooLarge
+type connReader struct {
+	conn         NetConnection
+	fd           int
+	connType     ConnectionType // 传给 OnDataReceived 的连接类型
+	callback     *BaseListenerCallback
+	framer       Framer
+	maxFrameSize int
+	state        *connState // 记录读活跃时间和流量统计，可为nil
+	heartbeat    *heartbeat // 处理 Ping/Pong 消息，可为nil
+}
+
+// run 循环读取数据直到连接出错、回调要求断开或 stopChan 关闭
+func (r *connReader) run(stopChan <-chan struct{}) error {
+	maxSize := r.maxFrameSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	bufSize := min(DefaultBufSize, maxSize)
+	buf := make([]byte, bufSize)
+	offset := 0
+
+	for {
+		select {
+		case <-stopChan:
+			return nil
+		default:
+		}
+
+		// 缓冲区已满：扩容，已达上限则视为帧过大
+		if offset == len(buf) {
+			if len(buf) >= maxSize {
+				r.state.recordError()
+				return ErrFrameTooLarge
+			}
+			grown := make([]byte, min(len(buf)*2, maxSize))
+			copy(grown, buf[:offset])
+			buf = grown
+		}
+
+		n, err := r.conn.Read(buf[offset:])
+		if err != nil {
+			return err
+		}
+		offset += n
+		r.state.recordRead(n)
+
+		processed, err := r.dispatch(buf, offset)
+		if err != nil {
+			if err != errCallbackClosed {
+				r.state.recordError()
+			}
+			return err
+		}
+		if processed > 0 {
+			copy(buf, buf[processed:offset])
+			offset -= processed
+		}
+
+		// 大帧处理完毕后收缩缓冲区，避免长期占用内存
+		if offset == 0 && len(buf) > bufSize {
+			buf = make([]byte, bufSize)
+		}
+	}
+}
+
+// dispatch 将缓冲区数据交给回调，返回已处理的字节数
+func (r *connReader) dispatch(buf []byte, used int) (int, error) {
+	if r.callback == nil {
+		return used, nil
+	}
+
+	if r.framer != nil && r.callback.OnMessage != nil {
+		processed := 0
+		for processed < used {
+			msg, n, err := r.framer.Decode(buf[processed:used])
+			if err != nil {
+				return 0, err
+			}
+			if n == 0 {
+				break
+			}
+			if n < 0 || n > used-processed {
+				return 0, ErrInvalidFrame
+			}
+			if r.heartbeat == nil || !r.heartbeat.handleMessage(msg) {
+				r.state.recordMessageIn()
+				r.callback.OnMessage(r.fd, msg)
+			}
+			processed += n
+		}
+		return processed, nil
+	}
+
+	if r.callback.OnDataReceived == nil {
+		return used, nil
+	}
+	processed := r.callback.OnDataReceived(r.fd, r.connType, buf, used)
+	if processed < 0 {
+		return 0, errCallbackClosed
+	}
+	return min(processed, used), nil
+}
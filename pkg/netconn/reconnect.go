@@ -0,0 +1,193 @@
+package netconn
+
+import (
+	"errors"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/timer"
+)
+
+// ErrSendQueueFull 断线期间发送缓存已满
+var ErrSendQueueFull = errors.New("send queue full")
+
+// 断线重连默认参数
+const (
+	DefaultReconnectInitialDelay = 500 * time.Millisecond
+	DefaultReconnectMaxDelay     = 30 * time.Second
+	DefaultReconnectQueueSize    = 256
+)
+
+// ReconnectPolicy 客户端断线重连策略
+// 退避语义与 timer.ExponentialBackoff 一致：第1次重连等待 InitialDelay，之后每次翻倍
+type ReconnectPolicy struct {
+	InitialDelay time.Duration // 首次重连延迟（0=使用默认500ms）
+	MaxDelay     time.Duration // 重连延迟上限（0=使用默认30s）
+	Jitter       float64       // 随机抖动比例（0~1），避免大量客户端同时重连
+	MaxAttempts  int           // 最大重连次数（0表示不限）
+	QueueSize    int           // 断线期间缓存的最大消息数（0=使用默认256，负数表示不缓存）
+}
+
+// withDefaults 填充默认值，nil 表示未启用重连
+func (p *ReconnectPolicy) withDefaults() *ReconnectPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := *p
+	if policy.InitialDelay <= 0 {
+		policy.InitialDelay = DefaultReconnectInitialDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultReconnectMaxDelay
+	}
+	if policy.QueueSize == 0 {
+		policy.QueueSize = DefaultReconnectQueueSize
+	}
+	return &policy
+}
+
+// reconnectLoop 按退避策略重连，成功后沿用原fd并发送缓存数据
+func (c *BaseClient) reconnectLoop(fd int, stopChan chan struct{}) {
+	c.mu.RLock()
+	policy := c.reconnect
+	opt := c.opt
+	c.mu.RUnlock()
+
+	var err error
+	attempt := 0
+	for policy.MaxAttempts <= 0 || attempt < policy.MaxAttempts {
+		attempt++
+		delay := timer.BackoffDelay(attempt, policy.InitialDelay, policy.MaxDelay, policy.Jitter)
+		if c.callback != nil && c.callback.OnReconnecting != nil {
+			c.callback.OnReconnecting(fd, attempt, delay)
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-stopChan:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		var conn NetConnection
+		if conn, err = c.dial(opt); err != nil {
+			continue
+		}
+
+		// 先发布连接再在锁外发送缓存数据，发送完成前新的发送等待 flushMu，保证数据顺序
+		c.flushMu.Lock()
+		c.mu.Lock()
+		if isClosed(stopChan) {
+			c.mu.Unlock()
+			c.flushMu.Unlock()
+			conn.Close()
+			return
+		}
+		c.connMgr.registerConnWithFd(fd, conn, ConnectionTypeClient)
+		if c.framer != nil {
+			_ = c.connMgr.SetFramer(fd, c.framer)
+		}
+		if opt.WriteQueue != nil {
+			_ = c.connMgr.EnableWriteQueue(fd, opt.WriteQueue, c.callback)
+		}
+		backlog := c.takePending()
+		c.running = true
+		c.reconnecting = false
+		c.mu.Unlock()
+
+		c.flushPending(fd, conn, backlog, opt.WriteQueue != nil, connectTimeout(opt))
+		c.flushMu.Unlock()
+		if isClosed(stopChan) {
+			// 发送缓存数据期间被 Close
+			c.clearPending()
+			return
+		}
+
+		if c.callback != nil && c.callback.OnConnected != nil {
+			c.callback.OnConnected(fd, ConnectionTypeClient, c.connMgr.GetConnInfo(fd))
+		}
+		if c.callback != nil && c.callback.OnReconnected != nil {
+			c.callback.OnReconnected(fd, attempt)
+		}
+
+		go c.receiveLoop(conn, fd, stopChan)
+		return
+	}
+
+	// 达到最大重连次数，放弃重连
+	c.mu.Lock()
+	if isClosed(stopChan) {
+		c.mu.Unlock()
+		return
+	}
+	c.reconnecting = false
+	close(stopChan)
+	c.clearPending()
+	c.mu.Unlock()
+
+	if c.callback != nil && c.callback.OnReconnectFailed != nil {
+		c.callback.OnReconnectFailed(fd, attempt, err)
+	}
+}
+
+// enqueue 断线期间缓存待发送数据，调用方需持有 c.mu 读锁
+func (c *BaseClient) enqueue(data []byte) error {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	if len(c.pending) >= c.reconnect.QueueSize {
+		return ErrSendQueueFull
+	}
+	c.pending = append(c.pending, append([]byte(nil), data...))
+	return nil
+}
+
+// takePending 取出全部缓存数据
+func (c *BaseClient) takePending() [][]byte {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	backlog := c.pending
+	c.pending = nil
+	return backlog
+}
+
+// flushPending 重连成功后按顺序发送缓存数据，调用方需持有 c.flushMu 且不持有 c.mu
+// 启用写队列时投递到写队列，否则同步写出且每次写出最长 timeout；
+// 发送失败时关闭连接，剩余数据放回缓存，等待接收循环检测到断线后再次重连
+func (c *BaseClient) flushPending(fd int, conn NetConnection, backlog [][]byte, queued bool, timeout time.Duration) {
+	for i, data := range backlog {
+		var err error
+		if queued {
+			err = c.connMgr.SendBytes(fd, data)
+		} else if err = writeWithTimeout(conn, [][]byte{data}, timeout); err == nil {
+			if state := c.connMgr.getState(fd); state != nil {
+				state.recordWrite(len(data))
+			}
+		}
+		if err != nil {
+			_ = c.connMgr.CloseWithReason(fd, err)
+			c.pendingMu.Lock()
+			c.pending = append(backlog[i:len(backlog):len(backlog)], c.pending...)
+			c.pendingMu.Unlock()
+			return
+		}
+		backlog[i] = nil
+	}
+}
+
+// clearPending 丢弃缓存数据
+func (c *BaseClient) clearPending() {
+	c.pendingMu.Lock()
+	c.pending = nil
+	c.pendingMu.Unlock()
+}
+
+// isClosed 判断停止通道是否已关闭
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
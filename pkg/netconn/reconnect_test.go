@@ -0,0 +1,274 @@
+package netconn
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func startEchoCollector(t *testing.T, port int, framer Framer, received chan<- string) *BaseServer {
+	t.Helper()
+	server := NewBaseServer(nil)
+	opt := &ServerOption{Protocol: ProtocolTCP, Addr: "127.0.0.1", Port: port, Framer: framer}
+	callback := &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			received <- string(msg)
+		},
+	}
+	if err := server.StartBaseListener(opt, callback); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	return server
+}
+
+// stopServer 停止监听并断开所有连接
+func stopServer(server *BaseServer) {
+	// 等待刚建立的连接完成注册
+	for i := 0; i < 50 && server.connMgr.GetConnCount() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = server.StopBaseListener()
+	server.connMgr.CloseAll()
+}
+
+func TestClientReconnect(t *testing.T) {
+	framer, _ := NewLengthFieldFramer(2, binary.BigEndian, 0)
+	received := make(chan string, 8)
+	server := startEchoCollector(t, 18088, framer, received)
+
+	var reconnecting, reconnected, connected int32
+	disconnected := make(chan struct{}, 1)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnConnected: func(fd int, connType ConnectionType, connOpt *ConnectOption) {
+			atomic.AddInt32(&connected, 1)
+		},
+		OnDisconnected: func(fd int, connType ConnectionType) {
+			disconnected <- struct{}{}
+		},
+		OnReconnecting: func(fd int, attempt int, delay time.Duration) {
+			atomic.AddInt32(&reconnecting, 1)
+		},
+		OnReconnected: func(fd int, attempt int) {
+			atomic.AddInt32(&reconnected, 1)
+		},
+	})
+	fd, err := client.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18088,
+		Framer:     framer,
+		Reconnect:  &ReconnectPolicy{InitialDelay: 50 * time.Millisecond, MaxDelay: 200 * time.Millisecond, QueueSize: 2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// 服务端下线，客户端进入重连状态
+	stopServer(server)
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for disconnect")
+	}
+	if !client.IsReconnecting() || client.IsConnected() {
+		t.Fatal("client should be reconnecting")
+	}
+
+	// 断线期间的消息进入有界缓存
+	if err := client.SendMessage([]byte("first")); err != nil {
+		t.Fatalf("SendMessage while reconnecting error = %v", err)
+	}
+	if err := client.SendMessage([]byte("second")); err != nil {
+		t.Fatalf("SendMessage while reconnecting error = %v", err)
+	}
+	if err := client.SendMessage([]byte("third")); !errors.Is(err, ErrSendQueueFull) {
+		t.Errorf("SendMessage on full queue error = %v, want ErrSendQueueFull", err)
+	}
+
+	// 服务端恢复后自动重连，缓存消息按顺序送达
+	time.Sleep(200 * time.Millisecond)
+	server = startEchoCollector(t, 18088, framer, received)
+	defer stopServer(server)
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case msg := <-received:
+			if msg != want {
+				t.Errorf("received %q, want %q", msg, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timeout waiting for %q", want)
+		}
+	}
+
+	if !client.IsConnected() || client.GetFd() != fd {
+		t.Errorf("client should be connected with the same fd %d, got %d", fd, client.GetFd())
+	}
+	if atomic.LoadInt32(&reconnecting) == 0 || atomic.LoadInt32(&reconnected) != 1 || atomic.LoadInt32(&connected) != 2 {
+		t.Errorf("events: reconnecting=%d reconnected=%d connected=%d", reconnecting, reconnected, connected)
+	}
+
+	if err := client.SendMessage([]byte("after")); err != nil {
+		t.Fatalf("SendMessage after reconnect error = %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg != "after" {
+			t.Errorf("received %q, want after", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for message after reconnect")
+	}
+}
+
+func TestClientReconnectGiveUp(t *testing.T) {
+	received := make(chan string, 1)
+	server := startEchoCollector(t, 18089, nil, received)
+
+	failed := make(chan int, 1)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnReconnectFailed: func(fd int, attempts int, err error) {
+			if err == nil {
+				t.Error("OnReconnectFailed should report the last error")
+			}
+			failed <- attempts
+		},
+	})
+	_, err := client.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18089,
+		Reconnect:  &ReconnectPolicy{InitialDelay: 20 * time.Millisecond, MaxAttempts: 3},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	stopServer(server)
+
+	select {
+	case attempts := <-failed:
+		if attempts != 3 {
+			t.Errorf("attempts = %d, want 3", attempts)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for give-up")
+	}
+	if client.IsReconnecting() || client.IsConnected() {
+		t.Error("client should be idle after giving up")
+	}
+	if err := client.SendBytes([]byte("x")); err == nil {
+		t.Error("SendBytes should fail after giving up")
+	}
+}
+
+func TestClientCloseStopsReconnect(t *testing.T) {
+	received := make(chan string, 1)
+	server := startEchoCollector(t, 18090, nil, received)
+
+	var attempts int32
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnReconnecting: func(fd int, attempt int, delay time.Duration) {
+			atomic.AddInt32(&attempts, 1)
+		},
+	})
+	if _, err := client.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18090,
+		Reconnect:  &ReconnectPolicy{InitialDelay: 20 * time.Millisecond},
+	}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	stopServer(server)
+	time.Sleep(100 * time.Millisecond)
+
+	client.Close()
+	if client.IsReconnecting() {
+		t.Error("Close should stop reconnecting")
+	}
+	n := atomic.LoadInt32(&attempts)
+	time.Sleep(150 * time.Millisecond)
+	if atomic.LoadInt32(&attempts) != n {
+		t.Error("reconnect attempts continued after Close")
+	}
+}
+
+func TestClientReconnectFlushStalledPeer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:18091")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn // 从不读取
+		}
+	}()
+
+	disconnected := make(chan struct{}, 2)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnDisconnected: func(fd int, connType ConnectionType) {
+			disconnected <- struct{}{}
+		},
+	})
+	if _, err := client.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18091,
+		Timeout:    5 * time.Second,
+		Reconnect:  &ReconnectPolicy{InitialDelay: 50 * time.Millisecond, QueueSize: 128},
+	}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	first := <-accepted
+	first.Close()
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for disconnect")
+	}
+
+	// 断线期间缓存远超套接字缓冲区的数据，重连后对端不读取，发送缓存数据会阻塞
+	chunk := make([]byte, 64<<10)
+	for i := 0; i < 128; i++ {
+		if err := client.SendBytes(chunk); err != nil {
+			t.Fatalf("SendBytes while reconnecting error = %v", err)
+		}
+	}
+	second := <-accepted
+	defer second.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.IsConnected() {
+		if time.Now().After(deadline) {
+			t.Fatal("client not connected after reconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 发送缓存数据期间不持有客户端锁：查询和关闭立即返回
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = client.GetConnInfo()
+		client.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Close blocked by pending flush to stalled peer")
+	}
+	if client.IsConnected() || client.IsReconnecting() {
+		t.Error("client should be closed")
+	}
+}
@@ -0,0 +1,382 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/binpack"
+	"github.com/junbin-yang/go-kitbox/pkg/netconn"
+)
+
+// Request 收到的请求或通知
+type Request struct {
+	Fd       int
+	Method   string
+	Payload  []byte
+	IsNotify bool // 单向通知，处理结果不会返回给对端
+
+	response []byte
+}
+
+// SetResponse 设置响应数据，供不直接返回结果的处理函数（如 zallocrout 路由处理函数）使用
+func (r *Request) SetResponse(data []byte) {
+	r.response = data
+}
+
+// Handler 请求处理函数，返回的数据作为响应发送给调用方
+// ctx 在调用方取消、请求超时或连接断开时结束
+type Handler func(ctx context.Context, req *Request) ([]byte, error)
+
+// SendFunc 向指定fd发送一帧数据
+type SendFunc func(fd int, frame []byte) error
+
+// inflightKey 正在处理的请求
+type inflightKey struct {
+	fd int
+	id uint32
+}
+
+// pendingCall 等待响应的调用
+type pendingCall struct {
+	fd   int
+	done chan *message // 收到响应或连接断开（nil）
+}
+
+// Endpoint RPC端点：既可发起调用，也可处理对端请求，与具体传输无关
+// 收到的帧通过 HandleMessage 输入，连接断开时调用 ConnClosed
+type Endpoint struct {
+	framer atomic.Pointer[netconn.BinpackHeaderFramer] // 传输层重新启动时替换
+	send   SendFunc
+	nextID uint32
+
+	handlers  map[string]Handler
+	fallback  Handler
+	handlerMu sync.RWMutex
+
+	pending   map[uint32]*pendingCall
+	pendingMu sync.Mutex
+	closed    bool
+
+	inflight   map[inflightKey]context.CancelFunc
+	inflightMu sync.Mutex
+}
+
+// NewEndpoint 创建RPC端点，framer 由 NewFramer 创建，send 用于发送编码后的帧
+func NewEndpoint(framer *netconn.BinpackHeaderFramer, send SendFunc) *Endpoint {
+	e := &Endpoint{
+		send:     send,
+		handlers: make(map[string]Handler),
+		pending:  make(map[uint32]*pendingCall),
+		inflight: make(map[inflightKey]context.CancelFunc),
+	}
+	e.framer.Store(framer)
+	return e
+}
+
+// Handle 注册方法处理函数
+func (e *Endpoint) Handle(method string, handler Handler) {
+	e.handlerMu.Lock()
+	e.handlers[method] = handler
+	e.handlerMu.Unlock()
+}
+
+// HandleFallback 注册未匹配方法的处理函数
+func (e *Endpoint) HandleFallback(handler Handler) {
+	e.handlerMu.Lock()
+	e.fallback = handler
+	e.handlerMu.Unlock()
+}
+
+// Call 调用对端方法并等待响应
+// ctx 的截止时间随请求发送给对端；ctx 结束时向对端发送取消消息并返回 ctx.Err()
+func (e *Endpoint) Call(ctx context.Context, fd int, method string, req []byte) ([]byte, error) {
+	header := frameHeader{Type: uint8(TypeRequest), ID: e.allocID()}
+	if deadline, ok := ctx.Deadline(); ok {
+		remain := time.Until(deadline)
+		if remain <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+		header.Timeout = uint32((remain + time.Millisecond - 1) / time.Millisecond)
+	}
+
+	call := &pendingCall{fd: fd, done: make(chan *message, 1)}
+	e.pendingMu.Lock()
+	if e.closed {
+		e.pendingMu.Unlock()
+		return nil, ErrClosed
+	}
+	e.pending[header.ID] = call
+	e.pendingMu.Unlock()
+	defer e.removePending(header.ID)
+
+	if err := e.sendFrame(fd, &header, method, req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-call.done:
+		if msg == nil {
+			return nil, ErrClosed
+		}
+		if MessageType(msg.header.Type) == TypeError {
+			return nil, &Error{Code: msg.header.Code, Message: string(msg.payload)}
+		}
+		return msg.payload, nil
+	case <-ctx.Done():
+		// 通知对端停止处理，失败时忽略
+		_ = e.sendFrame(fd, &frameHeader{Type: uint8(TypeCancel), ID: header.ID}, "", nil)
+		return nil, ctx.Err()
+	}
+}
+
+// Notify 向对端发送单向通知（服务端推送），不等待响应
+func (e *Endpoint) Notify(fd int, method string, payload []byte) error {
+	return e.sendFrame(fd, &frameHeader{Type: uint8(TypeNotify)}, method, payload)
+}
+
+// HandleMessage 处理收到的一帧数据，msg 为完整帧（帧头 + 消息体），可在返回后被复用
+func (e *Endpoint) HandleMessage(fd int, msg []byte) {
+	m, err := e.parse(msg)
+	if err != nil {
+		return
+	}
+
+	switch MessageType(m.header.Type) {
+	case TypeResponse, TypeError:
+		e.pendingMu.Lock()
+		call, ok := e.pending[m.header.ID]
+		if ok && call.fd == fd {
+			delete(e.pending, m.header.ID)
+		}
+		e.pendingMu.Unlock()
+		if ok && call.fd == fd {
+			call.done <- m
+		}
+
+	case TypeRequest:
+		// 启动处理协程前登记请求，紧随其后的取消帧才能找到它
+		ctx, cancel := e.track(fd, m)
+		go e.serve(ctx, cancel, fd, m)
+
+	case TypeNotify:
+		// 同步处理以保持推送顺序
+		ctx, cancel := requestContext(m)
+		e.serve(ctx, cancel, fd, m)
+
+	case TypeCancel:
+		e.inflightMu.Lock()
+		cancel, ok := e.inflight[inflightKey{fd, m.header.ID}]
+		e.inflightMu.Unlock()
+		if ok {
+			cancel()
+		}
+	}
+}
+
+// ConnClosed 连接断开：该连接上等待中的调用返回 ErrClosed，正在处理的请求被取消
+func (e *Endpoint) ConnClosed(fd int) {
+	e.pendingMu.Lock()
+	for id, call := range e.pending {
+		if call.fd == fd {
+			delete(e.pending, id)
+			call.done <- nil
+		}
+	}
+	e.pendingMu.Unlock()
+
+	e.inflightMu.Lock()
+	for key, cancel := range e.inflight {
+		if key.fd == fd {
+			cancel()
+		}
+	}
+	e.inflightMu.Unlock()
+}
+
+// Close 关闭端点：所有等待中的调用返回 ErrClosed，正在处理的请求被取消
+func (e *Endpoint) Close() {
+	e.pendingMu.Lock()
+	e.closed = true
+	for id, call := range e.pending {
+		delete(e.pending, id)
+		call.done <- nil
+	}
+	e.pendingMu.Unlock()
+
+	e.inflightMu.Lock()
+	for _, cancel := range e.inflight {
+		cancel()
+	}
+	e.inflightMu.Unlock()
+}
+
+// reset 设置帧编解码器并重新启用端点（传输层重新启动时调用）
+func (e *Endpoint) reset(framer *netconn.BinpackHeaderFramer) {
+	e.framer.Store(framer)
+	e.pendingMu.Lock()
+	e.closed = false
+	e.pendingMu.Unlock()
+}
+
+// requestContext 创建处理函数的 context，请求携带超时时设置截止时间
+func requestContext(m *message) (context.Context, context.CancelFunc) {
+	if m.header.Timeout > 0 {
+		return context.WithTimeout(context.Background(), time.Duration(m.header.Timeout)*time.Millisecond)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// track 创建请求的 context 并登记到正在处理的请求中，由 serve 在处理结束后移除
+func (e *Endpoint) track(fd int, m *message) (context.Context, context.CancelFunc) {
+	ctx, cancel := requestContext(m)
+	e.inflightMu.Lock()
+	e.inflight[inflightKey{fd, m.header.ID}] = cancel
+	e.inflightMu.Unlock()
+	return ctx, cancel
+}
+
+// serve 执行处理函数，请求类型的消息发送响应，ctx 在返回时被取消
+func (e *Endpoint) serve(ctx context.Context, cancel context.CancelFunc, fd int, m *message) {
+	defer cancel()
+	isNotify := MessageType(m.header.Type) == TypeNotify
+	if !isNotify {
+		key := inflightKey{fd, m.header.ID}
+		defer func() {
+			e.inflightMu.Lock()
+			delete(e.inflight, key)
+			e.inflightMu.Unlock()
+		}()
+	}
+	req := &Request{Fd: fd, Method: m.method, Payload: m.payload, IsNotify: isNotify}
+
+	handler := e.lookup(m.method)
+	if handler == nil {
+		if !isNotify {
+			e.reply(fd, m.header.ID, nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + m.method})
+		}
+		return
+	}
+
+	resp, err := e.invoke(ctx, handler, req)
+	if isNotify {
+		return
+	}
+	if err != nil {
+		e.reply(fd, m.header.ID, nil, toError(ctx, err))
+		return
+	}
+	e.reply(fd, m.header.ID, resp, nil)
+}
+
+// invoke 执行处理函数并捕获 panic
+func (e *Endpoint) invoke(ctx context.Context, handler Handler, req *Request) (resp []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	resp, err = handler(ctx, req)
+	if resp == nil && err == nil {
+		resp = req.response
+	}
+	return resp, err
+}
+
+// reply 发送响应
+func (e *Endpoint) reply(fd int, id uint32, resp []byte, rpcErr *Error) {
+	header := frameHeader{Type: uint8(TypeResponse), ID: id}
+	if rpcErr != nil {
+		header.Type = uint8(TypeError)
+		header.Code = rpcErr.Code
+		resp = []byte(rpcErr.Message)
+	}
+	_ = e.sendFrame(fd, &header, "", resp)
+}
+
+// lookup 查找方法处理函数
+func (e *Endpoint) lookup(method string) Handler {
+	e.handlerMu.RLock()
+	defer e.handlerMu.RUnlock()
+	if handler, ok := e.handlers[method]; ok {
+		return handler
+	}
+	return e.fallback
+}
+
+// sendFrame 编码并发送一帧
+func (e *Endpoint) sendFrame(fd int, header *frameHeader, method string, payload []byte) error {
+	if len(method) > 0xFFFF {
+		return ErrMethodTooLong
+	}
+	header.Magic = frameMagic
+	header.MethodLen = uint16(len(method))
+
+	body := make([]byte, len(method)+len(payload))
+	copy(body, method)
+	copy(body[len(method):], payload)
+
+	frame, err := e.framer.Load().Frame(header, body)
+	if err != nil {
+		return err
+	}
+	return e.send(fd, frame)
+}
+
+// parse 解析帧，拷贝消息体以便在回调返回后使用
+func (e *Endpoint) parse(frame []byte) (*message, error) {
+	size := e.framer.Load().HeaderSize()
+	if len(frame) < size {
+		return nil, netconn.ErrInvalidFrame
+	}
+
+	m := &message{}
+	if err := binpack.Unmarshal(frame[:size], &m.header); err != nil {
+		return nil, err
+	}
+	body := frame[size:]
+	if m.header.Magic != frameMagic || int(m.header.MethodLen) > len(body) {
+		return nil, netconn.ErrInvalidFrame
+	}
+
+	m.method = string(body[:m.header.MethodLen])
+	if len(body) > int(m.header.MethodLen) {
+		m.payload = append([]byte(nil), body[m.header.MethodLen:]...)
+	}
+	return m, nil
+}
+
+// allocID 分配非零的关联ID
+func (e *Endpoint) allocID() uint32 {
+	for {
+		if id := atomic.AddUint32(&e.nextID, 1); id != 0 {
+			return id
+		}
+	}
+}
+
+// removePending 移除等待中的调用
+func (e *Endpoint) removePending(id uint32) {
+	e.pendingMu.Lock()
+	delete(e.pending, id)
+	e.pendingMu.Unlock()
+}
+
+// toError 将处理函数的错误转换为RPC错误
+func toError(ctx context.Context, err error) *Error {
+	var rpcErr *Error
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &Error{Code: CodeDeadline, Message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return &Error{Code: CodeCanceled, Message: err.Error()}
+	default:
+		return &Error{Code: CodeHandlerError, Message: err.Error()}
+	}
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/junbin-yang/go-kitbox/pkg/netconn"
+)
+
+// 帧魔数 "RP"
+const frameMagic = 0x5250
+
+// MessageType 消息类型
+type MessageType uint8
+
+const (
+	TypeRequest  MessageType = iota + 1 // 请求，需要响应
+	TypeResponse                        // 成功响应
+	TypeError                           // 失败响应，Code 为错误码、消息体为错误信息
+	TypeCancel                          // 取消请求
+	TypeNotify                          // 单向通知（含服务端推送），无响应
+)
+
+// 错误码
+const (
+	CodeOK             uint16 = iota
+	CodeMethodNotFound        // 方法未注册
+	CodeHandlerError          // 处理函数返回错误
+	CodeCanceled              // 请求被取消
+	CodeDeadline              // 请求超时
+	CodeBadRequest            // 请求格式错误
+)
+
+// frameHeader 帧头，消息体为方法名 + 负载
+type frameHeader struct {
+	Magic     uint16 `bin:"0:2:be"`
+	Type      uint8  `bin:"2:1"`
+	Reserved  uint8  `bin:"3:1"`
+	ID        uint32 `bin:"4:4:be"`  // 关联ID，请求与响应/取消一一对应
+	Timeout   uint32 `bin:"8:4:be"`  // 请求剩余超时（毫秒），0表示不限
+	Code      uint16 `bin:"12:2:be"` // 错误码
+	MethodLen uint16 `bin:"14:2:be"` // 方法名长度
+	Length    uint32 `bin:"16:4:be"` // 消息体长度（方法名 + 负载）
+}
+
+var (
+	// ErrClosed 连接断开或端点已关闭，未完成的调用以此错误返回
+	ErrClosed = errors.New("rpc: connection closed")
+	// ErrMethodTooLong 方法名超过帧头可表示的长度
+	ErrMethodTooLong = errors.New("rpc: method name too long")
+)
+
+// Error 对端返回的错误
+type Error struct {
+	Code    uint16
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// NewFramer 创建RPC帧编解码器，maxFrameSize 为消息体上限（0表示使用 netconn.DefaultMaxFrameSize）
+func NewFramer(maxFrameSize int) (*netconn.BinpackHeaderFramer, error) {
+	if maxFrameSize <= 0 {
+		maxFrameSize = netconn.DefaultMaxFrameSize
+	}
+	return netconn.NewBinpackHeaderFramer(frameHeader{}, "Length", maxFrameSize)
+}
+
+// message 解析后的消息
+type message struct {
+	header  frameHeader
+	method  string
+	payload []byte
+}
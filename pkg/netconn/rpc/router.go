@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+)
+
+// RouterMethod 在 zallocrout.Router 中注册RPC路由时使用的方法名
+const RouterMethod = "RPC"
+
+// requestKey 请求在 context 中的键
+type requestKey struct{}
+
+// RequestFromContext 从路由处理函数的 context 中获取RPC请求
+func RequestFromContext(ctx context.Context) (*Request, bool) {
+	req, ok := ctx.Value(requestKey{}).(*Request)
+	return req, ok
+}
+
+// RouterHandler 将 zallocrout.Router 适配为 Handler，RPC方法名作为路由路径（如 "/user/:id/profile"）
+// 路由处理函数通过 RequestFromContext 获取请求、zallocrout.GetParam 获取路径参数，并调用 SetResponse 设置响应
+func RouterHandler(router *zallocrout.Router) Handler {
+	return func(ctx context.Context, req *Request) ([]byte, error) {
+		routeCtx, handler, middlewares, ok := router.Match(RouterMethod, req.Method, context.WithValue(ctx, requestKey{}, req))
+		if !ok {
+			return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+		}
+		if err := zallocrout.ExecuteHandler(routeCtx, handler, middlewares); err != nil {
+			return nil, err
+		}
+		return req.response, nil
+	}
+}
+
+// HandleRouter 未通过 Handle 注册的方法交由 zallocrout 路由处理
+func (e *Endpoint) HandleRouter(router *zallocrout.Router) {
+	e.HandleFallback(RouterHandler(router))
+}
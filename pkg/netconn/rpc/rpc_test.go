@@ -0,0 +1,355 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/netconn"
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+)
+
+// startPair 启动服务端并连接客户端
+func startPair(t *testing.T, protocol netconn.ProtocolType, port int, setup func(s *Server), clientCallback *netconn.BaseListenerCallback) (*Server, *Client) {
+	t.Helper()
+	server := NewServer(nil)
+	if setup != nil {
+		setup(server)
+	}
+	if err := server.Start(&netconn.ServerOption{Protocol: protocol, Addr: "127.0.0.1", Port: port}, nil); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Stop() })
+	time.Sleep(100 * time.Millisecond)
+
+	client := NewClient(nil, clientCallback)
+	if _, err := client.Connect(&netconn.ClientOption{Protocol: protocol, RemoteIP: "127.0.0.1", RemotePort: port}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return server, client
+}
+
+func registerEcho(s *Server) {
+	s.Handle("echo", func(ctx context.Context, req *Request) ([]byte, error) {
+		return append([]byte("echo:"), req.Payload...), nil
+	})
+	s.Handle("fail", func(ctx context.Context, req *Request) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+}
+
+func TestCallTCP(t *testing.T) {
+	_, client := startPair(t, netconn.ProtocolTCP, 18091, registerEcho, nil)
+	ctx := context.Background()
+
+	resp, err := client.Call(ctx, client.Fd(), "echo", []byte("hello"))
+	if err != nil || string(resp) != "echo:hello" {
+		t.Fatalf("Call(echo) = %q, %v", resp, err)
+	}
+
+	// 大于默认接收缓冲区的请求
+	large := strings.Repeat("x", 64*1024)
+	resp, err = client.Call(ctx, client.Fd(), "echo", []byte(large))
+	if err != nil || string(resp) != "echo:"+large {
+		t.Fatalf("Call(echo large) len = %d, %v", len(resp), err)
+	}
+
+	var rpcErr *Error
+	if _, err := client.Call(ctx, client.Fd(), "fail", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeHandlerError || rpcErr.Message != "boom" {
+		t.Errorf("Call(fail) error = %v, want handler error", err)
+	}
+	if _, err := client.Call(ctx, client.Fd(), "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("Call(missing) error = %v, want method not found", err)
+	}
+}
+
+func TestCallConcurrent(t *testing.T) {
+	_, client := startPair(t, netconn.ProtocolTCP, 18092, registerEcho, nil)
+
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		go func(i int) {
+			payload := strings.Repeat("a", i)
+			resp, err := client.Call(context.Background(), client.Fd(), "echo", []byte(payload))
+			if err == nil && string(resp) != "echo:"+payload {
+				err = errors.New("response mismatch: " + string(resp))
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCallDeadlineAndCancel(t *testing.T) {
+	handlerDone := make(chan error, 1)
+	deadlineSeen := make(chan bool, 1)
+	_, client := startPair(t, netconn.ProtocolTCP, 18093, func(s *Server) {
+		s.Handle("slow", func(ctx context.Context, req *Request) ([]byte, error) {
+			_, ok := ctx.Deadline()
+			deadlineSeen <- ok
+			<-ctx.Done()
+			handlerDone <- ctx.Err()
+			return nil, ctx.Err()
+		})
+	}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Call(ctx, client.Fd(), "slow", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Call error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Call returned after %v", elapsed)
+	}
+	if ok := <-deadlineSeen; !ok {
+		t.Error("handler context should carry the caller deadline")
+	}
+	select {
+	case <-handlerDone:
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not canceled")
+	}
+
+	// 调用方主动取消：对端收到取消消息
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-deadlineSeen
+		cancel()
+	}()
+	if _, err := client.Call(ctx, client.Fd(), "slow", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Call error = %v, want Canceled", err)
+	}
+	select {
+	case err := <-handlerDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("handler ctx error = %v, want Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancel message was not delivered")
+	}
+}
+
+func TestServerPush(t *testing.T) {
+	pushed := make(chan string, 4)
+	connected := make(chan int, 1)
+	server := NewServer(nil)
+	if err := server.Start(&netconn.ServerOption{Protocol: netconn.ProtocolTCP, Addr: "127.0.0.1", Port: 18094}, &netconn.BaseListenerCallback{
+		OnConnected: func(fd int, connType netconn.ConnectionType, connOpt *netconn.ConnectOption) {
+			if connType == netconn.ConnectionTypeClient {
+				connected <- fd
+			}
+		},
+	}); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() { _ = server.Stop() }()
+	time.Sleep(100 * time.Millisecond)
+
+	client := NewClient(nil, nil)
+	client.Handle("news", func(ctx context.Context, req *Request) ([]byte, error) {
+		if !req.IsNotify {
+			t.Error("expected notification")
+		}
+		pushed <- string(req.Payload)
+		return nil, nil
+	})
+	client.Handle("whoami", func(ctx context.Context, req *Request) ([]byte, error) {
+		return []byte("client"), nil
+	})
+	if _, err := client.Connect(&netconn.ClientOption{Protocol: netconn.ProtocolTCP, RemoteIP: "127.0.0.1", RemotePort: 18094}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	fd := <-connected
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := server.Notify(fd, "news", []byte(msg)); err != nil {
+			t.Fatalf("Notify error = %v", err)
+		}
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		select {
+		case got := <-pushed:
+			if got != want {
+				t.Errorf("push = %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for push")
+		}
+	}
+
+	// 服务端也可以调用客户端
+	resp, err := server.Call(context.Background(), fd, "whoami", nil)
+	if err != nil || string(resp) != "client" {
+		t.Errorf("server Call = %q, %v", resp, err)
+	}
+}
+
+func TestCallFailsOnDisconnect(t *testing.T) {
+	release := make(chan struct{})
+	_, client := startPair(t, netconn.ProtocolTCP, 18095, func(s *Server) {
+		s.Handle("hang", func(ctx context.Context, req *Request) ([]byte, error) {
+			<-release
+			return nil, nil
+		})
+	}, nil)
+	defer close(release)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.Call(context.Background(), client.Fd(), "hang", nil)
+		errc <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	client.Close()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Call error = %v, want ErrClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("pending call not released on disconnect")
+	}
+}
+
+func TestRouterHandler(t *testing.T) {
+	router := zallocrout.NewRouter()
+	_ = router.AddRoute(RouterMethod, "/user/:id/profile", func(ctx context.Context) error {
+		req, ok := RequestFromContext(ctx)
+		if !ok {
+			return errors.New("request missing")
+		}
+		id, _ := zallocrout.GetParam(ctx, "id")
+		req.SetResponse([]byte("profile:" + id + ":" + string(req.Payload)))
+		return nil
+	})
+
+	_, client := startPair(t, netconn.ProtocolTCP, 18096, func(s *Server) {
+		s.HandleRouter(router)
+		s.Handle("ping", func(ctx context.Context, req *Request) ([]byte, error) {
+			return []byte("pong"), nil
+		})
+	}, nil)
+
+	ctx := context.Background()
+	resp, err := client.Call(ctx, client.Fd(), "/user/42/profile", []byte("full"))
+	if err != nil || string(resp) != "profile:42:full" {
+		t.Errorf("Call(router) = %q, %v", resp, err)
+	}
+	if resp, err := client.Call(ctx, client.Fd(), "ping", nil); err != nil || string(resp) != "pong" {
+		t.Errorf("Call(ping) = %q, %v", resp, err)
+	}
+	var rpcErr *Error
+	if _, err := client.Call(ctx, client.Fd(), "/unknown", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("Call(/unknown) error = %v, want method not found", err)
+	}
+}
+
+func TestCallFILLP(t *testing.T) {
+	_, client := startPair(t, netconn.ProtocolUDP, 18097, registerEcho, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	resp, err := client.Call(ctx, client.Fd(), "echo", []byte("over fillp"))
+	if err != nil || string(resp) != "echo:over fillp" {
+		t.Fatalf("Call over FILLP = %q, %v", resp, err)
+	}
+}
+
+func TestEndpointResetConcurrent(t *testing.T) {
+	newFramer := func() *netconn.BinpackHeaderFramer {
+		framer, err := NewFramer(0)
+		if err != nil {
+			t.Fatalf("NewFramer error: %v", err)
+		}
+		return framer
+	}
+
+	var received atomic.Int32
+	peer := NewEndpoint(newFramer(), func(fd int, frame []byte) error { return nil })
+	peer.Handle("ping", func(ctx context.Context, req *Request) ([]byte, error) {
+		received.Add(1)
+		return nil, nil
+	})
+	e := NewEndpoint(newFramer(), func(fd int, frame []byte) error {
+		peer.HandleMessage(fd, frame)
+		return nil
+	})
+
+	// 传输层重新启动替换帧编解码器时，收发可能仍在进行
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			e.reset(newFramer())
+			peer.reset(newFramer())
+		}
+	}()
+	const n = 200
+	for i := 0; i < n; i++ {
+		if err := e.Notify(1, "ping", nil); err != nil {
+			t.Fatalf("Notify error: %v", err)
+		}
+	}
+	<-done
+
+	if got := received.Load(); got != n {
+		t.Errorf("received %d notifications, want %d", got, n)
+	}
+}
+
+func TestEndpointCancelImmediatelyAfterRequest(t *testing.T) {
+	framer, err := NewFramer(0)
+	if err != nil {
+		t.Fatalf("NewFramer error: %v", err)
+	}
+
+	var frames [][]byte
+	caller := NewEndpoint(framer, func(fd int, frame []byte) error {
+		frames = append(frames, append([]byte(nil), frame...))
+		return nil
+	})
+	replies := make(chan *message, 1)
+	e := NewEndpoint(framer, func(fd int, frame []byte) error {
+		m, err := caller.parse(frame)
+		if err == nil {
+			replies <- m
+		}
+		return nil
+	})
+	e.Handle("block", func(ctx context.Context, req *Request) ([]byte, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(2 * time.Second):
+			return []byte("finished"), nil
+		}
+	})
+
+	_ = caller.sendFrame(1, &frameHeader{Type: uint8(TypeRequest), ID: 7}, "block", nil)
+	_ = caller.sendFrame(1, &frameHeader{Type: uint8(TypeCancel), ID: 7}, "", nil)
+
+	// 同一读协程上请求之后紧跟取消帧，取消不能丢失
+	for _, frame := range frames {
+		e.HandleMessage(1, frame)
+	}
+
+	select {
+	case m := <-replies:
+		if MessageType(m.header.Type) != TypeError || m.header.Code != CodeCanceled {
+			t.Errorf("reply type=%d code=%d, want canceled error", m.header.Type, m.header.Code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not canceled")
+	}
+}
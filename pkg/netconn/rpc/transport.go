@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"github.com/junbin-yang/go-kitbox/pkg/netconn"
+)
+
+// Server 基于 netconn.BaseServer 的RPC服务端，支持 TCP 和 UDP（FILLP）
+type Server struct {
+	*Endpoint
+	server *netconn.BaseServer
+}
+
+// NewServer 创建RPC服务端，connMgr 为 nil 时自动创建
+func NewServer(connMgr *netconn.ConnectionManager) *Server {
+	s := &Server{server: netconn.NewBaseServer(connMgr)}
+	s.Endpoint = NewEndpoint(nil, s.server.SendMessage)
+	return s
+}
+
+// Start 启动监听，opt.Framer 由RPC层设置；callback 可为 nil，其 OnMessage 不会被调用
+func (s *Server) Start(opt *netconn.ServerOption, callback *netconn.BaseListenerCallback) error {
+	framer, err := NewFramer(opt.MaxFrameSize)
+	if err != nil {
+		return err
+	}
+	s.Endpoint.reset(framer)
+
+	serverOpt := *opt
+	serverOpt.Framer = framer
+	return s.server.StartBaseListener(&serverOpt, wrapCallback(s.Endpoint, callback))
+}
+
+// Stop 停止服务端，等待中的调用返回 ErrClosed
+func (s *Server) Stop() error {
+	s.Endpoint.Close()
+	return s.server.StopBaseListener()
+}
+
+// BaseServer 返回底层服务器
+func (s *Server) BaseServer() *netconn.BaseServer {
+	return s.server
+}
+
+// Client 基于 netconn.BaseClient 的RPC客户端，支持 TCP 和 UDP（FILLP）
+type Client struct {
+	*Endpoint
+	client *netconn.BaseClient
+}
+
+// NewClient 创建RPC客户端，connMgr 为 nil 时自动创建；callback 可为 nil，其 OnMessage 不会被调用
+func NewClient(connMgr *netconn.ConnectionManager, callback *netconn.BaseListenerCallback) *Client {
+	c := &Client{}
+	c.Endpoint = NewEndpoint(nil, func(fd int, frame []byte) error {
+		return c.client.SendMessage(frame)
+	})
+	c.client = netconn.NewBaseClient(connMgr, wrapCallback(c.Endpoint, callback))
+	return c
+}
+
+// Connect 连接服务端，返回虚拟fd，opt.Framer 由RPC层设置
+func (c *Client) Connect(opt *netconn.ClientOption) (int, error) {
+	framer, err := NewFramer(opt.MaxFrameSize)
+	if err != nil {
+		return -1, err
+	}
+	c.Endpoint.reset(framer)
+
+	clientOpt := *opt
+	clientOpt.Framer = framer
+	return c.client.Connect(&clientOpt)
+}
+
+// Close 关闭连接，等待中的调用返回 ErrClosed
+func (c *Client) Close() {
+	c.client.Close()
+	c.Endpoint.ConnClosed(c.client.GetFd())
+}
+
+// Fd 返回连接的虚拟fd
+func (c *Client) Fd() int {
+	return c.client.GetFd()
+}
+
+// BaseClient 返回底层客户端
+func (c *Client) BaseClient() *netconn.BaseClient {
+	return c.client
+}
+
+// wrapCallback 将收到的消息交给端点处理，连接断开时取消该连接上的调用
+func wrapCallback(e *Endpoint, callback *netconn.BaseListenerCallback) *netconn.BaseListenerCallback {
+	wrapped := &netconn.BaseListenerCallback{}
+	if callback != nil {
+		*wrapped = *callback
+	}
+
+	onDisconnected := wrapped.OnDisconnected
+	wrapped.OnDisconnected = func(fd int, connType netconn.ConnectionType) {
+		e.ConnClosed(fd)
+		if onDisconnected != nil {
+			onDisconnected(fd, connType)
+		}
+	}
+	wrapped.OnDataReceived = nil
+	wrapped.OnMessage = e.HandleMessage
+	return wrapped
+}
@@ -1,14 +1,20 @@
 package netconn
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"sync"
 
 	"github.com/junbin-yang/go-kitbox/pkg/fillp"
+	"github.com/junbin-yang/go-kitbox/pkg/lifecycle"
 )
 
+// ErrServerShutdown Shutdown 到期时强制关闭连接的原因
+var ErrServerShutdown = errors.New("server shutdown")
+
 // BaseServer 统一的服务器
 type BaseServer struct {
 	connMgr      *ConnectionManager
@@ -17,11 +23,21 @@ type BaseServer struct {
 	listener     net.Listener // TCP监听器
 	addr         string
 	port         int
+	framer       Framer
+	maxFrameSize int
+	tlsConfig    *tls.Config
+	wsPath       string
+	writeQueue   *WriteQueueOption
+	heartbeat    *HeartbeatOption
+	goodbye      []byte
+	admission    *admission
+	metrics      *serverMetrics
 	running      bool
 	mu           sync.RWMutex
 	stopChan     chan struct{}
 	connHandlers map[int]chan struct{} // 每个连接的停止通道
 	handlerMu    sync.Mutex
+	handlerWG    sync.WaitGroup // 连接处理协程（含握手阶段）
 }
 
 // NewBaseServer 创建服务器实例
@@ -32,6 +48,7 @@ func NewBaseServer(connMgr *ConnectionManager) *BaseServer {
 	return &BaseServer{
 		connMgr:      connMgr,
 		connHandlers: make(map[int]chan struct{}),
+		metrics:      &serverMetrics{},
 	}
 }
 
@@ -48,13 +65,38 @@ func (s *BaseServer) StartBaseListener(opt *ServerOption, callback *BaseListener
 	s.protocol = opt.Protocol
 	s.addr = opt.Addr
 	s.port = opt.Port
+	s.framer = opt.Framer
+	s.maxFrameSize = opt.MaxFrameSize
+	s.tlsConfig = opt.TLSConfig
+	s.writeQueue = opt.WriteQueue
+	s.heartbeat = opt.Heartbeat
+	s.goodbye = opt.Goodbye
+	s.wsPath = opt.WebSocketPath
+	if s.wsPath == "" {
+		s.wsPath = DefaultWebSocketPath
+	}
+
+	if opt.TLSConfig != nil && !opt.Protocol.supportsTLS() {
+		return errTLSRequiresTCP
+	}
+	admission, err := newAdmission(opt.Admission)
+	if err != nil {
+		return err
+	}
+	s.admission = admission
 	s.stopChan = make(chan struct{})
 
-	var err error
-	if opt.Protocol == ProtocolTCP {
-		err = s.startTCPListener(opt.Addr, opt.Port)
-	} else {
+	switch opt.Protocol {
+	case ProtocolTCP, ProtocolWebSocket:
+		err = s.startStreamListener("tcp", fmt.Sprintf("%s:%d", opt.Addr, opt.Port))
+	case ProtocolUDP:
 		err = s.startUDPListener(opt.Addr, opt.Port)
+	case ProtocolUnix, ProtocolUnixPacket:
+		network := unixNetwork(opt.Protocol)
+		removeStaleUnixSocket(network, opt.Addr)
+		err = s.startStreamListener(network, opt.Addr)
+	default:
+		err = errors.New("unsupported protocol")
 	}
 
 	if err != nil {
@@ -65,9 +107,9 @@ func (s *BaseServer) StartBaseListener(opt *ServerOption, callback *BaseListener
 	return nil
 }
 
-// startTCPListener 启动TCP监听
-func (s *BaseServer) startTCPListener(addr string, port int) error {
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", addr, port))
+// startStreamListener 启动面向流的监听（TCP、WebSocket、Unix域套接字）
+func (s *BaseServer) startStreamListener(network, address string) error {
+	listener, err := net.Listen(network, address)
 	if err != nil {
 		return err
 	}
@@ -77,14 +119,14 @@ func (s *BaseServer) startTCPListener(addr string, port int) error {
 	// 触发服务端监听回调
 	if s.callback != nil && s.callback.OnConnected != nil {
 		connOpt := &ConnectOption{
-			Protocol:     ProtocolTCP,
-			LocalSocket:  &SocketOption{Addr: addr, Port: port},
+			Protocol:     s.protocol,
+			LocalSocket:  &SocketOption{Addr: s.addr, Port: s.port},
 			RemoteSocket: &SocketOption{},
 		}
 		s.callback.OnConnected(0, ConnectionTypeServer, connOpt)
 	}
 
-	go s.acceptTCPLoop()
+	go s.acceptStreamLoop()
 	return nil
 }
 
@@ -129,12 +171,26 @@ func (s *BaseServer) acceptUDPLoop(localAddr *net.UDPAddr) {
 			continue
 		}
 
-		go s.handleUDPConnection(conn)
+		remote := conn.RemoteAddr()
+		if !s.admit(remote) {
+			conn.Close()
+			continue
+		}
+		if !s.startHandler() {
+			s.release(remote)
+			conn.Close()
+			return
+		}
+		go func() {
+			defer s.handlerWG.Done()
+			defer s.release(remote)
+			s.handleUDPConnection(conn)
+		}()
 	}
 }
 
-// acceptTCPLoop TCP接受连接循环
-func (s *BaseServer) acceptTCPLoop() {
+// acceptStreamLoop 面向流的接受连接循环
+func (s *BaseServer) acceptStreamLoop() {
 	for {
 		select {
 		case <-s.stopChan:
@@ -152,73 +208,102 @@ func (s *BaseServer) acceptTCPLoop() {
 			}
 		}
 
-		go s.handleTCPConnection(conn)
+		remote := conn.RemoteAddr()
+		if !s.admit(remote) {
+			conn.Close()
+			continue
+		}
+		if !s.startHandler() {
+			s.release(remote)
+			conn.Close()
+			return
+		}
+		go func() {
+			defer s.handlerWG.Done()
+			defer s.release(remote)
+			s.handleStreamConnection(conn)
+		}()
 	}
 }
 
-// handleTCPConnection 处理TCP连接
-func (s *BaseServer) handleTCPConnection(conn net.Conn) {
-	tcpConn := NewTCPConnection(conn)
-	fd := s.connMgr.RegisterConn(tcpConn, ConnectionTypeClient)
-	defer s.connMgr.UnregisterConn(fd)
-
-	// 触发客户端连接回调
-	if s.callback != nil && s.callback.OnConnected != nil {
-		connOpt := s.connMgr.GetConnInfo(fd)
-		s.callback.OnConnected(fd, ConnectionTypeClient, connOpt)
+// admit 准入检查并记录指标
+func (s *BaseServer) admit(remote net.Addr) bool {
+	if s.admission != nil {
+		if reason := s.admission.admit(remote); reason != RejectNone {
+			s.metrics.recordReject(reason)
+			return false
+		}
 	}
+	s.metrics.recordAccept()
+	return true
+}
 
-	// 创建停止通道
-	stopChan := make(chan struct{})
+// startHandler 登记连接处理协程，服务器已停止时返回 false
+func (s *BaseServer) startHandler() bool {
 	s.handlerMu.Lock()
-	s.connHandlers[fd] = stopChan
-	s.handlerMu.Unlock()
-
-	defer func() {
-		s.handlerMu.Lock()
-		delete(s.connHandlers, fd)
-		s.handlerMu.Unlock()
-
-		if s.callback != nil && s.callback.OnDisconnected != nil {
-			s.callback.OnDisconnected(fd, ConnectionTypeClient)
-		}
-	}()
+	defer s.handlerMu.Unlock()
+	if isClosed(s.stopChan) {
+		return false
+	}
+	s.handlerWG.Add(1)
+	return true
+}
 
-	// 接收数据循环
-	buf := make([]byte, DefaultBufSize)
-	offset := 0
+// release 连接结束后释放准入计数
+func (s *BaseServer) release(remote net.Addr) {
+	if s.admission != nil {
+		s.admission.release(remote)
+	}
+	s.metrics.recordRelease()
+}
 
-	for {
-		select {
-		case <-stopChan:
+// handleStreamConnection 按协议完成TLS握手、WebSocket升级后处理连接
+func (s *BaseServer) handleStreamConnection(conn net.Conn) {
+	if s.tlsConfig != nil {
+		tlsConn := tls.Server(conn, s.tlsConfig)
+		// 握手失败（如客户端证书校验不通过）的连接不触发回调
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultHandshakeTimeout)
+		err := tlsConn.HandshakeContext(ctx)
+		cancel()
+		if err != nil {
+			tlsConn.Close()
 			return
-		default:
 		}
+		conn = tlsConn
+	}
 
-		n, err := conn.Read(buf[offset:])
+	switch s.protocol {
+	case ProtocolWebSocket:
+		wsConn, err := acceptWebSocket(conn, s.wsPath)
 		if err != nil {
+			conn.Close()
 			return
 		}
-
-		offset += n
-		if s.callback != nil && s.callback.OnDataReceived != nil {
-			processed := s.callback.OnDataReceived(fd, ConnectionTypeServer, buf, offset)
-			if processed < 0 {
-				return
-			}
-			if processed > 0 {
-				copy(buf, buf[processed:offset])
-				offset -= processed
-			}
-		}
+		s.handleConnection(wsConn)
+	case ProtocolUnix, ProtocolUnixPacket:
+		s.handleConnection(NewUnixConnection(conn.(*net.UnixConn), s.protocol == ProtocolUnixPacket))
+	default:
+		s.handleConnection(NewTCPConnection(conn))
 	}
 }
 
 // handleUDPConnection 处理UDP/FILLP连接
 func (s *BaseServer) handleUDPConnection(conn *fillp.Connection) {
-	udpConn := NewUDPConnection(conn)
-	fd := s.connMgr.RegisterConn(udpConn, ConnectionTypeClient)
+	s.handleConnection(NewUDPConnection(conn))
+}
+
+// handleConnection 注册连接并运行接收循环
+func (s *BaseServer) handleConnection(conn NetConnection) {
+	fd := s.connMgr.RegisterConn(conn, ConnectionTypeClient)
 	defer s.connMgr.UnregisterConn(fd)
+	state := s.connMgr.getState(fd)
+
+	if s.framer != nil {
+		_ = s.connMgr.SetFramer(fd, s.framer)
+	}
+	if s.writeQueue != nil {
+		_ = s.connMgr.EnableWriteQueue(fd, s.writeQueue, s.callback)
+	}
 
 	// 触发客户端连接回调
 	if s.callback != nil && s.callback.OnConnected != nil {
@@ -232,45 +317,40 @@ func (s *BaseServer) handleUDPConnection(conn *fillp.Connection) {
 	s.connHandlers[fd] = stopChan
 	s.handlerMu.Unlock()
 
+	var reason error
 	defer func() {
 		s.handlerMu.Lock()
 		delete(s.connHandlers, fd)
+		s.metrics.recordClosed(state)
 		s.handlerMu.Unlock()
 
 		if s.callback != nil && s.callback.OnDisconnected != nil {
 			s.callback.OnDisconnected(fd, ConnectionTypeClient)
 		}
-	}()
-
-	buf := make([]byte, DefaultBufSize)
-	offset := 0
-
-	for {
-		select {
-		case <-stopChan:
-			return
-		default:
-		}
-
-		data, err := conn.Receive()
-		if err != nil {
-			return
+		if s.callback != nil && s.callback.OnClosed != nil {
+			s.callback.OnClosed(fd, ConnectionTypeClient, reason)
 		}
+	}()
 
-		copy(buf[offset:], data)
-		offset += len(data)
+	hb := newHeartbeat(s.connMgr, fd, s.heartbeat)
+	reader := &connReader{
+		conn:         conn,
+		fd:           fd,
+		connType:     ConnectionTypeServer,
+		callback:     s.callback,
+		framer:       s.framer,
+		maxFrameSize: s.maxFrameSize,
+		state:        state,
+		heartbeat:    hb,
+	}
 
-		if s.callback != nil && s.callback.OnDataReceived != nil {
-			processed := s.callback.OnDataReceived(fd, ConnectionTypeServer, buf, offset)
-			if processed < 0 {
-				return
-			}
-			if processed > 0 {
-				copy(buf, buf[processed:offset])
-				offset -= processed
-			}
-		}
+	done := make(chan struct{})
+	if hb != nil {
+		go hb.run(done)
 	}
+	err := reader.run(stopChan)
+	close(done)
+	reason = s.connMgr.closeReason(fd, err)
 }
 
 // StopBaseListener 停止服务器
@@ -292,7 +372,7 @@ func (s *BaseServer) StopBaseListener() error {
 	s.connHandlers = make(map[int]chan struct{})
 	s.handlerMu.Unlock()
 
-	if s.protocol == ProtocolTCP && s.listener != nil {
+	if s.listener != nil {
 		s.listener.Close()
 	}
 
@@ -300,11 +380,126 @@ func (s *BaseServer) StopBaseListener() error {
 	return nil
 }
 
+// Shutdown 优雅关闭服务器：停止接受新连接，向现有连接发送 Goodbye 消息，等待连接处理结束（对端断开或业务关闭连接）
+// ctx 到期时以 ErrServerShutdown 为原因强制关闭剩余连接并返回 ctx.Err()
+func (s *BaseServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.stopChan)
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.running = false
+	goodbye := s.goodbye
+	s.mu.Unlock()
+
+	// stopChan 关闭后不再登记新的处理协程，此后可以安全等待
+	fds := s.handlerFds()
+	if goodbye != nil {
+		for _, fd := range fds {
+			if _, ok := s.connMgr.GetFramer(fd); ok {
+				_ = s.connMgr.SendMessage(fd, goodbye)
+			} else {
+				_ = s.connMgr.SendBytes(fd, goodbye)
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.handlerWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	for _, fd := range s.handlerFds() {
+		_ = s.connMgr.CloseWithReason(fd, ErrServerShutdown)
+	}
+	return ctx.Err()
+}
+
+// handlerFds 返回正在处理的连接fd
+func (s *BaseServer) handlerFds() []int {
+	s.handlerMu.Lock()
+	defer s.handlerMu.Unlock()
+	fds := make([]int, 0, len(s.connHandlers))
+	for fd := range s.connHandlers {
+		fds = append(fds, fd)
+	}
+	return fds
+}
+
+// LifecycleWorker 返回可注册到 lifecycle.Manager 的服务器协程：运行时启动监听，退出时在管理器的超时时间内调用 Shutdown
+//
+//	run, stop := server.LifecycleWorker(opt, callback)
+//	manager.AddWorker("netconn", run, stop)
+func (s *BaseServer) LifecycleWorker(opt *ServerOption, callback *BaseListenerCallback) (lifecycle.RunFunc, lifecycle.WorkerOption) {
+	stopped := make(chan struct{})
+	var once sync.Once
+	run := func(ctx context.Context) error {
+		if err := s.StartBaseListener(opt, callback); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		// 等待停止函数完成排空，避免协程提前退出后不再调用停止函数
+		<-stopped
+		return nil
+	}
+	stop := func(ctx context.Context) error {
+		defer once.Do(func() { close(stopped) })
+		return s.Shutdown(ctx)
+	}
+	return run, lifecycle.WithStopFunc(stop)
+}
+
 // SendBytes 向指定fd发送数据
 func (s *BaseServer) SendBytes(fd int, data []byte) error {
 	return s.connMgr.SendBytes(fd, data)
 }
 
+// SendMessage 使用帧编解码器编码后向指定fd发送消息
+func (s *BaseServer) SendMessage(fd int, msg []byte) error {
+	return s.connMgr.SendMessage(fd, msg)
+}
+
+// Broadcast 向分组内所有连接异步发送数据，返回成功入队的连接数
+func (s *BaseServer) Broadcast(group string, data []byte) int {
+	return s.connMgr.Broadcast(group, data)
+}
+
+// BroadcastMessage 使用帧编解码器编码后向分组广播消息，返回成功入队的连接数
+func (s *BaseServer) BroadcastMessage(group string, msg []byte) int {
+	return s.connMgr.BroadcastMessage(group, msg)
+}
+
+// ConnManager 返回服务器使用的连接管理器，可用于设置连接属性和分组
+func (s *BaseServer) ConnManager() *ConnectionManager {
+	return s.connMgr
+}
+
+// GetMetrics 获取服务器指标快照，流量统计包含已关闭的连接
+func (s *BaseServer) GetMetrics() *ServerMetrics {
+	s.handlerMu.Lock()
+	defer s.handlerMu.Unlock()
+	live := make([]*connState, 0, len(s.connHandlers))
+	for fd := range s.connHandlers {
+		live = append(live, s.connMgr.getState(fd))
+	}
+	return s.metrics.snapshot(live)
+}
+
+// GetConnMetrics 获取连接的指标快照，连接不存在时返回nil
+func (s *BaseServer) GetConnMetrics(fd int) *ConnMetrics {
+	return s.connMgr.GetConnMetrics(fd)
+}
+
 // GetConnInfo 获取连接信息
 func (s *BaseServer) GetConnInfo(fd int) *ConnectOption {
 	return s.connMgr.GetConnInfo(fd)
@@ -0,0 +1,216 @@
+package netconn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junbin-yang/go-kitbox/pkg/lifecycle"
+)
+
+var testGoodbye = []byte("goodbye")
+
+// startGoodbyeServer 启动发送告别消息的回显服务端，返回服务端和连接关闭原因
+func startGoodbyeServer(t *testing.T, port int) (*BaseServer, chan error) {
+	t.Helper()
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	reasons := make(chan error, 4)
+	server := NewBaseServer(nil)
+	err := server.StartBaseListener(&ServerOption{
+		Protocol: ProtocolTCP,
+		Addr:     "127.0.0.1",
+		Port:     port,
+		Framer:   framer,
+		Goodbye:  testGoodbye,
+	}, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			_ = server.SendMessage(fd, msg)
+		},
+		OnClosed: func(fd int, connType ConnectionType, reason error) {
+			reasons <- reason
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	return server, reasons
+}
+
+// connectGoodbyeClient 连接服务端，closeOnGoodbye 为 true 时收到告别消息后主动断开
+func connectGoodbyeClient(t *testing.T, port int, closeOnGoodbye bool) (*BaseClient, chan struct{}) {
+	t.Helper()
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	gotGoodbye := make(chan struct{})
+	var client *BaseClient
+	client = NewBaseClient(nil, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			if string(msg) == string(testGoodbye) {
+				close(gotGoodbye)
+				if closeOnGoodbye {
+					go client.Close()
+				}
+			}
+		},
+	})
+	if _, err := client.Connect(&ClientOption{Protocol: ProtocolTCP, RemoteIP: "127.0.0.1", RemotePort: port, Framer: framer}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client, gotGoodbye
+}
+
+func TestShutdownDrain(t *testing.T) {
+	server, reasons := startGoodbyeServer(t, 18107)
+	_, gotGoodbye := connectGoodbyeClient(t, 18107, true)
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown error = %v", err)
+	}
+	select {
+	case <-gotGoodbye:
+	default:
+		t.Error("client should receive goodbye before shutdown completes")
+	}
+	// 对端主动断开，不是强制关闭
+	if reason := <-reasons; errors.Is(reason, ErrServerShutdown) {
+		t.Errorf("close reason = %v, want peer close", reason)
+	}
+
+	// 已停止接受新连接
+	client := NewBaseClient(nil, nil)
+	if _, err := client.ConnectSimple(ProtocolTCP, "127.0.0.1", 18107); err == nil {
+		client.Close()
+		t.Error("connect after Shutdown should fail")
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown error = %v", err)
+	}
+}
+
+func TestShutdownForceClose(t *testing.T) {
+	server, reasons := startGoodbyeServer(t, 18108)
+	_, gotGoodbye := connectGoodbyeClient(t, 18108, false)
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %v", elapsed)
+	}
+	<-gotGoodbye
+
+	select {
+	case reason := <-reasons:
+		if !errors.Is(reason, ErrServerShutdown) {
+			t.Errorf("close reason = %v, want ErrServerShutdown", reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection should be force closed")
+	}
+}
+
+func TestConnMetrics(t *testing.T) {
+	server, reasons := startGoodbyeServer(t, 18109)
+	defer server.StopBaseListener()
+
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	echoes := make(chan struct{}, 3)
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			echoes <- struct{}{}
+		},
+	})
+	_, err := client.Connect(&ClientOption{Protocol: ProtocolTCP, RemoteIP: "127.0.0.1", RemotePort: 18109, Framer: framer})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	for _, msg := range []string{"a", "bb", "ccc"} {
+		if err := client.SendMessage([]byte(msg)); err != nil {
+			t.Fatalf("SendMessage error = %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-echoes:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for echo")
+		}
+	}
+
+	// 3条消息，各带4字节长度前缀
+	const wireBytes = 3*4 + 6
+	m := client.GetConnMetrics()
+	if m == nil {
+		t.Fatal("GetConnMetrics returned nil")
+	}
+	if m.MessagesOut != 3 || m.MessagesIn != 3 || m.BytesOut != wireBytes || m.BytesIn != wireBytes || m.Errors != 0 {
+		t.Errorf("client metrics = %+v", m)
+	}
+	if m.Duration <= 0 || m.ConnectedAt.IsZero() {
+		t.Errorf("client duration = %v, connectedAt = %v", m.Duration, m.ConnectedAt)
+	}
+
+	sm := server.GetMetrics()
+	if sm.MessagesIn != 3 || sm.MessagesOut != 3 || sm.BytesIn != wireBytes || sm.BytesOut != wireBytes {
+		t.Errorf("server metrics = %+v", sm)
+	}
+
+	// 连接关闭后计数仍保留在聚合指标中
+	client.Close()
+	<-reasons
+	sm = server.GetMetrics()
+	if sm.MessagesIn != 3 || sm.BytesIn != wireBytes || sm.ConnDuration <= 0 {
+		t.Errorf("server metrics after close = %+v", sm)
+	}
+	if client.GetConnMetrics() != nil {
+		t.Error("GetConnMetrics should return nil after Close")
+	}
+}
+
+func TestLifecycleWorker(t *testing.T) {
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	server := NewBaseServer(nil)
+	run, stop := server.LifecycleWorker(&ServerOption{
+		Protocol: ProtocolTCP,
+		Addr:     "127.0.0.1",
+		Port:     18110,
+		Framer:   framer,
+		Goodbye:  testGoodbye,
+	}, nil)
+
+	m := lifecycle.NewManager(lifecycle.WithShutdownTimeout(2 * time.Second))
+	if err := m.AddWorker("netconn", run, stop); err != nil {
+		t.Fatalf("AddWorker error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Run()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	_, gotGoodbye := connectGoodbyeClient(t, 18110, true)
+	time.Sleep(100 * time.Millisecond)
+	if server.GetMetrics().ActiveConns != 1 {
+		t.Fatalf("server should be serving, metrics = %+v", server.GetMetrics())
+	}
+
+	if err := m.Shutdown(); err != nil {
+		t.Errorf("manager Shutdown error = %v", err)
+	}
+	<-done
+	<-gotGoodbye
+	if server.GetPort() != -1 {
+		t.Error("server should be stopped")
+	}
+}
@@ -0,0 +1,232 @@
+package netconn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultCertReloadDebounce 证书文件变化的防抖间隔
+const DefaultCertReloadDebounce = 100 * time.Millisecond
+
+// CertReloader 从文件加载证书，并在文件变化时自动重新加载
+// 将 GetCertificate / GetClientCertificate 设置到 tls.Config 后，新连接即使用最新证书
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	mu       sync.RWMutex
+
+	watcher   *fsnotify.Watcher
+	watchQuit chan struct{}
+	watching  bool
+	watchMu   sync.Mutex // 保护 watcher、watchQuit、watching
+}
+
+// NewCertReloader 加载证书和私钥文件
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: filepath.Clean(certFile),
+		keyFile:  filepath.Clean(keyFile),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新加载证书，失败时保留原证书
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair failed: %w", err)
+	}
+	if len(cert.Certificate) > 0 && cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return fmt.Errorf("parse certificate failed: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// Certificate 返回当前证书
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// GetCertificate 用于 tls.Config.GetCertificate（服务端）
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate 用于 tls.Config.GetClientCertificate（客户端双向认证）
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// Watch 监听证书文件变化并自动重新加载，onReload 在每次重新加载后以结果调用（可为nil）
+// 已在监听时直接返回；Close 之后可以再次调用
+func (r *CertReloader) Watch(onReload func(err error)) error {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	if r.watching {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher failed: %w", err)
+	}
+
+	// 监听所在目录，以便感知原子替换（重命名）方式的更新
+	dirs := map[string]struct{}{filepath.Dir(r.certFile): {}, filepath.Dir(r.keyFile): {}}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("add watch path failed: %w", err)
+		}
+	}
+
+	r.watcher = watcher
+	r.watchQuit = make(chan struct{})
+	r.watching = true
+	go r.watchLoop(r.watcher, r.watchQuit, onReload)
+	return nil
+}
+
+// Close 停止监听证书文件，可重复调用
+func (r *CertReloader) Close() error {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	if !r.watching {
+		return nil
+	}
+	close(r.watchQuit)
+	r.watching = false
+	r.watchQuit = nil
+	watcher := r.watcher
+	r.watcher = nil
+	return watcher.Close()
+}
+
+// watchLoop 监听文件变化循环
+func (r *CertReloader) watchLoop(watcher *fsnotify.Watcher, quit chan struct{}, onReload func(err error)) {
+	debounceTimer := time.NewTimer(0)
+	if !debounceTimer.Stop() {
+		<-debounceTimer.C
+	}
+	defer debounceTimer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if name != r.certFile && name != r.keyFile {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounceTimer.Reset(DefaultCertReloadDebounce)
+			}
+
+		case <-debounceTimer.C:
+			err := r.Reload()
+			if onReload != nil {
+				onReload(err)
+			}
+
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+		case <-quit:
+			return
+		}
+	}
+}
+
+// SNICertificates 按 SNI 服务器名选择证书，支持 "*.example.com" 形式的通配符
+type SNICertificates struct {
+	certs    map[string]*CertReloader
+	fallback *CertReloader
+	mu       sync.RWMutex
+}
+
+// NewSNICertificates 创建 SNI 证书选择器，fallback 用于未匹配或未携带 SNI 的握手（可为nil）
+func NewSNICertificates(fallback *CertReloader) *SNICertificates {
+	return &SNICertificates{
+		certs:    make(map[string]*CertReloader),
+		fallback: fallback,
+	}
+}
+
+// Add 为服务器名注册证书
+func (s *SNICertificates) Add(serverName string, reloader *CertReloader) {
+	s.mu.Lock()
+	s.certs[strings.ToLower(serverName)] = reloader
+	s.mu.Unlock()
+}
+
+// Remove 移除服务器名对应的证书
+func (s *SNICertificates) Remove(serverName string) {
+	s.mu.Lock()
+	delete(s.certs, strings.ToLower(serverName))
+	s.mu.Unlock()
+}
+
+// GetCertificate 用于 tls.Config.GetCertificate
+func (s *SNICertificates) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+
+	s.mu.RLock()
+	reloader, ok := s.certs[name]
+	if !ok {
+		if idx := strings.IndexByte(name, '.'); idx > 0 {
+			reloader, ok = s.certs["*"+name[idx:]]
+		}
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		reloader = s.fallback
+	}
+	if reloader == nil {
+		return nil, fmt.Errorf("no certificate for server name %q", hello.ServerName)
+	}
+	return reloader.Certificate(), nil
+}
+
+// LoadCertPool 从 PEM 文件加载 CA 证书池，用于 ClientCAs / RootCAs
+func LoadCertPool(caFiles ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range caFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file failed: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// errTLSRequiresTCP TLS 仅支持 TCP 和 WebSocket 协议
+var errTLSRequiresTCP = errors.New("tls is only supported over tcp and websocket")
@@ -0,0 +1,318 @@
+package netconn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testCA 测试用证书签发机构
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate error = %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发证书并写入 dir 下的 name.crt / name.key
+func (ca *testCA) issue(t *testing.T, dir, name, commonName string, serial int64, dnsNames ...string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate error = %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, caFile, ca.pem)
+	serverCert, serverKey := ca.issue(t, dir, "server", "server", 2)
+	clientCert, clientKey := ca.issue(t, dir, "client", "user-42", 3)
+
+	pool, err := LoadCertPool(caFile)
+	if err != nil {
+		t.Fatalf("LoadCertPool error = %v", err)
+	}
+	serverReloader, err := NewCertReloader(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("NewCertReloader error = %v", err)
+	}
+	clientReloader, err := NewCertReloader(clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("NewCertReloader error = %v", err)
+	}
+
+	server := NewBaseServer(nil)
+	identities := make(chan string, 1)
+	serverCallback := &BaseListenerCallback{
+		OnConnected: func(fd int, connType ConnectionType, connOpt *ConnectOption) {
+			if connType == ConnectionTypeClient {
+				if cert := connOpt.PeerCertificate(); cert != nil {
+					identities <- cert.Subject.CommonName
+				} else {
+					identities <- ""
+				}
+			}
+		},
+	}
+	opt := &ServerOption{
+		Protocol: ProtocolTCP,
+		Addr:     "127.0.0.1",
+		Port:     18086,
+		TLSConfig: &tls.Config{
+			GetCertificate: serverReloader.GetCertificate,
+			ClientAuth:     tls.RequireAndVerifyClientCert,
+			ClientCAs:      pool,
+		},
+	}
+	if err := server.StartBaseListener(opt, serverCallback); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() { _ = server.StopBaseListener() }()
+	time.Sleep(100 * time.Millisecond)
+
+	// 携带客户端证书：握手成功，服务端可读取客户端身份
+	client := NewBaseClient(nil, nil)
+	_, err = client.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18086,
+		TLSConfig: &tls.Config{
+			RootCAs:              pool,
+			GetClientCertificate: clientReloader.GetClientCertificate,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case id := <-identities:
+		if id != "user-42" {
+			t.Errorf("peer identity = %q, want user-42", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for OnConnected")
+	}
+	if info := client.GetConnInfo(); info.PeerCertificate() == nil || info.PeerCertificate().Subject.CommonName != "server" {
+		t.Error("client should see server certificate")
+	}
+
+	// 不携带客户端证书：握手失败，服务端不触发连接回调
+	bare := NewBaseClient(nil, nil)
+	if _, err := bare.Connect(&ClientOption{
+		Protocol:   ProtocolTCP,
+		RemoteIP:   "127.0.0.1",
+		RemotePort: 18086,
+		TLSConfig:  &tls.Config{RootCAs: pool},
+	}); err == nil {
+		// TLS 1.3 中客户端可能在服务端校验前完成握手，连接随后会被关闭
+		defer bare.Close()
+	}
+	select {
+	case id := <-identities:
+		t.Errorf("unexpected connection without client certificate: %q", id)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestTLSRequiresTCP(t *testing.T) {
+	server := NewBaseServer(nil)
+	opt := &ServerOption{Protocol: ProtocolUDP, Addr: "127.0.0.1", Port: 18087, TLSConfig: &tls.Config{}}
+	if err := server.StartBaseListener(opt, nil); err == nil {
+		_ = server.StopBaseListener()
+		t.Error("Expected error for TLS over UDP")
+	}
+
+	client := NewBaseClient(nil, nil)
+	if _, err := client.Connect(&ClientOption{Protocol: ProtocolUDP, RemoteIP: "127.0.0.1", RemotePort: 18087, TLSConfig: &tls.Config{}}); err == nil {
+		client.Close()
+		t.Error("Expected error for TLS over UDP")
+	}
+}
+
+func TestCertReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", "v1", 2)
+
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader error = %v", err)
+	}
+	reloaded := make(chan error, 4)
+	if err := r.Watch(func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("Watch error = %v", err)
+	}
+	defer r.Close()
+
+	if cn := r.Certificate().Leaf.Subject.CommonName; cn != "v1" {
+		t.Fatalf("initial certificate = %q, want v1", cn)
+	}
+
+	ca.issue(t, dir, "server", "v2", 3)
+
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case err := <-reloaded:
+			if err != nil {
+				continue // 证书与私钥先后写入，中间状态可能不匹配
+			}
+			if cn := r.Certificate().Leaf.Subject.CommonName; cn == "v2" {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("certificate not reloaded, got %q", r.Certificate().Leaf.Subject.CommonName)
+		}
+	}
+}
+
+func TestCertReloaderWatchCloseConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", "v1", 2)
+
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader error = %v", err)
+	}
+
+	// 并发启动和停止监听，重复 Close 不应 panic
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := r.Watch(nil); err != nil {
+				t.Errorf("Watch error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = r.Close()
+		}()
+	}
+	wg.Wait()
+
+	if err := r.Close(); err != nil {
+		t.Errorf("Close error = %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("second Close error = %v", err)
+	}
+
+	// Close 之后可以重新监听
+	if err := r.Watch(nil); err != nil {
+		t.Fatalf("Watch after Close error = %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close error = %v", err)
+	}
+}
+
+func TestSNICertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	defCert, defKey := ca.issue(t, dir, "default", "default", 2)
+	apiCert, apiKey := ca.issue(t, dir, "api", "api", 3)
+	wildCert, wildKey := ca.issue(t, dir, "wild", "wild", 4)
+
+	def, _ := NewCertReloader(defCert, defKey)
+	api, _ := NewCertReloader(apiCert, apiKey)
+	wild, _ := NewCertReloader(wildCert, wildKey)
+
+	sni := NewSNICertificates(def)
+	sni.Add("api.example.com", api)
+	sni.Add("*.example.com", wild)
+
+	tests := map[string]string{
+		"api.example.com": "api",
+		"API.example.com": "api",
+		"www.example.com": "wild",
+		"other.org":       "default",
+		"":                "default",
+	}
+	for name, want := range tests {
+		cert, err := sni.GetCertificate(&tls.ClientHelloInfo{ServerName: name})
+		if err != nil {
+			t.Fatalf("GetCertificate(%q) error = %v", name, err)
+		}
+		if cn := cert.Leaf.Subject.CommonName; cn != want {
+			t.Errorf("GetCertificate(%q) = %q, want %q", name, cn, want)
+		}
+	}
+
+	sni.Remove("api.example.com")
+	if cert, _ := sni.GetCertificate(&tls.ClientHelloInfo{ServerName: "api.example.com"}); cert.Leaf.Subject.CommonName != "wild" {
+		t.Error("removed name should fall back to wildcard")
+	}
+
+	if _, err := NewSNICertificates(nil).GetCertificate(&tls.ClientHelloInfo{ServerName: "x"}); err == nil {
+		t.Error("Expected error without fallback certificate")
+	}
+}
@@ -0,0 +1,256 @@
+package netconn
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startEchoServer 启动按消息回显的服务端，返回服务端收到的连接信息
+func startEchoServer(t *testing.T, opt *ServerOption) (*BaseServer, chan *ConnectOption) {
+	t.Helper()
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	opt.Framer = framer
+	connInfos := make(chan *ConnectOption, 1)
+	server := NewBaseServer(nil)
+	err := server.StartBaseListener(opt, &BaseListenerCallback{
+		OnConnected: func(fd int, connType ConnectionType, connOpt *ConnectOption) {
+			if connType == ConnectionTypeClient {
+				connInfos <- connOpt
+			}
+		},
+		OnMessage: func(fd int, msg []byte) {
+			_ = server.SendMessage(fd, msg)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = server.StopBaseListener() })
+	time.Sleep(100 * time.Millisecond)
+	return server, connInfos
+}
+
+// echoRoundTrip 连接服务端并校验消息回显
+func echoRoundTrip(t *testing.T, opt *ClientOption, messages ...string) *BaseClient {
+	t.Helper()
+	framer, _ := NewLengthFieldFramer(4, nil, 0)
+	opt.Framer = framer
+	received := make(chan string, len(messages))
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnMessage: func(fd int, msg []byte) {
+			received <- string(msg)
+		},
+	})
+	if _, err := client.Connect(opt); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	for _, msg := range messages {
+		if err := client.SendMessage([]byte(msg)); err != nil {
+			t.Fatalf("SendMessage error = %v", err)
+		}
+	}
+	for _, want := range messages {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Errorf("echo len = %d, want %d", len(got), len(want))
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for echo")
+		}
+	}
+	return client
+}
+
+func TestUnixTransport(t *testing.T) {
+	for _, protocol := range []ProtocolType{ProtocolUnix, ProtocolUnixPacket} {
+		path := filepath.Join(t.TempDir(), "netconn.sock")
+		_, connInfos := startEchoServer(t, &ServerOption{Protocol: protocol, Addr: path})
+
+		client := echoRoundTrip(t, &ClientOption{Protocol: protocol, RemoteIP: path},
+			"hello", strings.Repeat("x", 8*1024), "bye")
+		if info := <-connInfos; info.Protocol != protocol {
+			t.Errorf("server conn protocol = %v, want %v", info.Protocol, protocol)
+		}
+		if info := client.GetConnInfo(); info.Protocol != protocol {
+			t.Errorf("client conn protocol = %v, want %v", info.Protocol, protocol)
+		}
+	}
+}
+
+func TestUnixPacketBoundaries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packet.sock")
+	listener, err := net.Listen("unixpacket", path)
+	if err != nil {
+		t.Fatalf("listen error = %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("first"))
+		_, _ = conn.Write([]byte("second"))
+	}()
+
+	raw, err := net.Dial("unixpacket", path)
+	if err != nil {
+		t.Fatalf("dial error = %v", err)
+	}
+	conn := NewUnixConnection(raw.(*net.UnixConn), true)
+	defer conn.Close()
+
+	// 小缓冲区分多次读出同一消息，不会截断
+	buf := make([]byte, 3)
+	var got bytes.Buffer
+	for got.Len() < len("firstsecond") {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Read error = %v", err)
+		}
+		got.Write(buf[:n])
+	}
+	if got.String() != "firstsecond" {
+		t.Errorf("received %q", got.String())
+	}
+
+	if _, err := conn.Write(make([]byte, MaxUnixPacketSize+1)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("oversized Write error = %v, want ErrFrameTooLarge", err)
+	}
+}
+
+func TestStaleUnixSocketRemoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen error = %v", err)
+	}
+	// 模拟进程异常退出：关闭监听但保留套接字文件
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	startEchoServer(t, &ServerOption{Protocol: ProtocolUnix, Addr: path})
+	echoRoundTrip(t, &ClientOption{Protocol: ProtocolUnix, RemoteIP: path}, "ping")
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	_, connInfos := startEchoServer(t, &ServerOption{
+		Protocol:      ProtocolWebSocket,
+		Addr:          "127.0.0.1",
+		Port:          18105,
+		WebSocketPath: "/ws",
+	})
+
+	// 覆盖 7 位、16 位和 64 位负载长度
+	client := echoRoundTrip(t, &ClientOption{
+		Protocol:      ProtocolWebSocket,
+		RemoteIP:      "127.0.0.1",
+		RemotePort:    18105,
+		WebSocketPath: "/ws",
+	}, "hello", strings.Repeat("m", 1000), strings.Repeat("L", 100*1024))
+
+	if info := <-connInfos; info.Protocol != ProtocolWebSocket {
+		t.Errorf("server conn protocol = %v", info.Protocol)
+	}
+	if info := client.GetConnInfo(); info.Protocol != ProtocolWebSocket {
+		t.Errorf("client conn protocol = %v", info.Protocol)
+	}
+
+	// 路径不匹配时握手失败
+	bad := NewBaseClient(nil, nil)
+	_, err := bad.Connect(&ClientOption{Protocol: ProtocolWebSocket, RemoteIP: "127.0.0.1", RemotePort: 18105, WebSocketPath: "/other"})
+	if !errors.Is(err, ErrWebSocketHandshake) {
+		t.Errorf("Connect with wrong path error = %v, want ErrWebSocketHandshake", err)
+	}
+}
+
+// TestWebSocketBrowserFrames 模拟浏览器客户端：文本帧、分片帧、Ping 和 Close
+func TestWebSocketBrowserFrames(t *testing.T) {
+	received := make(chan string, 1)
+	server := NewBaseServer(nil)
+	err := server.StartBaseListener(&ServerOption{Protocol: ProtocolWebSocket, Addr: "127.0.0.1", Port: 18106}, &BaseListenerCallback{
+		OnDataReceived: func(fd int, connType ConnectionType, buf []byte, used int) int {
+			if used < len("hello world") {
+				return 0
+			}
+			received <- string(buf[:used])
+			_ = server.SendBytes(fd, buf[:used])
+			return used
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.StopBaseListener()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, err := net.Dial("tcp", "127.0.0.1:18106")
+	if err != nil {
+		t.Fatalf("dial error = %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	_, _ = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\nSec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("read handshake response error = %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake response = %s, accept %q", resp.Status, resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	frame := func(head byte, payload string) []byte {
+		b := []byte{head, 0x80 | byte(len(payload))}
+		b = append(b, mask...)
+		for i := 0; i < len(payload); i++ {
+			b = append(b, payload[i]^mask[i%4])
+		}
+		return b
+	}
+	// 文本帧 "hello " 分片 + 中间插入 Ping + 续帧 "world"
+	_, _ = conn.Write(frame(0x01, "hello "))
+	_, _ = conn.Write(frame(0x89, "hb"))
+	_, _ = conn.Write(frame(0x80, "world"))
+
+	// 先收到 Pong，再收到二进制回显
+	want := [][]byte{
+		append([]byte{0x8A, 2}, "hb"...),
+		append([]byte{0x82, 11}, "hello world"...),
+	}
+	for _, w := range want {
+		got := make([]byte, len(w))
+		if _, err := io.ReadFull(br, got); err != nil {
+			t.Fatalf("read frame error = %v", err)
+		}
+		if !bytes.Equal(got, w) {
+			t.Errorf("frame = %q, want %q", got, w)
+		}
+	}
+	if got := <-received; got != "hello world" {
+		t.Errorf("server received %q", got)
+	}
+
+	// Close 帧：服务端回送状态码后断开
+	_, _ = conn.Write(frame(0x88, "\x03\xe8"))
+	got := make([]byte, 4)
+	if _, err := io.ReadFull(br, got); err != nil || !bytes.Equal(got, []byte{0x88, 2, 0x03, 0xe8}) {
+		t.Errorf("close reply = %q, %v", got, err)
+	}
+}
@@ -1,6 +1,8 @@
 package netconn
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"net"
 	"time"
 )
@@ -9,10 +11,18 @@ import (
 type ProtocolType int
 
 const (
-	ProtocolTCP ProtocolType = iota
-	ProtocolUDP // 使用FILLP
+	ProtocolTCP        ProtocolType = iota
+	ProtocolUDP                     // 使用FILLP
+	ProtocolUnix                    // Unix域套接字（stream），地址为套接字路径
+	ProtocolUnixPacket              // Unix域套接字（seqpacket），保留消息边界
+	ProtocolWebSocket               // WebSocket（基于TCP，二进制帧）
 )
 
+// supportsTLS 判断协议是否支持TLS
+func (p ProtocolType) supportsTLS() bool {
+	return p == ProtocolTCP || p == ProtocolWebSocket
+}
+
 // ConnectionType 连接类型
 type ConnectionType int
 
@@ -42,34 +52,79 @@ type ConnectOption struct {
 	LocalSocket  *SocketOption
 	RemoteSocket *SocketOption
 	NetConn      NetConnection
+	TLS          *tls.ConnectionState // TLS连接状态，非TLS连接为nil
+}
+
+// PeerCertificate 返回对端证书（TLS连接且对端提供了证书时），可用于按身份鉴权
+func (o *ConnectOption) PeerCertificate() *x509.Certificate {
+	if o == nil || o.TLS == nil || len(o.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return o.TLS.PeerCertificates[0]
 }
 
 // ServerOption 服务器选项
 type ServerOption struct {
-	Protocol ProtocolType
-	Addr     string
-	Port     int
+	Protocol      ProtocolType
+	Addr          string // 监听地址，Unix域套接字为套接字路径
+	Port          int
+	WebSocketPath string            // WebSocket 升级路径（默认"/"）
+	Framer        Framer            // 帧编解码器，设置后通过 OnMessage 接收完整消息
+	MaxFrameSize  int               // 接收缓冲区上限（字节），0表示使用 DefaultMaxFrameSize
+	TLSConfig     *tls.Config       // 非nil时启用TLS（仅TCP/WebSocket），ClientAuth/ClientCAs 用于校验客户端证书
+	WriteQueue    *WriteQueueOption // 非nil时每个连接使用异步写队列，SendBytes 不再阻塞调用方
+	Heartbeat     *HeartbeatOption  // 非nil时启用应用层心跳与空闲检测
+	Admission     *AdmissionOption  // 非nil时启用准入控制（连接数、速率、CIDR 等）
+	Goodbye       []byte            // Shutdown 时发送给每个连接的告别消息（设置 Framer 时按消息编码），nil表示不发送
 }
 
 // ClientOption 客户端选项
 type ClientOption struct {
 	Protocol        ProtocolType
-	RemoteIP        string
+	RemoteIP        string // 服务端地址，Unix域套接字为套接字路径
 	RemotePort      int
+	WebSocketPath   string // WebSocket 升级路径（默认"/"）
 	Timeout         time.Duration
 	KeepAlive       bool
 	KeepAlivePeriod time.Duration
+	Framer          Framer            // 帧编解码器，设置后通过 OnMessage 接收完整消息
+	MaxFrameSize    int               // 接收缓冲区上限（字节），0表示使用 DefaultMaxFrameSize
+	TLSConfig       *tls.Config       // 非nil时启用TLS（仅TCP/WebSocket），ServerName 为空时使用 RemoteIP
+	Reconnect       *ReconnectPolicy  // 非nil时启用断线自动重连
+	WriteQueue      *WriteQueueOption // 非nil时使用异步写队列，SendBytes 不再阻塞调用方
+	Heartbeat       *HeartbeatOption  // 非nil时启用应用层心跳与空闲检测（KeepAlive 为TCP层保活）
 }
 
 // BaseListenerCallback 统一的回调接口
 type BaseListenerCallback struct {
-	OnConnected    func(fd int, connType ConnectionType, connOpt *ConnectOption)
+	OnConnected func(fd int, connType ConnectionType, connOpt *ConnectOption)
+	// OnDisconnected 连接断开，不携带原因的旧形式，为兼容已有调用方保留；需要断开原因时使用 OnClosed
 	OnDisconnected func(fd int, connType ConnectionType)
+	// OnClosed 连接断开并给出原因，在 OnDisconnected 之后触发，新代码只需设置其一。
+	// 原因为 ErrReadIdleTimeout、ErrWriteIdleTimeout、ErrWriteTimeout、CloseWithReason 指定的原因，
+	// 本地关闭时为 ErrConnClosed、对端关闭时为 io.EOF，其余为读取错误。
+	// 单独新增回调而不修改 OnDisconnected 的签名，是为了不破坏按旧签名赋值的已有代码
+	OnClosed       func(fd int, connType ConnectionType, reason error)
 	OnDataReceived func(fd int, connType ConnectionType, buf []byte, used int) int
+	// OnMessage 收到一条完整消息（需配置 Framer），msg 仅在回调期间有效
+	OnMessage func(fd int, msg []byte)
+
+	// 以下回调仅在客户端启用断线重连时触发，重连成功后沿用原fd并再次触发 OnConnected
+	OnReconnecting    func(fd int, attempt int, delay time.Duration) // 第 attempt 次重连开始等待
+	OnReconnected     func(fd int, attempt int)                      // 第 attempt 次重连成功
+	OnReconnectFailed func(fd int, attempts int, err error)          // 达到最大重连次数，放弃重连
+
+	// 以下回调仅在启用写队列时触发，可用于生产方限流
+	OnWriteHighWatermark func(fd int, queued int) // 排队字节数达到 HighWatermark
+	OnWriteLowWatermark  func(fd int, queued int) // 排队字节数回落到 LowWatermark
 }
 
 // 常量定义
 const (
-	DefaultBufSize        = 1536
-	DefaultConnectTimeout = 5 * time.Second
+	DefaultBufSize          = 1536
+	DefaultConnectTimeout   = 5 * time.Second
+	DefaultMaxFrameSize     = 1 << 20 // 1MB
+	DefaultHandshakeTimeout = 10 * time.Second
+
+	DefaultBroadcastWriteTimeout = time.Second // 广播时未启用写队列的连接单次同步写出的截止时间
 )
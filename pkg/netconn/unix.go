@@ -0,0 +1,92 @@
+package netconn
+
+import (
+	"net"
+	"os"
+	"time"
+)
+
+// MaxUnixPacketSize ProtocolUnixPacket 单个消息的最大长度，超过时收发均返回 ErrFrameTooLarge
+const MaxUnixPacketSize = 64 << 10
+
+// UnixConnection Unix域套接字连接适配器
+// stream 模式与TCP语义一致；seqpacket 模式保留消息边界，每次 Write 发送一个消息
+type UnixConnection struct {
+	conn    *net.UnixConn
+	packet  bool
+	buf     []byte // seqpacket 接收缓冲区
+	pending []byte // 上次读取未拷贝完的消息
+}
+
+func NewUnixConnection(conn *net.UnixConn, packet bool) *UnixConnection {
+	return &UnixConnection{conn: conn, packet: packet}
+}
+
+func (u *UnixConnection) Read(b []byte) (n int, err error) {
+	if !u.packet {
+		return u.conn.Read(b)
+	}
+
+	// seqpacket 读取时缓冲区不足会截断消息，因此先完整读入内部缓冲区
+	if len(u.pending) == 0 {
+		if u.buf == nil {
+			u.buf = make([]byte, MaxUnixPacketSize+1)
+		}
+		n, err := u.conn.Read(u.buf)
+		if err != nil {
+			return 0, err
+		}
+		if n > MaxUnixPacketSize {
+			return 0, ErrFrameTooLarge
+		}
+		u.pending = u.buf[:n]
+	}
+	n = copy(b, u.pending)
+	u.pending = u.pending[n:]
+	return n, nil
+}
+
+func (u *UnixConnection) Write(b []byte) (n int, err error) {
+	if u.packet && len(b) > MaxUnixPacketSize {
+		return 0, ErrFrameTooLarge
+	}
+	return u.conn.Write(b)
+}
+
+func (u *UnixConnection) Close() error {
+	return u.conn.Close()
+}
+
+func (u *UnixConnection) LocalAddr() net.Addr {
+	return u.conn.LocalAddr()
+}
+
+func (u *UnixConnection) RemoteAddr() net.Addr {
+	return u.conn.RemoteAddr()
+}
+
+// SetWriteDeadline 设置写截止时间
+func (u *UnixConnection) SetWriteDeadline(deadline time.Time) error {
+	return u.conn.SetWriteDeadline(deadline)
+}
+
+// unixNetwork 返回协议对应的 Unix 网络类型
+func unixNetwork(protocol ProtocolType) string {
+	if protocol == ProtocolUnixPacket {
+		return "unixpacket"
+	}
+	return "unix"
+}
+
+// removeStaleUnixSocket 删除进程异常退出后遗留的套接字文件，仍有进程监听时保留
+func removeStaleUnixSocket(network, path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if conn, err := net.DialTimeout(network, path, time.Second); err == nil {
+		conn.Close()
+		return
+	}
+	_ = os.Remove(path)
+}
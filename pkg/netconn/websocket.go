@@ -0,0 +1,342 @@
+package netconn
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWebSocketPath 默认的 WebSocket 升级路径
+const DefaultWebSocketPath = "/"
+
+// websocketGUID RFC 6455 握手使用的固定 GUID
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket 操作码
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// wsCloseTimeout 关闭连接时发送 Close 帧的超时
+const wsCloseTimeout = time.Second
+
+var (
+	// ErrWebSocketHandshake WebSocket 握手失败
+	ErrWebSocketHandshake = errors.New("websocket handshake failed")
+	// errWebSocketProtocol 收到不符合 RFC 6455 的帧
+	errWebSocketProtocol = errors.New("websocket protocol error")
+)
+
+// WebSocketConnection WebSocket连接适配器
+// 文本帧和二进制帧的负载按字节流读出（与TCP语义一致，可配合 Framer 使用），每次 Write 发送一个二进制帧；
+// Ping/Close 控制帧自动应答
+type WebSocketConnection struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // 客户端发送的帧需要掩码
+
+	// 当前数据帧的读取状态，仅由接收协程访问
+	remaining int64
+	masked    bool
+	maskKey   [4]byte
+	maskPos   int
+
+	writeMu   sync.Mutex
+	closeSent bool
+}
+
+// acceptWebSocket 在已建立的连接上完成服务端 HTTP 升级握手
+func acceptWebSocket(conn net.Conn, path string) (*WebSocketConnection, error) {
+	_ = conn.SetDeadline(time.Now().Add(DefaultHandshakeTimeout))
+	br := bufio.NewReader(conn)
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return nil, err
+	}
+
+	key := req.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case req.Method != http.MethodGet || key == "" ||
+		!headerContains(req.Header, "Connection", "upgrade") ||
+		!headerContains(req.Header, "Upgrade", "websocket"):
+		writeHTTPError(conn, http.StatusBadRequest, "")
+		return nil, ErrWebSocketHandshake
+	case req.Header.Get("Sec-WebSocket-Version") != "13":
+		writeHTTPError(conn, http.StatusUpgradeRequired, "Sec-WebSocket-Version: 13\r\n")
+		return nil, ErrWebSocketHandshake
+	case req.URL.Path != path:
+		writeHTTPError(conn, http.StatusNotFound, "")
+		return nil, ErrWebSocketHandshake
+	}
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n"
+	if _, err := io.WriteString(conn, resp); err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return &WebSocketConnection{conn: conn, br: br}, nil
+}
+
+// dialWebSocket 在已建立的连接上完成客户端握手
+func dialWebSocket(conn net.Conn, host, path string, timeout time.Duration) (*WebSocketConnection, error) {
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	req := "GET " + path + " HTTP/1.1\r\n" +
+		"Host: " + host + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		return nil, fmt.Errorf("%w: status %s", ErrWebSocketHandshake, resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return &WebSocketConnection{conn: conn, br: br, client: true}, nil
+}
+
+// Read 读取数据帧负载，跨帧连续读取
+func (w *WebSocketConnection) Read(b []byte) (int, error) {
+	for w.remaining == 0 {
+		if err := w.nextFrame(); err != nil {
+			return 0, err
+		}
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	n, err := w.br.Read(b[:min(int64(len(b)), w.remaining)])
+	if w.masked {
+		for i := range b[:n] {
+			b[i] ^= w.maskKey[w.maskPos&3]
+			w.maskPos++
+		}
+	}
+	w.remaining -= int64(n)
+	return n, err
+}
+
+// nextFrame 读取下一个帧头，控制帧在此处理，数据帧的负载留给 Read
+func (w *WebSocketConnection) nextFrame() error {
+	var head [2]byte
+	if _, err := io.ReadFull(w.br, head[:]); err != nil {
+		return err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	// 客户端发往服务端的帧必须有掩码，服务端发往客户端的帧不能有掩码
+	if masked == w.client || head[0]&0x70 != 0 {
+		return errWebSocketProtocol
+	}
+
+	length := int64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(w.br, ext[:]); err != nil {
+			return err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(w.br, ext[:]); err != nil {
+			return err
+		}
+		if ext[0]&0x80 != 0 {
+			return errWebSocketProtocol
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(w.br, key[:]); err != nil {
+			return err
+		}
+	}
+
+	switch opcode {
+	case wsOpContinuation, wsOpText, wsOpBinary:
+		w.remaining = length
+		w.masked = masked
+		w.maskKey = key
+		w.maskPos = 0
+		return nil
+	case wsOpClose, wsOpPing, wsOpPong:
+		if !fin || length > 125 {
+			return errWebSocketProtocol
+		}
+	default:
+		return errWebSocketProtocol
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(w.br, payload); err != nil {
+		return err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i&3]
+		}
+	}
+
+	switch opcode {
+	case wsOpPing:
+		return w.writeFrame(wsOpPong, payload)
+	case wsOpClose:
+		// 回送对端的状态码后视为连接结束
+		_ = w.writeFrame(wsOpClose, payload[:min(len(payload), 2)])
+		return io.EOF
+	}
+	return nil
+}
+
+// writeFrame 发送一个完整帧
+func (w *WebSocketConnection) writeFrame(opcode byte, payload []byte) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return w.writeFrameLocked(opcode, payload)
+}
+
+// writeFrameLocked 写出一个完整帧，客户端发送的帧使用随机掩码，调用方需持有 writeMu
+func (w *WebSocketConnection) writeFrameLocked(opcode byte, payload []byte) error {
+	header := make([]byte, 0, 10)
+	header = append(header, 0x80|opcode)
+	maskBit := byte(0)
+	if w.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, maskBit|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, maskBit|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, maskBit|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if w.closeSent {
+		return net.ErrClosed
+	}
+	if opcode == wsOpClose {
+		w.closeSent = true
+	}
+
+	if w.client {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		frame := make([]byte, 0, len(header)+4+len(payload))
+		frame = append(frame, header...)
+		frame = append(frame, key[:]...)
+		for i, c := range payload {
+			frame = append(frame, c^key[i&3])
+		}
+		_, err := w.conn.Write(frame)
+		return err
+	}
+
+	bufs := net.Buffers{header, payload}
+	_, err := bufs.WriteTo(w.conn)
+	return err
+}
+
+// Write 以一个二进制帧发送数据
+func (w *WebSocketConnection) Write(b []byte) (int, error) {
+	if err := w.writeFrame(wsOpBinary, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close 发送 Close 帧（正常关闭）后关闭底层连接；有写操作阻塞时直接关闭
+func (w *WebSocketConnection) Close() error {
+	if w.writeMu.TryLock() {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(wsCloseTimeout))
+		_ = w.writeFrameLocked(wsOpClose, []byte{0x03, 0xE8}) // 1000 正常关闭
+		w.writeMu.Unlock()
+	}
+	return w.conn.Close()
+}
+
+func (w *WebSocketConnection) LocalAddr() net.Addr {
+	return w.conn.LocalAddr()
+}
+
+func (w *WebSocketConnection) RemoteAddr() net.Addr {
+	return w.conn.RemoteAddr()
+}
+
+// SetWriteDeadline 设置写截止时间
+func (w *WebSocketConnection) SetWriteDeadline(deadline time.Time) error {
+	return w.conn.SetWriteDeadline(deadline)
+}
+
+// ConnectionState 返回TLS连接状态（wss），非TLS连接返回false
+func (w *WebSocketConnection) ConnectionState() (tls.ConnectionState, bool) {
+	if tlsConn, ok := w.conn.(*tls.Conn); ok {
+		return tlsConn.ConnectionState(), true
+	}
+	return tls.ConnectionState{}, false
+}
+
+// websocketAccept 计算 Sec-WebSocket-Accept
+func websocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// headerContains 判断逗号分隔的请求头是否包含指定值（不区分大小写）
+func headerContains(header http.Header, name, value string) bool {
+	for _, v := range header.Values(name) {
+		for _, item := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeHTTPError 握手失败时返回HTTP错误响应
+func writeHTTPError(conn net.Conn, status int, extraHeader string) {
+	_, _ = fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nConnection: close\r\n%s\r\n", status, http.StatusText(status), extraHeader)
+}
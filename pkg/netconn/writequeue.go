@@ -0,0 +1,313 @@
+package netconn
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrWriteQueueFull 写队列已满（OverflowDisconnect 策略或广播时的 OverflowBlock 策略）
+	ErrWriteQueueFull = errors.New("write queue full")
+	// ErrWriteTimeout 写出超过 WriteTimeout 被关闭，或 OverflowBlock 策略下等待队列空间超时
+	ErrWriteTimeout = errors.New("write timeout")
+	// ErrConnClosed 连接已关闭，写队列已停止
+	ErrConnClosed = errors.New("connection closed")
+)
+
+// OverflowPolicy 写队列溢出策略
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // 阻塞发送方直到队列有空间（最长 WriteTimeout）
+	OverflowDropOldest                       // 丢弃最早入队且尚未写出的数据
+	OverflowDisconnect                       // 断开连接
+)
+
+// 写队列参数
+const (
+	DefaultWriteQueueBytes = 4 << 20   // 每个连接写队列的默认容量（4MB）
+	maxWriteBatchBytes     = 256 << 10 // 单次批量写出的字节上限
+	maxWriteBatchBufs      = 1024      // 单次批量写出的缓冲区个数上限（writev 的 IOV_MAX）
+)
+
+// WriteQueueOption 写队列配置
+type WriteQueueOption struct {
+	MaxBytes      int            // 队列容量（字节，0=使用 DefaultWriteQueueBytes），队列为空时单条数据可超过容量
+	Policy        OverflowPolicy // 队列满时的处理策略
+	WriteTimeout  time.Duration  // 单次写出的截止时间，超时关闭连接；同时限制 OverflowBlock 的等待时间（0表示不限）
+	HighWatermark int            // 排队字节数达到该值时触发 OnWriteHighWatermark（0表示不启用）
+	LowWatermark  int            // 越过高水位后回落到该值及以下时触发 OnWriteLowWatermark
+}
+
+// writeDeadliner 支持写截止时间的连接
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// writeQueue 连接的异步写队列，由独立协程批量顺序写出，慢连接只会填满自己的队列
+type writeQueue struct {
+	fd     int
+	conn   NetConnection
+	state  *connState
+	opt    WriteQueueOption
+	onHigh func(fd int, queued int)
+	onLow  func(fd int, queued int)
+
+	mu     sync.Mutex
+	bufs   [][]byte
+	queued int           // 排队中及正在写出的字节数
+	high   bool          // 是否处于高水位
+	space  chan struct{} // 写出一批后关闭，唤醒等待空间的发送方
+	wake   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// newWriteQueue 创建写队列并启动写协程，opt 为nil时使用默认配置
+func newWriteQueue(fd int, conn NetConnection, state *connState, opt *WriteQueueOption, callback *BaseListenerCallback) *writeQueue {
+	q := &writeQueue{
+		fd:    fd,
+		conn:  conn,
+		state: state,
+		space: make(chan struct{}),
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	if opt != nil {
+		q.opt = *opt
+	}
+	if q.opt.MaxBytes <= 0 {
+		q.opt.MaxBytes = DefaultWriteQueueBytes
+	}
+	if callback != nil {
+		q.onHigh = callback.OnWriteHighWatermark
+		q.onLow = callback.OnWriteLowWatermark
+	}
+	go q.run()
+	return q
+}
+
+// send 数据入队，data 的所有权转移给队列
+// wait 为 false 时 OverflowBlock 策略不等待，直接返回 ErrWriteQueueFull
+func (q *writeQueue) send(data []byte, wait bool) error {
+	var timeout <-chan time.Time
+
+	q.mu.Lock()
+	for {
+		if isClosed(q.done) {
+			q.mu.Unlock()
+			return ErrConnClosed
+		}
+		if q.queued == 0 || q.queued+len(data) <= q.opt.MaxBytes {
+			break
+		}
+
+		if q.opt.Policy == OverflowDropOldest {
+			q.dropOldestLocked(len(data))
+			break
+		}
+		if q.opt.Policy == OverflowDisconnect {
+			q.mu.Unlock()
+			q.fail(ErrWriteQueueFull)
+			return ErrWriteQueueFull
+		}
+
+		if !wait {
+			q.mu.Unlock()
+			return ErrWriteQueueFull
+		}
+		if timeout == nil && q.opt.WriteTimeout > 0 {
+			t := time.NewTimer(q.opt.WriteTimeout)
+			defer t.Stop()
+			timeout = t.C
+		}
+		space := q.space
+		q.mu.Unlock()
+		select {
+		case <-space:
+		case <-q.done:
+		case <-timeout:
+			return ErrWriteTimeout
+		}
+		q.mu.Lock()
+	}
+
+	q.bufs = append(q.bufs, data)
+	q.queued += len(data)
+	queued := q.queued
+	reachHigh := q.opt.HighWatermark > 0 && !q.high && queued >= q.opt.HighWatermark
+	if reachHigh {
+		q.high = true
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	if reachHigh && q.onHigh != nil {
+		q.onHigh(q.fd, queued)
+	}
+	return nil
+}
+
+// dropOldestLocked 丢弃最早入队的数据直到能容纳 size 字节，正在写出的数据不受影响，调用方需持有 q.mu
+func (q *writeQueue) dropOldestLocked(size int) {
+	for len(q.bufs) > 0 && q.queued+size > q.opt.MaxBytes {
+		q.queued -= len(q.bufs[0])
+		q.bufs[0] = nil
+		q.bufs = q.bufs[1:]
+	}
+}
+
+// run 写协程：批量取出数据写出，写失败或超时时关闭连接，由接收循环完成注销
+func (q *writeQueue) run() {
+	var batch [][]byte
+	for {
+		select {
+		case <-q.wake:
+		case <-q.done:
+			return
+		}
+
+		for {
+			var size int
+			batch, size = q.take(batch[:0])
+			if len(batch) == 0 {
+				break
+			}
+			if err := q.write(batch); err != nil {
+				q.state.recordError()
+				q.fail(err)
+				return
+			}
+			q.written(size)
+		}
+	}
+}
+
+// take 从队列头部取出一批数据
+func (q *writeQueue) take(batch [][]byte) ([][]byte, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	size := 0
+	n := 0
+	for n < len(q.bufs) && n < maxWriteBatchBufs {
+		if n > 0 && size+len(q.bufs[n]) > maxWriteBatchBytes {
+			break
+		}
+		size += len(q.bufs[n])
+		batch = append(batch, q.bufs[n])
+		q.bufs[n] = nil
+		n++
+	}
+	q.bufs = q.bufs[n:]
+	if len(q.bufs) == 0 {
+		q.bufs = nil
+	}
+	return batch, size
+}
+
+// write 写出一批数据，超时返回 ErrWriteTimeout
+func (q *writeQueue) write(batch [][]byte) error {
+	size := 0
+	for _, b := range batch {
+		size += len(b)
+	}
+	err := writeWithTimeout(q.conn, batch, q.opt.WriteTimeout)
+	if err == nil {
+		q.state.recordWrite(size)
+	}
+	return err
+}
+
+// writeWithTimeout 在截止时间内写出一批数据（timeout 为0表示不限），超时返回 ErrWriteTimeout
+// TCP 和 Unix stream 连接使用 net.Buffers 合并为一次 writev 系统调用
+func writeWithTimeout(conn NetConnection, batch [][]byte, timeout time.Duration) error {
+	start := time.Now()
+	err := writeBatch(conn, batch, timeout)
+	if err != nil && timeout > 0 && (errors.Is(err, os.ErrDeadlineExceeded) || time.Since(start) >= timeout) {
+		return ErrWriteTimeout
+	}
+	return err
+}
+
+// writeBatch 在写截止时间内写出一批数据
+func writeBatch(conn NetConnection, batch [][]byte, timeout time.Duration) error {
+	if timeout > 0 {
+		if d, ok := conn.(writeDeadliner); ok {
+			_ = d.SetWriteDeadline(time.Now().Add(timeout))
+			defer d.SetWriteDeadline(time.Time{})
+		} else {
+			// 不支持写截止时间的连接（FILLP）超时后直接关闭，使阻塞的写操作返回
+			t := time.AfterFunc(timeout, func() { conn.Close() })
+			defer t.Stop()
+		}
+	}
+
+	if len(batch) > 1 {
+		var stream net.Conn
+		switch c := conn.(type) {
+		case *TCPConnection:
+			stream = c.conn
+		case *UnixConnection:
+			if !c.packet {
+				stream = c.conn
+			}
+		}
+		if stream != nil {
+			bufs := net.Buffers(batch)
+			_, err := bufs.WriteTo(stream)
+			return err
+		}
+	}
+	for _, data := range batch {
+		if _, err := conn.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// written 一批数据写出后更新计数，唤醒等待的发送方并检查低水位
+func (q *writeQueue) written(size int) {
+	q.mu.Lock()
+	q.queued -= size
+	queued := q.queued
+	reachLow := q.high && queued <= q.opt.LowWatermark
+	if reachLow {
+		q.high = false
+	}
+	close(q.space)
+	q.space = make(chan struct{})
+	q.mu.Unlock()
+
+	if reachLow && q.onLow != nil {
+		q.onLow(q.fd, queued)
+	}
+}
+
+// queuedBytes 返回排队中及正在写出的字节数
+func (q *writeQueue) queuedBytes() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queued
+}
+
+// fail 记录关闭原因，关闭连接并停止写队列
+func (q *writeQueue) fail(reason error) {
+	q.state.setReason(reason)
+	q.conn.Close()
+	q.stop()
+}
+
+// stop 停止写协程，未写出的数据被丢弃
+func (q *writeQueue) stop() {
+	q.once.Do(func() {
+		close(q.done)
+	})
+}
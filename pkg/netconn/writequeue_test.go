@@ -0,0 +1,245 @@
+package netconn
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// enableQueue 注册 net.Pipe 连接并启用写队列，对端不读取时写操作一直阻塞
+func enableQueue(t *testing.T, mgr *ConnectionManager, opt *WriteQueueOption, callback *BaseListenerCallback) (int, io.Reader) {
+	t.Helper()
+	fd, peer := registerPipe(t, mgr)
+	if err := mgr.EnableWriteQueue(fd, opt, callback); err != nil {
+		t.Fatalf("EnableWriteQueue error = %v", err)
+	}
+	return fd, peer
+}
+
+// readN 从对端读取 n 字节
+func readN(t *testing.T, r io.Reader, n int) string {
+	t.Helper()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("read error = %v", err)
+	}
+	return string(buf)
+}
+
+func TestWriteQueueNonBlockingSend(t *testing.T) {
+	mgr := NewConnectionManager()
+	fd, peer := enableQueue(t, mgr, &WriteQueueOption{}, nil)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			_ = mgr.SendBytes(fd, []byte("ab"))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SendBytes blocked on stalled peer")
+	}
+	if n := mgr.QueuedBytes(fd); n != 200 {
+		t.Errorf("QueuedBytes = %d, want 200", n)
+	}
+
+	if got := readN(t, peer, 200); got != strings.Repeat("ab", 100) {
+		t.Errorf("received %q", got)
+	}
+}
+
+func TestWriteQueueOverflowPolicies(t *testing.T) {
+	t.Run("DropOldest", func(t *testing.T) {
+		mgr := NewConnectionManager()
+		fd, peer := enableQueue(t, mgr, &WriteQueueOption{MaxBytes: 4, Policy: OverflowDropOldest}, nil)
+
+		_ = mgr.SendBytes(fd, []byte("aa"))
+		time.Sleep(50 * time.Millisecond) // 等待 "aa" 进入写出状态
+		for _, s := range []string{"bb", "cc", "dd"} {
+			if err := mgr.SendBytes(fd, []byte(s)); err != nil {
+				t.Fatalf("SendBytes(%s) error = %v", s, err)
+			}
+		}
+		if got := readN(t, peer, 4); got != "aadd" {
+			t.Errorf("received %q, want %q", got, "aadd")
+		}
+	})
+
+	t.Run("Disconnect", func(t *testing.T) {
+		mgr := NewConnectionManager()
+		fd, peer := enableQueue(t, mgr, &WriteQueueOption{MaxBytes: 4, Policy: OverflowDisconnect}, nil)
+
+		_ = mgr.SendBytes(fd, []byte("aa"))
+		time.Sleep(50 * time.Millisecond)
+		_ = mgr.SendBytes(fd, []byte("bb"))
+		if err := mgr.SendBytes(fd, []byte("cc")); !errors.Is(err, ErrWriteQueueFull) {
+			t.Errorf("SendBytes error = %v, want ErrWriteQueueFull", err)
+		}
+		if _, err := io.ReadAll(peer); err != nil {
+			t.Errorf("peer should see EOF, got %v", err)
+		}
+		if err := mgr.SendBytes(fd, []byte("dd")); !errors.Is(err, ErrConnClosed) {
+			t.Errorf("SendBytes after disconnect error = %v, want ErrConnClosed", err)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		mgr := NewConnectionManager()
+		fd, peer := enableQueue(t, mgr, &WriteQueueOption{MaxBytes: 4, Policy: OverflowBlock}, nil)
+
+		_ = mgr.SendBytes(fd, []byte("aa"))
+		time.Sleep(50 * time.Millisecond)
+		_ = mgr.SendBytes(fd, []byte("bb"))
+
+		received := make(chan string, 1)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			received <- readN(t, peer, 6)
+		}()
+		start := time.Now()
+		if err := mgr.SendBytes(fd, []byte("cc")); err != nil {
+			t.Fatalf("SendBytes error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("SendBytes returned after %v, should block until space is available", elapsed)
+		}
+		if got := <-received; got != "aabbcc" {
+			t.Errorf("received %q", got)
+		}
+	})
+}
+
+func TestWriteQueueTimeout(t *testing.T) {
+	mgr := NewConnectionManager()
+	fd, _ := enableQueue(t, mgr, &WriteQueueOption{MaxBytes: 4, WriteTimeout: 100 * time.Millisecond}, nil)
+
+	_ = mgr.SendBytes(fd, []byte("aa"))
+	time.Sleep(50 * time.Millisecond)
+	_ = mgr.SendBytes(fd, []byte("bb"))
+
+	// 等待空间期间写截止时间到期，连接被关闭
+	start := time.Now()
+	err := mgr.SendBytes(fd, []byte("cc"))
+	if !errors.Is(err, ErrConnClosed) && !errors.Is(err, ErrWriteTimeout) {
+		t.Errorf("SendBytes error = %v, want ErrConnClosed or ErrWriteTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendBytes returned after %v", elapsed)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if err := mgr.SendBytes(fd, []byte("dd")); !errors.Is(err, ErrConnClosed) {
+		t.Errorf("SendBytes after write timeout error = %v, want ErrConnClosed", err)
+	}
+}
+
+func TestWriteQueueWatermarks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	low := make(chan struct{}, 1)
+	callback := &BaseListenerCallback{
+		OnWriteHighWatermark: func(fd int, queued int) {
+			mu.Lock()
+			events = append(events, "high:"+strconv.Itoa(queued))
+			mu.Unlock()
+		},
+		OnWriteLowWatermark: func(fd int, queued int) {
+			mu.Lock()
+			events = append(events, "low:"+strconv.Itoa(queued))
+			mu.Unlock()
+			low <- struct{}{}
+		},
+	}
+
+	mgr := NewConnectionManager()
+	fd, peer := enableQueue(t, mgr, &WriteQueueOption{HighWatermark: 4, LowWatermark: 0}, callback)
+
+	_ = mgr.SendBytes(fd, []byte("aa"))
+	time.Sleep(50 * time.Millisecond)
+	_ = mgr.SendBytes(fd, []byte("bb"))
+	_ = mgr.SendBytes(fd, []byte("cc")) // 已处于高水位，不重复触发
+
+	readN(t, peer, 6)
+	select {
+	case <-low:
+	case <-time.After(time.Second):
+		t.Fatal("low watermark callback not triggered")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(events, ",") != "high:4,low:0" {
+		t.Errorf("watermark events = %v", events)
+	}
+}
+
+func TestWriteQueueTCPBatching(t *testing.T) {
+	const count = 2000
+	connected := make(chan int, 1)
+	server := NewBaseServer(nil)
+	err := server.StartBaseListener(&ServerOption{
+		Protocol:   ProtocolTCP,
+		Addr:       "127.0.0.1",
+		Port:       18098,
+		WriteQueue: &WriteQueueOption{},
+	}, &BaseListenerCallback{
+		OnConnected: func(fd int, connType ConnectionType, connOpt *ConnectOption) {
+			if connType == ConnectionTypeClient {
+				connected <- fd
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.StopBaseListener()
+	time.Sleep(100 * time.Millisecond)
+
+	var mu sync.Mutex
+	var received strings.Builder
+	client := NewBaseClient(nil, &BaseListenerCallback{
+		OnDataReceived: func(fd int, connType ConnectionType, buf []byte, used int) int {
+			mu.Lock()
+			received.Write(buf[:used])
+			mu.Unlock()
+			return used
+		},
+	})
+	if _, err := client.Connect(&ClientOption{Protocol: ProtocolTCP, RemoteIP: "127.0.0.1", RemotePort: 18098}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	fd := <-connected
+	var want strings.Builder
+	for i := 0; i < count; i++ {
+		msg := strconv.Itoa(i) + ";"
+		want.WriteString(msg)
+		if err := server.SendBytes(fd, []byte(msg)); err != nil {
+			t.Fatalf("SendBytes error = %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := received.Len()
+		mu.Unlock()
+		if n >= want.Len() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if received.String() != want.String() {
+		t.Errorf("received %d bytes out of order or incomplete, want %d", received.Len(), want.Len())
+	}
+}
@@ -0,0 +1,521 @@
+package taskpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DAGFunc 依赖任务函数，deps 为各父任务的返回值（按任务ID），返回值传给下游任务
+type DAGFunc func(ctx context.Context, deps map[string]interface{}) (interface{}, error)
+
+// FailurePolicy 任务失败时的处理策略
+type FailurePolicy int
+
+const (
+	// CancelDependents 取消失败任务的所有下游任务，其他分支继续执行（默认）
+	CancelDependents FailurePolicy = iota
+	// CancelAll 取消所有未开始的任务，并取消运行中任务的 context
+	CancelAll
+)
+
+// DAGOption DAG配置选项
+type DAGOption func(*DAG)
+
+// WithDAGID 设置DAG ID（聚合Future的任务ID，节点任务ID为 "DAG ID:节点ID"）
+func WithDAGID(id string) DAGOption {
+	return func(d *DAG) {
+		d.id = id
+	}
+}
+
+// WithFailurePolicy 设置失败处理策略
+func WithFailurePolicy(policy FailurePolicy) DAGOption {
+	return func(d *DAG) {
+		d.policy = policy
+	}
+}
+
+// dagNode DAG节点定义
+type dagNode struct {
+	id   string
+	fn   DAGFunc
+	deps []string
+	opts []TaskOption
+}
+
+// DAG 有依赖关系的任务集合
+// 添加任务时依赖可以引用之后添加的任务，提交时检查未知依赖和环
+type DAG struct {
+	mu     sync.Mutex
+	id     string
+	policy FailurePolicy
+	nodes  map[string]*dagNode
+	order  []string // 添加顺序
+}
+
+// NewDAG 创建DAG
+func NewDAG(opts ...DAGOption) *DAG {
+	d := &DAG{
+		id:    generateDAGID(),
+		nodes: make(map[string]*dagNode),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Add 添加任务，deps 为依赖的任务ID
+func (d *DAG) Add(id string, fn DAGFunc, deps ...string) error {
+	return d.AddWithOptions(id, fn, deps)
+}
+
+// AddWithOptions 添加任务并设置任务选项（优先级、超时等）
+func (d *DAG) AddWithOptions(id string, fn DAGFunc, deps []string, opts ...TaskOption) error {
+	if id == "" {
+		return errors.New("taskpool: dag task id cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("taskpool: dag task func cannot be nil")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.nodes[id]; exists {
+		return fmt.Errorf("taskpool: dag task %s already exists", id)
+	}
+
+	// 去掉重复依赖
+	node := &dagNode{id: id, fn: fn, opts: opts}
+	seen := make(map[string]bool, len(deps))
+	for _, dep := range deps {
+		if !seen[dep] {
+			seen[dep] = true
+			node.deps = append(node.deps, dep)
+		}
+	}
+
+	d.nodes[id] = node
+	d.order = append(d.order, id)
+	return nil
+}
+
+// Len 返回任务数
+func (d *DAG) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.order)
+}
+
+// Validate 检查未知依赖和环
+func (d *DAG) Validate() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.validate()
+}
+
+// validate 检查DAG（调用方持有锁）
+func (d *DAG) validate() error {
+	for _, id := range d.order {
+		for _, dep := range d.nodes[id].deps {
+			if _, ok := d.nodes[dep]; !ok {
+				return fmt.Errorf("%w: %s depends on %s", ErrDAGUnknownDependency, id, dep)
+			}
+		}
+	}
+
+	// 深度优先搜索，记录路径用于报告环
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(d.order))
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			start := 0
+			for i, p := range path {
+				if p == id {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string(nil), path[start:]...), id)
+			return fmt.Errorf("%w: %s", ErrDAGCycle, strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range d.nodes[id].deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, id := range d.order {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateDAGID 生成DAG ID
+var dagIDCounter atomic.Uint64
+
+func generateDAGID() string {
+	return fmt.Sprintf("dag-%d", dagIDCounter.Add(1))
+}
+
+// DAGNodeState 节点状态
+type DAGNodeState int32
+
+const (
+	DAGNodePending   DAGNodeState = iota // 等待依赖完成
+	DAGNodeRunning                       // 已提交到协程池
+	DAGNodeSucceeded                     // 执行成功
+	DAGNodeFailed                        // 执行失败（错误、超时或panic）
+	DAGNodeCanceled                      // 因依赖失败或DAG被取消而未执行
+)
+
+// String 返回状态名称
+func (s DAGNodeState) String() string {
+	switch s {
+	case DAGNodePending:
+		return "pending"
+	case DAGNodeRunning:
+		return "running"
+	case DAGNodeSucceeded:
+		return "succeeded"
+	case DAGNodeFailed:
+		return "failed"
+	case DAGNodeCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// DAGNodeResult 节点执行结果
+type DAGNodeResult struct {
+	ID     string
+	State  DAGNodeState
+	Value  interface{} // 任务返回值
+	Err    error
+	Result *TaskResult // 协程池的执行结果（未提交的节点为 nil）
+}
+
+// dagRunNode 一次执行中的节点状态
+type dagRunNode struct {
+	def      *dagNode
+	children []*dagRunNode
+	waiting  int // 未完成的父任务数
+	state    DAGNodeState
+	output   interface{}
+	err      error
+	result   *TaskResult
+}
+
+// DAGFuture DAG的聚合Future
+// 所有节点结束后完成：TaskID 为DAG ID，有任务失败时 Err 包装 ErrDAGFailed 和各失败任务的错误
+type DAGFuture struct {
+	*Future
+	pool      *TaskPool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	policy    FailurePolicy
+	startTime time.Time
+	done      chan struct{}
+
+	mu        sync.Mutex
+	nodes     map[string]*dagRunNode
+	order     []string
+	remaining int // 未结束的节点数
+}
+
+// SubmitDAG 提交DAG：没有依赖的任务立即提交，其余任务在父任务全部成功后提交
+// ctx 取消时不再提交新任务，运行中任务的 context 同时被取消
+func (p *TaskPool) SubmitDAG(ctx context.Context, d *DAG) *DAGFuture {
+	d.mu.Lock()
+	run := &DAGFuture{
+		Future:    newFuture(d.id),
+		pool:      p,
+		policy:    d.policy,
+		startTime: time.Now(),
+		done:      make(chan struct{}),
+		nodes:     make(map[string]*dagRunNode, len(d.order)),
+		order:     append([]string(nil), d.order...),
+		remaining: len(d.order),
+	}
+	err := d.validate()
+	if err == nil {
+		for _, id := range d.order {
+			run.nodes[id] = &dagRunNode{def: d.nodes[id], waiting: len(d.nodes[id].deps)}
+		}
+	}
+	d.mu.Unlock()
+
+	if err == nil && p.closed.Load() {
+		err = ErrPoolClosed
+	}
+	if err != nil {
+		run.Future.complete(&TaskResult{TaskID: run.taskID, Err: err})
+		close(run.done)
+		return run
+	}
+
+	for _, node := range run.nodes {
+		for _, dep := range node.def.deps {
+			parent := run.nodes[dep]
+			parent.children = append(parent.children, node)
+		}
+	}
+
+	run.ctx, run.cancel = context.WithCancel(ctx)
+	p.metrics.recordDAGSubmit(len(run.order))
+
+	run.mu.Lock()
+	var ready []*dagRunNode
+	for _, id := range run.order {
+		if node := run.nodes[id]; node.waiting == 0 {
+			ready = append(ready, run.start(node))
+		}
+	}
+	if run.remaining == 0 {
+		run.finish()
+	}
+	run.mu.Unlock()
+
+	go run.watch()
+	run.submit(ready)
+	return run
+}
+
+// Result 返回节点的执行结果
+func (f *DAGFuture) Result(id string) (DAGNodeResult, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	node, ok := f.nodes[id]
+	if !ok {
+		return DAGNodeResult{}, false
+	}
+	return node.snapshot(), true
+}
+
+// Results 返回所有节点的执行结果（按添加顺序）
+func (f *DAGFuture) Results() []DAGNodeResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	results := make([]DAGNodeResult, 0, len(f.nodes))
+	for _, id := range f.order {
+		if node, ok := f.nodes[id]; ok {
+			results = append(results, node.snapshot())
+		}
+	}
+	return results
+}
+
+// Progress 返回已结束的节点数和节点总数
+func (f *DAGFuture) Progress() (finished, total int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.nodes) - f.remaining, len(f.nodes)
+}
+
+// Cancel 取消DAG：不再提交新任务，并取消运行中任务的 context
+func (f *DAGFuture) Cancel() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
+// snapshot 生成节点结果（调用方持有锁）
+func (n *dagRunNode) snapshot() DAGNodeResult {
+	return DAGNodeResult{
+		ID:     n.def.id,
+		State:  n.state,
+		Value:  n.output,
+		Err:    n.err,
+		Result: n.result,
+	}
+}
+
+// watch 等待 ctx 取消，取消所有未提交的节点
+func (f *DAGFuture) watch() {
+	select {
+	case <-f.done:
+	case <-f.ctx.Done():
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.remaining == 0 {
+			// 已完成（finish 会取消 ctx）
+			return
+		}
+		for _, id := range f.order {
+			if node := f.nodes[id]; node.state == DAGNodePending {
+				f.cancelNode(node, f.ctx.Err())
+			}
+		}
+		if f.remaining == 0 {
+			f.finish()
+		}
+	}
+}
+
+// start 标记节点为运行中（调用方持有锁）
+func (f *DAGFuture) start(node *dagRunNode) *dagRunNode {
+	node.state = DAGNodeRunning
+	f.pool.metrics.dagPendingTasks.Add(-1)
+	return node
+}
+
+// submit 将节点提交到协程池，并等待其结果
+func (f *DAGFuture) submit(nodes []*dagRunNode) {
+	for _, node := range nodes {
+		f.mu.Lock()
+		deps := make(map[string]interface{}, len(node.def.deps))
+		for _, dep := range node.def.deps {
+			deps[dep] = f.nodes[dep].output
+		}
+		f.mu.Unlock()
+
+		node := node
+		fn := func(taskCtx context.Context) error {
+			// 合并任务超时和DAG取消
+			ctx, cancel := context.WithCancel(f.ctx)
+			defer cancel()
+			stop := context.AfterFunc(taskCtx, cancel)
+			defer stop()
+
+			value, err := node.def.fn(ctx, deps)
+			if err == nil {
+				f.mu.Lock()
+				if node.state == DAGNodeRunning {
+					node.output = value
+				}
+				f.mu.Unlock()
+			}
+			return err
+		}
+
+		opts := append(append([]TaskOption(nil), node.def.opts...), WithTaskID(f.taskID+":"+node.def.id))
+		future := f.pool.Submit(fn, opts...)
+		go func() {
+			f.complete(node, <-future.Wait())
+		}()
+	}
+}
+
+// complete 处理节点结束：成功时提交就绪的子节点，失败时按策略取消
+func (f *DAGFuture) complete(node *dagRunNode, result *TaskResult) {
+	f.mu.Lock()
+	node.result = result
+	f.remaining--
+
+	var ready []*dagRunNode
+	switch {
+	case result.Err == nil:
+		node.state = DAGNodeSucceeded
+		for _, child := range node.children {
+			child.waiting--
+			if child.waiting == 0 && child.state == DAGNodePending {
+				ready = append(ready, f.start(child))
+			}
+		}
+	case f.ctx.Err() != nil && errors.Is(result.Err, context.Canceled):
+		// DAG 被取消导致的失败
+		node.state = DAGNodeCanceled
+		node.output = nil
+		node.err = result.Err
+		f.pool.metrics.dagCanceledTasks.Add(1)
+		f.cancelDependents(node, result.Err)
+	default:
+		node.state = DAGNodeFailed
+		node.output = nil
+		node.err = result.Err
+		cause := fmt.Errorf("%w: %s", ErrDAGDependencyFailed, node.def.id)
+		if f.policy == CancelAll {
+			f.cancel()
+			for _, id := range f.order {
+				if n := f.nodes[id]; n.state == DAGNodePending {
+					f.cancelNode(n, cause)
+				}
+			}
+		} else {
+			f.cancelDependents(node, cause)
+		}
+	}
+
+	if f.remaining == 0 {
+		f.finish()
+	}
+	f.mu.Unlock()
+
+	f.submit(ready)
+}
+
+// cancelDependents 取消节点的所有未开始的下游节点（调用方持有锁）
+func (f *DAGFuture) cancelDependents(node *dagRunNode, cause error) {
+	for _, child := range node.children {
+		if child.state == DAGNodePending {
+			f.cancelNode(child, cause)
+			f.cancelDependents(child, cause)
+		}
+	}
+}
+
+// cancelNode 取消未开始的节点（调用方持有锁）
+func (f *DAGFuture) cancelNode(node *dagRunNode, cause error) {
+	node.state = DAGNodeCanceled
+	node.err = cause
+	f.remaining--
+	f.pool.metrics.dagPendingTasks.Add(-1)
+	f.pool.metrics.dagCanceledTasks.Add(1)
+}
+
+// finish 所有节点结束后完成聚合Future（调用方持有锁）
+func (f *DAGFuture) finish() {
+	result := &TaskResult{
+		TaskID:    f.taskID,
+		StartTime: f.startTime,
+		EndTime:   time.Now(),
+	}
+	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	var errs []error
+	for _, id := range f.order {
+		if node := f.nodes[id]; node.state == DAGNodeFailed {
+			errs = append(errs, fmt.Errorf("task %s: %w", id, node.err))
+		}
+	}
+	switch {
+	case len(errs) > 0:
+		result.Err = fmt.Errorf("%w: %w", ErrDAGFailed, errors.Join(errs...))
+	case f.ctx.Err() != nil:
+		result.Err = f.ctx.Err()
+	}
+
+	f.pool.metrics.recordDAGComplete(result.Err != nil)
+	close(f.done)
+	f.cancel()
+	f.Future.complete(result)
+}
@@ -0,0 +1,277 @@
+package taskpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDAG_Pipeline(t *testing.T) {
+	pool := New(WithQueueSize(10), WithMinWorkers(4))
+	defer func() { _ = pool.ShutdownNow() }()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(id string) {
+		mu.Lock()
+		order = append(order, id)
+		mu.Unlock()
+	}
+
+	dag := NewDAG(WithDAGID("pipeline"))
+	_ = dag.Add("c", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		record("c")
+		return deps["a"].(int) + deps["b"].(int), nil
+	}, "a", "b")
+	_ = dag.Add("a", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		record("a")
+		return 1, nil
+	})
+	_ = dag.Add("b", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		record("b")
+		return 2, nil
+	})
+	_ = dag.Add("d", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		record("d")
+		return deps["c"].(int) * 10, nil
+	}, "c", "c")
+
+	future := pool.SubmitDAG(context.Background(), dag)
+	result := <-future.Wait()
+	if result.Err != nil {
+		t.Fatalf("DAG failed: %v", result.Err)
+	}
+	if result.TaskID != "pipeline" {
+		t.Errorf("TaskID = %s, want pipeline", result.TaskID)
+	}
+
+	d, ok := future.Result("d")
+	if !ok || d.State != DAGNodeSucceeded || d.Value != 30 {
+		t.Errorf("Result(d) = %+v, want succeeded with 30", d)
+	}
+	if d.Result == nil || d.Result.TaskID != "pipeline:d" {
+		t.Errorf("Result(d).Result = %+v, want task id pipeline:d", d.Result)
+	}
+	if len(order) != 4 || order[2] != "c" || order[3] != "d" {
+		t.Errorf("execution order = %v, want c and d after a and b", order)
+	}
+	if finished, total := future.Progress(); finished != 4 || total != 4 {
+		t.Errorf("Progress() = %d/%d, want 4/4", finished, total)
+	}
+
+	results := future.Results()
+	if len(results) != 4 || results[0].ID != "c" {
+		t.Errorf("Results() = %+v, want 4 results in insertion order", results)
+	}
+
+	metrics := pool.GetMetrics()
+	if metrics.DAGSubmitted != 1 || metrics.DAGCompleted != 1 || metrics.DAGFailed != 0 || metrics.DAGPendingTasks != 0 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+}
+
+func TestDAG_Validate(t *testing.T) {
+	noop := func(ctx context.Context, deps map[string]interface{}) (interface{}, error) { return nil, nil }
+
+	dag := NewDAG()
+	_ = dag.Add("a", noop, "c")
+	_ = dag.Add("b", noop, "a")
+	_ = dag.Add("c", noop, "b")
+	if err := dag.Validate(); !errors.Is(err, ErrDAGCycle) {
+		t.Errorf("Validate() = %v, want ErrDAGCycle", err)
+	} else if want := "taskpool: dag has a cycle: a -> c -> b -> a"; err.Error() != want {
+		t.Errorf("Validate() = %q, want %q", err.Error(), want)
+	}
+
+	self := NewDAG()
+	_ = self.Add("a", noop, "a")
+	if err := self.Validate(); !errors.Is(err, ErrDAGCycle) {
+		t.Errorf("Validate() = %v, want ErrDAGCycle", err)
+	}
+
+	unknown := NewDAG()
+	_ = unknown.Add("a", noop, "missing")
+	if err := unknown.Validate(); !errors.Is(err, ErrDAGUnknownDependency) {
+		t.Errorf("Validate() = %v, want ErrDAGUnknownDependency", err)
+	}
+
+	if err := unknown.Add("a", noop); err == nil {
+		t.Error("duplicate task should fail")
+	}
+	if err := unknown.Add("", noop); err == nil {
+		t.Error("empty task id should fail")
+	}
+	if err := unknown.Add("x", nil); err == nil {
+		t.Error("nil task func should fail")
+	}
+
+	// 提交无效的DAG时聚合Future直接返回错误
+	pool := New(WithQueueSize(10), WithMinWorkers(1))
+	defer func() { _ = pool.ShutdownNow() }()
+	result := <-pool.SubmitDAG(context.Background(), dag).Wait()
+	if !errors.Is(result.Err, ErrDAGCycle) {
+		t.Errorf("SubmitDAG error = %v, want ErrDAGCycle", result.Err)
+	}
+}
+
+func TestDAG_CancelDependents(t *testing.T) {
+	pool := New(WithQueueSize(10), WithMinWorkers(4))
+	defer func() { _ = pool.ShutdownNow() }()
+
+	boom := errors.New("boom")
+	var ran atomic.Int32
+	ok := func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		ran.Add(1)
+		return "ok", nil
+	}
+
+	dag := NewDAG()
+	_ = dag.Add("fail", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		return nil, boom
+	})
+	_ = dag.Add("child", ok, "fail")
+	_ = dag.Add("grandchild", ok, "child")
+	_ = dag.Add("other", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return ok(ctx, deps)
+	})
+	_ = dag.Add("after-other", ok, "other")
+
+	future := pool.SubmitDAG(context.Background(), dag)
+	result := <-future.Wait()
+	if !errors.Is(result.Err, ErrDAGFailed) || !errors.Is(result.Err, boom) {
+		t.Fatalf("DAG error = %v, want ErrDAGFailed wrapping boom", result.Err)
+	}
+
+	want := map[string]DAGNodeState{
+		"fail":        DAGNodeFailed,
+		"child":       DAGNodeCanceled,
+		"grandchild":  DAGNodeCanceled,
+		"other":       DAGNodeSucceeded,
+		"after-other": DAGNodeSucceeded,
+	}
+	for id, state := range want {
+		if r, _ := future.Result(id); r.State != state {
+			t.Errorf("%s state = %s, want %s", id, r.State, state)
+		}
+	}
+	if r, _ := future.Result("grandchild"); !errors.Is(r.Err, ErrDAGDependencyFailed) {
+		t.Errorf("grandchild error = %v, want ErrDAGDependencyFailed", r.Err)
+	}
+	if ran.Load() != 2 {
+		t.Errorf("ran = %d, want 2", ran.Load())
+	}
+
+	metrics := pool.GetMetrics()
+	if metrics.DAGFailed != 1 || metrics.DAGCanceledTasks != 2 || metrics.DAGPendingTasks != 0 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+}
+
+func TestDAG_CancelAll(t *testing.T) {
+	pool := New(WithQueueSize(10), WithMinWorkers(4))
+	defer func() { _ = pool.ShutdownNow() }()
+
+	dag := NewDAG(WithFailurePolicy(CancelAll))
+	_ = dag.Add("fail", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		return nil, errors.New("boom")
+	})
+	_ = dag.Add("slow", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_ = dag.Add("after-slow", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	}, "slow")
+
+	future := pool.SubmitDAG(context.Background(), dag)
+	select {
+	case result := <-future.Wait():
+		if !errors.Is(result.Err, ErrDAGFailed) {
+			t.Fatalf("DAG error = %v, want ErrDAGFailed", result.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CancelAll should cancel running tasks")
+	}
+
+	for id, state := range map[string]DAGNodeState{
+		"fail":       DAGNodeFailed,
+		"slow":       DAGNodeCanceled,
+		"after-slow": DAGNodeCanceled,
+	} {
+		if r, _ := future.Result(id); r.State != state {
+			t.Errorf("%s state = %s, want %s", id, r.State, state)
+		}
+	}
+}
+
+func TestDAG_ContextCancel(t *testing.T) {
+	pool := New(WithQueueSize(10), WithMinWorkers(2))
+	defer func() { _ = pool.ShutdownNow() }()
+
+	started := make(chan struct{})
+	dag := NewDAG()
+	_ = dag.Add("block", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_ = dag.Add("next", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		t.Error("next should not run")
+		return nil, nil
+	}, "block")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	future := pool.SubmitDAG(ctx, dag)
+	<-started
+	cancel()
+
+	result := <-future.Wait()
+	if !errors.Is(result.Err, context.Canceled) {
+		t.Fatalf("DAG error = %v, want context.Canceled", result.Err)
+	}
+	if r, _ := future.Result("next"); r.State != DAGNodeCanceled {
+		t.Errorf("next state = %s, want canceled", r.State)
+	}
+}
+
+func TestDAG_TaskOptions(t *testing.T) {
+	pool := New(WithQueueSize(10), WithMinWorkers(2))
+	defer func() { _ = pool.ShutdownNow() }()
+
+	dag := NewDAG()
+	_ = dag.AddWithOptions("slow", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return "late", nil
+	}, nil, WithTimeout(20*time.Millisecond))
+
+	future := pool.SubmitDAG(context.Background(), dag)
+	result := <-future.Wait()
+	if !errors.Is(result.Err, ErrTimeout) {
+		t.Fatalf("DAG error = %v, want ErrTimeout", result.Err)
+	}
+	if r, _ := future.Result("slow"); r.State != DAGNodeFailed || r.Value != nil {
+		t.Errorf("slow = %+v, want failed without value", r)
+	}
+}
+
+func TestDAG_EmptyAndClosedPool(t *testing.T) {
+	pool := New(WithQueueSize(10), WithMinWorkers(1))
+
+	empty := pool.SubmitDAG(context.Background(), NewDAG())
+	if result := <-empty.Wait(); result.Err != nil {
+		t.Errorf("empty DAG error = %v", result.Err)
+	}
+
+	_ = pool.ShutdownNow()
+	dag := NewDAG()
+	_ = dag.Add("a", func(ctx context.Context, deps map[string]interface{}) (interface{}, error) { return nil, nil })
+	if result := <-pool.SubmitDAG(context.Background(), dag).Wait(); result.Err != ErrPoolClosed {
+		t.Errorf("SubmitDAG on closed pool error = %v, want ErrPoolClosed", result.Err)
+	}
+}
@@ -20,4 +20,16 @@ var (
 
 	// ErrTaskPanic 任务执行panic
 	ErrTaskPanic = errors.New("taskpool: task panic")
+
+	// ErrDAGCycle DAG存在环
+	ErrDAGCycle = errors.New("taskpool: dag has a cycle")
+
+	// ErrDAGUnknownDependency DAG任务依赖不存在的任务
+	ErrDAGUnknownDependency = errors.New("taskpool: dag task depends on unknown task")
+
+	// ErrDAGDependencyFailed 依赖的任务失败，任务被取消
+	ErrDAGDependencyFailed = errors.New("taskpool: dag dependency failed")
+
+	// ErrDAGFailed DAG中有任务失败
+	ErrDAGFailed = errors.New("taskpool: dag failed")
 )
@@ -14,6 +14,12 @@ type Metrics struct {
 	totalPanic     atomic.Int64 // 总panic数
 	totalWaitTime  atomic.Int64 // 总等待时间（纳秒）
 	totalExecTime  atomic.Int64 // 总执行时间（纳秒）
+
+	dagSubmitted     atomic.Int64 // DAG提交数
+	dagCompleted     atomic.Int64 // DAG完成数
+	dagFailed        atomic.Int64 // DAG失败数
+	dagPendingTasks  atomic.Int64 // 等待依赖完成的DAG任务数
+	dagCanceledTasks atomic.Int64 // 被取消的DAG任务数
 }
 
 // newMetrics 创建指标统计
@@ -44,6 +50,19 @@ func (m *Metrics) recordTaskComplete(result *TaskResult) {
 	m.totalExecTime.Add(result.Duration.Nanoseconds())
 }
 
+// recordDAGSubmit 记录DAG提交
+func (m *Metrics) recordDAGSubmit(tasks int) {
+	m.dagSubmitted.Add(1)
+	m.dagPendingTasks.Add(int64(tasks))
+}
+
+// recordDAGComplete 记录DAG完成
+func (m *Metrics) recordDAGComplete(failed bool) {
+	m.dagCompleted.Add(1)
+	if failed {
+		m.dagFailed.Add(1)
+	}
+}
 
 // snapshot 生成快照
 func (m *Metrics) snapshot(queueLen, runningTasks, activeWorkers int) *MetricsSnapshot {
@@ -78,6 +97,12 @@ func (m *Metrics) snapshot(queueLen, runningTasks, activeWorkers int) *MetricsSn
 		CurrentQueue:   queueLen,
 		RunningTasks:   runningTasks,
 		ActiveWorkers:  activeWorkers,
+
+		DAGSubmitted:     m.dagSubmitted.Load(),
+		DAGCompleted:     m.dagCompleted.Load(),
+		DAGFailed:        m.dagFailed.Load(),
+		DAGPendingTasks:  m.dagPendingTasks.Load(),
+		DAGCanceledTasks: m.dagCanceledTasks.Load(),
 	}
 }
 
@@ -94,4 +119,10 @@ type MetricsSnapshot struct {
 	CurrentQueue   int           // 当前队列长度
 	RunningTasks   int           // 运行中任务数
 	ActiveWorkers  int           // 活跃工作协程数
+
+	DAGSubmitted     int64 // DAG提交数
+	DAGCompleted     int64 // DAG完成数（含失败）
+	DAGFailed        int64 // DAG失败数
+	DAGPendingTasks  int64 // 等待依赖完成的DAG任务数
+	DAGCanceledTasks int64 // 因依赖失败或DAG取消而未执行的任务数
 }
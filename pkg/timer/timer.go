@@ -4,6 +4,8 @@ package timer
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -363,18 +365,50 @@ func Retry(attempts int, delay time.Duration, fn func() error) error {
 // 返回: 若成功返回nil，否则返回最后一次错误
 func ExponentialBackoff(attempts int, initialDelay time.Duration, fn func() error) error {
 	var err error
-	delay := initialDelay // 初始延迟
-
 	for i := 0; i < attempts; i++ {
 		if err = fn(); err == nil {
 			return nil // 成功则直接返回
 		}
 		// 不是最后一次尝试则等待后重试，延迟翻倍
 		if i < attempts-1 {
-			time.Sleep(delay)
-			delay *= 2
+			time.Sleep(BackoffDelay(i+1, initialDelay, 0, 0))
 		}
 	}
 	return fmt.Errorf("after %d attempts with exponential backoff, last error: %w", attempts, err)
 }
 
+// BackoffDelay 计算指数退避中第 retry 次重试前的等待时间，与 ExponentialBackoff 一致：
+// 第1次重试等待 initialDelay，之后每次翻倍
+// 参数:
+//   retry: 重试序号（从1开始）
+//   initialDelay: 初始延迟时间
+//   maxDelay: 延迟上限（0表示不限）
+//   jitter: 随机抖动比例（0~1），实际延迟在 [d*(1-jitter), d*(1+jitter)] 内均匀分布，避免大量客户端同时重试
+// 返回: 本次重试前应等待的时间
+func BackoffDelay(retry int, initialDelay, maxDelay time.Duration, jitter float64) time.Duration {
+	if retry < 1 || initialDelay <= 0 {
+		return 0
+	}
+
+	delay := initialDelay
+	for i := 1; i < retry; i++ {
+		// 达到上限后不再翻倍，同时避免溢出
+		if (maxDelay > 0 && delay >= maxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if jitter > 0 {
+		jitter = min(jitter, 1)
+		delay = time.Duration(float64(delay) * (1 - jitter + 2*jitter*rand.Float64()))
+		if maxDelay > 0 && delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+	return delay
+}
+
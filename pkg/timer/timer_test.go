@@ -466,3 +466,35 @@ func TestScenario20_ConcurrentCreate(t *testing.T) {
 		t.Errorf("期望10个定时器，实际 %d 个", mgr.GetTimerCount())
 	}
 }
+
+// 场景：退避延迟计算
+func TestBackoffDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+
+	// 与 ExponentialBackoff 一致：100ms, 200ms, 400ms...
+	for retry, want := range map[int]time.Duration{0: 0, 1: base, 2: 2 * base, 3: 4 * base} {
+		if got := BackoffDelay(retry, base, 0, 0); got != want {
+			t.Errorf("BackoffDelay(%d) = %v, want %v", retry, got, want)
+		}
+	}
+
+	// 上限
+	if got := BackoffDelay(10, base, time.Second, 0); got != time.Second {
+		t.Errorf("BackoffDelay 应被限制在1s，实际 %v", got)
+	}
+	// 大量重试不应溢出
+	if got := BackoffDelay(1000, base, 0, 0); got <= 0 {
+		t.Errorf("BackoffDelay 溢出，实际 %v", got)
+	}
+
+	// 抖动范围 [200ms, 600ms]，且不超过上限
+	for i := 0; i < 100; i++ {
+		got := BackoffDelay(3, base, 0, 0.5)
+		if got < 2*base || got > 6*base {
+			t.Fatalf("抖动后延迟 %v 超出范围", got)
+		}
+		if got := BackoffDelay(10, base, time.Second, 0.5); got > time.Second {
+			t.Fatalf("抖动后延迟 %v 超过上限", got)
+		}
+	}
+}
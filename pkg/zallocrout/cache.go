@@ -21,6 +21,9 @@ type cacheEntry struct {
 	paramPairs  [MaxParams]paramPair // 参数数组
 	paramCount  int                  // 参数数量
 	timestamp   int64                // 时间戳（LRU 淘汰用）
+	table       *routeTable          // 写入时的路由表（Swap 后旧表的条目不再命中）
+	node        *RouteNode           // 匹配到的路由节点（RemoveRoute 时按节点失效）
+	removed     atomic.Bool          // 路由已被 RemoveRoute 删除，节点可能已归还节点池
 }
 
 // 复合缓存键
@@ -182,6 +185,32 @@ func (sm *shardedMap) evictLRU(shardIdx int) {
 	shard.mu.Unlock()
 }
 
+// 删除满足条件的条目（Copy-on-Write，路由变更时调用，不在热路径）
+func (sm *shardedMap) DeleteFunc(match func(key cacheKey, entry *cacheEntry) bool) {
+	for i := 0; i < shardCount; i++ {
+		shard := &sm.shards[i]
+		shard.mu.Lock()
+		oldEntries := *shard.entries.Load()
+		removed := 0
+		for k, v := range oldEntries {
+			if match(k, v) {
+				removed++
+			}
+		}
+		if removed > 0 {
+			newEntries := make(map[cacheKey]*cacheEntry, len(oldEntries)-removed)
+			for k, v := range oldEntries {
+				if !match(k, v) {
+					newEntries[k] = v
+				}
+			}
+			shard.entries.Store(&newEntries)
+			atomic.AddInt64(&shard.count, -int64(removed))
+		}
+		shard.mu.Unlock()
+	}
+}
+
 // 清空所有缓存
 func (sm *shardedMap) Clear() {
 	for i := 0; i < shardCount; i++ {
@@ -0,0 +1,317 @@
+// Package cli 将 zallocrout.Router 适配为命令行命令路由
+// 命令模式（如 "user add :name"、"file cat *path"）按空格切分为路由片段注册，
+// 命令行参数切分出选项后按位置参数匹配路由，并通过 zallocrout.ExecuteHandler 执行中间件链
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+)
+
+// Method 命令路由注册使用的方法名
+const Method = "CLI"
+
+// 处理函数通过以下键从 context 获取命令行信息（zallocrout.SetValue 仅支持字符串键）
+const (
+	ArgsKey   = "cli.args"   // 位置参数（[]string，不含选项）
+	FlagsKey  = "cli.flags"  // 选项值（Flags）
+	StdoutKey = "cli.stdout" // 标准输出（io.Writer）
+	StderrKey = "cli.stderr" // 标准错误（io.Writer）
+)
+
+// ErrUnknownCommand 位置参数不匹配任何命令
+var ErrUnknownCommand = errors.New("unknown command")
+
+// 内置的帮助选项
+var helpFlag = Flag{Name: "help", Short: "h", Usage: "show help", Bool: true}
+
+// Command 命令定义
+type Command struct {
+	Pattern     string                  // 命令模式，如 "user add :name"、"file cat *path"、"user get :id<int>"
+	Short       string                  // 一行说明，显示在命令列表中
+	Long        string                  // 详细说明，显示在命令帮助中（为空时使用 Short）
+	Flags       []Flag                  // 命令支持的选项
+	Handler     zallocrout.HandlerFunc  // 处理函数
+	Middlewares []zallocrout.Middleware // 命令中间件
+
+	params []string // 参数名（通配符为 "*"），用于还原转义后的参数值
+}
+
+// Option 应用配置选项
+type Option func(*App)
+
+// WithStdout 设置标准输出，默认 os.Stdout
+func WithStdout(w io.Writer) Option {
+	return func(a *App) {
+		a.stdout = w
+	}
+}
+
+// WithStderr 设置标准错误，默认 os.Stderr
+func WithStderr(w io.Writer) Option {
+	return func(a *App) {
+		a.stderr = w
+	}
+}
+
+// WithDescription 设置应用说明，显示在帮助开头
+func WithDescription(desc string) Option {
+	return func(a *App) {
+		a.description = desc
+	}
+}
+
+// App 命令行应用
+// 命令注册为 Method 方法下的路由，帮助和补全信息从路由表生成
+type App struct {
+	name        string
+	description string
+	router      *zallocrout.Router
+	commands    map[string]*Command // 路由路径 → 命令
+	flags       *flagIndex
+	stdout      io.Writer
+	stderr      io.Writer
+}
+
+// New 创建命令行应用，name 为帮助中显示的程序名
+func New(name string, opts ...Option) *App {
+	a := &App{
+		name:     name,
+		router:   zallocrout.NewRouter(),
+		commands: make(map[string]*Command),
+		flags:    newFlagIndex(),
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+	}
+	_ = a.flags.add(&helpFlag)
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Router 返回底层路由器（可用于查看路由表或指标）
+func (a *App) Router() *zallocrout.Router {
+	return a.router
+}
+
+// Use 注册全局中间件，作用于所有命令（包括已注册的命令）
+// 全局中间件在命令选项校验之前执行，此时 Flags 中尚未填充默认值，路由参数仍为转义形式
+func (a *App) Use(middlewares ...zallocrout.Middleware) {
+	a.router.Use(middlewares...)
+}
+
+// AddCommand 注册命令
+func (a *App) AddCommand(cmd Command) error {
+	if cmd.Handler == nil {
+		return errors.New("command handler cannot be nil")
+	}
+	path := patternPath(cmd.Pattern)
+	if path == "/" {
+		return errors.New("command pattern cannot be empty")
+	}
+
+	c := cmd
+	c.params = patternParams(cmd.Pattern)
+	c.Flags = append([]Flag(nil), cmd.Flags...)
+	for i := range c.Flags {
+		if c.Flags[i].Name == helpFlag.Name {
+			return errors.New("flag --help is reserved")
+		}
+		if err := a.flags.add(&c.Flags[i]); err != nil {
+			return err
+		}
+	}
+
+	middlewares := make([]zallocrout.Middleware, 0, len(cmd.Middlewares)+1)
+	middlewares = append(middlewares, c.bind)
+	middlewares = append(middlewares, cmd.Middlewares...)
+	if err := a.router.AddRoute(Method, path, cmd.Handler, middlewares...); err != nil {
+		return err
+	}
+	a.commands[path] = &c
+	return nil
+}
+
+// Run 使用 context.Background 执行命令
+func (a *App) Run(args []string) error {
+	return a.RunContext(context.Background(), args)
+}
+
+// RunContext 执行命令
+// 无参数、"help [command...]" 或带 --help/-h 时输出帮助；位置参数不匹配任何命令时返回 ErrUnknownCommand
+func (a *App) RunContext(parent context.Context, args []string) error {
+	words, flags, err := a.flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return a.writeHelp(nil)
+	}
+	if words[0] == "help" && a.commands["/help"] == nil {
+		return a.writeHelp(words[1:])
+	}
+	if flags.Bool(helpFlag.Name) {
+		return a.writeHelp(words)
+	}
+
+	ctx, handler, middlewares, ok := a.router.Match(Method, wordsPath(words), parent)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, strings.Join(words, " "))
+	}
+
+	zallocrout.SetValue(ctx, ArgsKey, words)
+	zallocrout.SetValue(ctx, FlagsKey, flags)
+	zallocrout.SetValue(ctx, StdoutKey, a.stdout)
+	zallocrout.SetValue(ctx, StderrKey, a.stderr)
+
+	// 执行处理器（自动释放 context）
+	return zallocrout.ExecuteHandler(ctx, handler, middlewares)
+}
+
+// bind 命令的第一个中间件：还原转义后的参数值，拒绝命令未声明的选项并填充默认值
+func (c *Command) bind(next zallocrout.HandlerFunc) zallocrout.HandlerFunc {
+	return func(ctx context.Context) error {
+		for _, name := range c.params {
+			if value, ok := zallocrout.GetParam(ctx, name); ok {
+				zallocrout.SetParam(ctx, name, unescapeParam(name, value))
+			}
+		}
+		flags := FlagsFrom(ctx)
+		for name := range flags.values {
+			if c.flag(name) == nil {
+				return fmt.Errorf("%w for %q: --%s", ErrUnknownFlag, c.Pattern, name)
+			}
+		}
+		for i := range c.Flags {
+			flag := &c.Flags[i]
+			if flag.Default != "" && !flags.Has(flag.Name) {
+				flags.values[flag.Name] = []string{flag.Default}
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// 按长选项名查找命令的选项
+func (c *Command) flag(name string) *Flag {
+	for i := range c.Flags {
+		if c.Flags[i].Name == name {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+// 命令模式转换为路由路径（"user add :name" → "/user/add/:name"）
+func patternPath(pattern string) string {
+	return "/" + strings.Join(strings.Fields(pattern), "/")
+}
+
+// 命令模式中的参数名（"user get :id<int>" → ["id"]，通配符参数的键为 "*"）
+func patternParams(pattern string) []string {
+	var params []string
+	for _, seg := range strings.Fields(pattern) {
+		switch seg[0] {
+		case ':':
+			name := seg[1:]
+			if i := strings.IndexByte(name, '<'); i >= 0 {
+				name = name[:i]
+			}
+			params = append(params, name)
+		case '*':
+			params = append(params, "*")
+		}
+	}
+	return params
+}
+
+// 位置参数转换为匹配路径，跳过空参数
+// 每个参数作为一个路由片段：'/' 和 '%' 以及开头的 '.' 被转义，避免被当作路径分隔符或被路径规范化改写
+func wordsPath(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		b.WriteByte('/')
+		for i := 0; i < len(word); i++ {
+			switch ch := word[i]; {
+			case ch == '/':
+				b.WriteString("%2F")
+			case ch == '%':
+				b.WriteString("%25")
+			case ch == '.' && i == 0:
+				b.WriteString("%2E")
+			default:
+				b.WriteByte(ch)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// 还原参数值：通配符参数的多个片段以空格连接
+func unescapeParam(name, value string) string {
+	if name == "*" {
+		value = strings.ReplaceAll(value, "/", " ")
+	}
+	if strings.IndexByte(value, '%') < 0 {
+		return value
+	}
+	var b strings.Builder
+	b.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			switch value[i+1 : i+3] {
+			case "2F":
+				b.WriteByte('/')
+				i += 2
+				continue
+			case "25":
+				b.WriteByte('%')
+				i += 2
+				continue
+			case "2E":
+				b.WriteByte('.')
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// Args 从处理函数的 context 获取位置参数（命令词和参数值，不含选项）
+func Args(ctx context.Context) []string {
+	args, _ := ctx.Value(ArgsKey).([]string)
+	return args
+}
+
+// FlagsFrom 从处理函数的 context 获取选项值
+func FlagsFrom(ctx context.Context) Flags {
+	flags, _ := ctx.Value(FlagsKey).(Flags)
+	return flags
+}
+
+// Stdout 从处理函数的 context 获取标准输出
+func Stdout(ctx context.Context) io.Writer {
+	w, _ := ctx.Value(StdoutKey).(io.Writer)
+	return w
+}
+
+// Stderr 从处理函数的 context 获取标准错误
+func Stderr(ctx context.Context) io.Writer {
+	w, _ := ctx.Value(StderrKey).(io.Writer)
+	return w
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+)
+
+func newTestApp(t *testing.T) (*App, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	app := New("tool", WithStdout(&out), WithStderr(io.Discard), WithDescription("tool manages users and files"))
+	commands := []Command{
+		{
+			Pattern: "user add :name",
+			Short:   "Add a user",
+			Flags: []Flag{
+				{Name: "role", Short: "r", Usage: "user role", Default: "member"},
+				{Name: "force", Short: "f", Usage: "overwrite existing user", Bool: true},
+			},
+			Handler: func(ctx context.Context) error {
+				name, _ := zallocrout.GetParam(ctx, "name")
+				flags := FlagsFrom(ctx)
+				_, err := io.WriteString(Stdout(ctx), "add "+name+" "+flags.Get("role")+" "+
+					map[bool]string{true: "force", false: "noforce"}[flags.Bool("force")])
+				return err
+			},
+		},
+		{
+			Pattern: "user get :id<int>",
+			Short:   "Show a user",
+			Handler: func(ctx context.Context) error {
+				id, _ := zallocrout.GetParamInt(ctx, "id")
+				_, err := io.WriteString(Stdout(ctx), "get "+strings.Repeat("#", id))
+				return err
+			},
+		},
+		{
+			Pattern: "file cat *path",
+			Short:   "Print a file",
+			Flags:   []Flag{{Name: "tag", Usage: "tags"}},
+			Handler: func(ctx context.Context) error {
+				path, _ := zallocrout.GetParam(ctx, "*")
+				_, err := io.WriteString(Stdout(ctx), "cat "+path+" "+strings.Join(FlagsFrom(ctx).Values("tag"), ","))
+				return err
+			},
+		},
+	}
+	for _, cmd := range commands {
+		if err := app.AddCommand(cmd); err != nil {
+			t.Fatalf("AddCommand(%s) error = %v", cmd.Pattern, err)
+		}
+	}
+	return app, &out
+}
+
+func TestAppRun(t *testing.T) {
+	app, out := newTestApp(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"user", "add", "alice"}, "add alice member noforce"},
+		{[]string{"user", "add", "--role", "admin", "alice", "-f"}, "add alice admin force"},
+		{[]string{"user", "add", "-r=ops", "--force=false", "bob"}, "add bob ops noforce"},
+		{[]string{"user", "get", "3"}, "get ###"},
+		{[]string{"file", "cat", "docs/a.txt", "--tag", "x", "--tag=y"}, "cat docs/a.txt x,y"},
+		{[]string{"file", "cat", "--", "-rf"}, "cat -rf "},
+		// 参数中的路径原样传递
+		{[]string{"file", "cat", "/etc/passwd"}, "cat /etc/passwd "},
+		{[]string{"file", "cat", "../../etc"}, "cat ../../etc "},
+		{[]string{"file", "cat", "./a//b/"}, "cat ./a//b/ "},
+		{[]string{"file", "cat", "."}, "cat . "},
+		{[]string{"file", "cat", "a b", "%2F", "c"}, "cat a b %2F c "},
+		{[]string{"user", "add", "a/b"}, "add a/b member noforce"},
+		{[]string{"user", "add", ".."}, "add .. member noforce"},
+		{[]string{"user", "add", "50%"}, "add 50% member noforce"},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		if err := app.Run(tt.args); err != nil {
+			t.Fatalf("Run(%v) error = %v", tt.args, err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("Run(%v) output = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestAppRunErrors(t *testing.T) {
+	app, _ := newTestApp(t)
+
+	tests := []struct {
+		args []string
+		want error
+	}{
+		{[]string{"user", "delete", "alice"}, ErrUnknownCommand},
+		{[]string{"user", "get", "abc"}, ErrUnknownCommand}, // 不满足 <int> 约束
+		{[]string{"user", "add", "alice", "--color"}, ErrUnknownFlag},
+		{[]string{"user", "add", "alice", "--tag", "x"}, ErrUnknownFlag}, // 其他命令的选项
+		{[]string{"user", "add", "alice", "--role"}, ErrFlagNeedsValue},
+		{[]string{"user", "add", "alice", "--force=maybe"}, ErrInvalidFlagValue},
+	}
+	for _, tt := range tests {
+		if err := app.Run(tt.args); !errors.Is(err, tt.want) {
+			t.Errorf("Run(%v) error = %v, want %v", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestAppMiddleware(t *testing.T) {
+	app, out := newTestApp(t)
+	var trace []string
+	app.Use(func(next zallocrout.HandlerFunc) zallocrout.HandlerFunc {
+		return func(ctx context.Context) error {
+			trace = append(trace, "global "+strings.Join(Args(ctx), " "))
+			return next(ctx)
+		}
+	})
+	err := app.AddCommand(Command{
+		Pattern: "ping",
+		Handler: func(ctx context.Context) error {
+			trace = append(trace, "handler")
+			return nil
+		},
+		Middlewares: []zallocrout.Middleware{func(next zallocrout.HandlerFunc) zallocrout.HandlerFunc {
+			return func(ctx context.Context) error {
+				trace = append(trace, "command")
+				return next(ctx)
+			}
+		}},
+	})
+	if err != nil {
+		t.Fatalf("AddCommand error = %v", err)
+	}
+
+	if err := app.Run([]string{"ping"}); err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	want := "global ping,command,handler"
+	if got := strings.Join(trace, ","); got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+
+	// 全局中间件作用于已注册的命令
+	trace = nil
+	out.Reset()
+	if err := app.Run([]string{"user", "get", "1"}); err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	if len(trace) != 1 || trace[0] != "global user get 1" {
+		t.Errorf("trace = %v, want [global user get 1]", trace)
+	}
+}
+
+func TestAppAddCommandErrors(t *testing.T) {
+	app, _ := newTestApp(t)
+	handler := func(ctx context.Context) error { return nil }
+
+	tests := []Command{
+		{Pattern: "noop"},                             // 缺少处理函数
+		{Pattern: "  ", Handler: handler},             // 空模式
+		{Pattern: "user add :name", Handler: handler}, // 重复命令
+		{Pattern: "x", Handler: handler, Flags: []Flag{{Name: "help"}}},
+		{Pattern: "x", Handler: handler, Flags: []Flag{{Name: "role", Short: "x"}}},  // 与已有定义不一致
+		{Pattern: "x", Handler: handler, Flags: []Flag{{Name: "other", Short: "r"}}}, // 短选项已被占用
+		{Pattern: "x", Handler: handler, Flags: []Flag{{Name: "v", Short: "vv"}}},
+		{Pattern: "x", Handler: handler, Flags: []Flag{{Name: "b", Bool: true, Default: "yes"}}},
+	}
+	for _, cmd := range tests {
+		if err := app.AddCommand(cmd); err == nil {
+			t.Errorf("AddCommand(%+v) should fail", cmd)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 参数解析错误
+var (
+	ErrUnknownFlag      = errors.New("unknown flag")
+	ErrFlagNeedsValue   = errors.New("flag needs a value")
+	ErrInvalidFlagValue = errors.New("invalid flag value")
+)
+
+// Flag 命令选项定义
+type Flag struct {
+	Name    string // 长选项名（--name）
+	Short   string // 单字母短选项名（-n），可为空
+	Usage   string // 帮助说明
+	Default string // 未指定时的默认值
+	Bool    bool   // 布尔选项：不带值时为 true，不消费下一个参数
+}
+
+// Flags 解析后的选项值，按长选项名存储
+type Flags struct {
+	values map[string][]string
+}
+
+// Lookup 返回选项的最后一个值，未指定且无默认值时返回 false
+func (f Flags) Lookup(name string) (string, bool) {
+	values := f.values[name]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// Get 返回选项的最后一个值，未指定时返回空字符串
+func (f Flags) Get(name string) string {
+	value, _ := f.Lookup(name)
+	return value
+}
+
+// Values 返回选项的所有值（选项可重复指定，如 --tag a --tag b）
+func (f Flags) Values(name string) []string {
+	return f.values[name]
+}
+
+// Has 检查选项是否有值（命令行指定或有默认值）
+func (f Flags) Has(name string) bool {
+	return len(f.values[name]) > 0
+}
+
+// Bool 返回布尔选项的值，未指定或无法解析时为 false
+func (f Flags) Bool(name string) bool {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	return err == nil && b
+}
+
+// Int 返回整数选项的值，未指定或无法解析时返回 false
+func (f Flags) Int(name string) (int, bool) {
+	value, ok := f.Lookup(name)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// 选项定义索引（所有命令的选项并集，用于在匹配命令之前切分参数）
+type flagIndex struct {
+	long  map[string]*Flag
+	short map[string]*Flag
+}
+
+func newFlagIndex() *flagIndex {
+	return &flagIndex{long: make(map[string]*Flag), short: make(map[string]*Flag)}
+}
+
+// 登记选项定义，同名选项在不同命令中的短选项名和布尔属性必须一致
+func (idx *flagIndex) add(flag *Flag) error {
+	if flag.Name == "" || strings.HasPrefix(flag.Name, "-") || strings.ContainsAny(flag.Name, "= ") {
+		return fmt.Errorf("invalid flag name: %q", flag.Name)
+	}
+	if len(flag.Short) > 1 || flag.Short == "-" || flag.Short == "=" {
+		return fmt.Errorf("invalid short flag for --%s: %q", flag.Name, flag.Short)
+	}
+	if flag.Bool && flag.Default != "" {
+		if _, err := strconv.ParseBool(flag.Default); err != nil {
+			return fmt.Errorf("invalid default for bool flag --%s: %q", flag.Name, flag.Default)
+		}
+	}
+	if old, ok := idx.long[flag.Name]; ok {
+		if old.Short != flag.Short || old.Bool != flag.Bool {
+			return fmt.Errorf("flag --%s is defined differently by another command", flag.Name)
+		}
+		return nil
+	}
+	if flag.Short != "" {
+		if old, ok := idx.short[flag.Short]; ok && old.Name != flag.Name {
+			return fmt.Errorf("short flag -%s is already used by --%s", flag.Short, old.Name)
+		}
+		idx.short[flag.Short] = flag
+	}
+	idx.long[flag.Name] = flag
+	return nil
+}
+
+// 解析参数，返回位置参数（命令词和参数值）和选项值
+// 支持 --name=value、--name value、-n value、-n=value，布尔选项可省略值；"--" 之后的参数均为位置参数
+// 以 '-' 开头的数字（如 -5）和单独的 "-" 视为位置参数
+func (idx *flagIndex) parse(args []string) ([]string, Flags, error) {
+	words := make([]string, 0, len(args))
+	flags := Flags{values: make(map[string][]string)}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			words = append(words, args[i+1:]...)
+			break
+		}
+		if !isFlagArg(arg) {
+			words = append(words, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		var flag *Flag
+		if strings.HasPrefix(arg, "--") {
+			flag = idx.long[name]
+		} else {
+			flag = idx.short[name]
+		}
+		if flag == nil {
+			return nil, Flags{}, fmt.Errorf("%w: %s", ErrUnknownFlag, arg)
+		}
+
+		switch {
+		case hasValue:
+		case flag.Bool:
+			value = "true"
+		case i+1 < len(args):
+			i++
+			value = args[i]
+		default:
+			return nil, Flags{}, fmt.Errorf("%w: --%s", ErrFlagNeedsValue, flag.Name)
+		}
+		if flag.Bool {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return nil, Flags{}, fmt.Errorf("%w: --%s=%s", ErrInvalidFlagValue, flag.Name, value)
+			}
+		}
+		flags.values[flag.Name] = append(flags.values[flag.Name], value)
+	}
+	return words, flags, nil
+}
+
+// 检查参数是否为选项
+func isFlagArg(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	// 负数作为位置参数
+	if c := arg[1]; c >= '0' && c <= '9' {
+		return false
+	}
+	return true
+}
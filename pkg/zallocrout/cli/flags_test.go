@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlagIndexParse(t *testing.T) {
+	idx := newFlagIndex()
+	for _, flag := range []Flag{
+		{Name: "name", Short: "n"},
+		{Name: "verbose", Short: "v", Bool: true},
+		{Name: "count"},
+	} {
+		flag := flag
+		if err := idx.add(&flag); err != nil {
+			t.Fatalf("add(%s) error = %v", flag.Name, err)
+		}
+	}
+
+	words, flags, err := idx.parse([]string{"a", "-n", "x", "--count=-3", "-v", "-5", "-", "--", "--name", "b"})
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	if want := []string{"a", "-5", "-", "--name", "b"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("words = %v, want %v", words, want)
+	}
+	if got := flags.Get("name"); got != "x" {
+		t.Errorf("name = %q, want x", got)
+	}
+	if n, ok := flags.Int("count"); !ok || n != -3 {
+		t.Errorf("count = %d, %v, want -3, true", n, ok)
+	}
+	if !flags.Bool("verbose") {
+		t.Error("verbose should be true")
+	}
+	if _, ok := flags.Lookup("missing"); ok || flags.Has("missing") {
+		t.Error("missing flag should not be set")
+	}
+}
+
+func TestFlagsAccessors(t *testing.T) {
+	flags := Flags{values: map[string][]string{
+		"tag":  {"a", "b"},
+		"port": {"x"},
+		"on":   {"1"},
+	}}
+	if got := flags.Get("tag"); got != "b" {
+		t.Errorf("Get(tag) = %q, want last value b", got)
+	}
+	if got := flags.Values("tag"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Values(tag) = %v", got)
+	}
+	if _, ok := flags.Int("port"); ok {
+		t.Error("Int(port) should fail for non-numeric value")
+	}
+	if !flags.Bool("on") {
+		t.Error("Bool(on) should be true")
+	}
+	var zero Flags
+	if zero.Get("x") != "" || zero.Bool("x") || zero.Values("x") != nil {
+		t.Error("zero Flags should be empty")
+	}
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// 路由表中的一条命令
+type commandRoute struct {
+	segs []string // 路由片段
+	cmd  *Command // 通过 AddCommand 注册的命令（直接向路由器注册的路由为 nil）
+}
+
+// 从路由表收集命令，按路径排序
+func (a *App) routes() []commandRoute {
+	var routes []commandRoute
+	for _, info := range a.router.Routes() {
+		if info.Method != Method {
+			continue
+		}
+		routes = append(routes, commandRoute{
+			segs: strings.Split(strings.Trim(info.Path, "/"), "/"),
+			cmd:  a.commands[info.Path],
+		})
+	}
+	return routes
+}
+
+// 检查已输入的位置参数是否为命令的前缀（参数片段接受任意值，通配符接受剩余所有参数）
+func (r commandRoute) hasPrefix(words []string) bool {
+	for i, word := range words {
+		if i >= len(r.segs) {
+			return false
+		}
+		seg := r.segs[i]
+		switch seg[0] {
+		case '*':
+			return true
+		case ':':
+		default:
+			if seg != word {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 命令的用法文本（"user add <name>"、"file cat <path...>"）
+func (r commandRoute) usage() string {
+	parts := make([]string, len(r.segs))
+	for i, seg := range r.segs {
+		switch seg[0] {
+		case ':':
+			name := seg[1:]
+			if j := strings.IndexByte(name, '<'); j >= 0 {
+				name = name[:j]
+			}
+			parts[i] = "<" + name + ">"
+		case '*':
+			name := seg[1:]
+			if name == "" {
+				name = "args"
+			}
+			parts[i] = "<" + name + "...>"
+		default:
+			parts[i] = seg
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Help 返回帮助文本
+// 不带参数时列出所有命令；参数为命令前缀时列出匹配的命令，只匹配一个命令时输出该命令的用法和选项
+func (a *App) Help(words ...string) (string, error) {
+	var matched []commandRoute
+	for _, r := range a.routes() {
+		if r.hasPrefix(words) {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCommand, strings.Join(words, " "))
+	}
+
+	var b strings.Builder
+	if len(words) > 0 && len(matched) == 1 {
+		a.writeCommandHelp(&b, matched[0])
+	} else {
+		a.writeCommandList(&b, matched)
+	}
+	return b.String(), nil
+}
+
+// 输出帮助到标准输出
+func (a *App) writeHelp(words []string) error {
+	help, err := a.Help(words...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(a.stdout, help)
+	return err
+}
+
+// 输出命令列表
+func (a *App) writeCommandList(b *strings.Builder, routes []commandRoute) {
+	if a.description != "" {
+		b.WriteString(a.description)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(b, "Usage: %s <command> [flags]\n\nCommands:\n", a.name)
+	tw := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	for _, r := range routes {
+		short := ""
+		if r.cmd != nil {
+			short = r.cmd.Short
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", r.usage(), short)
+	}
+	tw.Flush()
+	fmt.Fprintf(b, "\nRun '%s help <command>' for more information on a command.\n", a.name)
+}
+
+// 输出单个命令的用法和选项
+func (a *App) writeCommandHelp(b *strings.Builder, r commandRoute) {
+	fmt.Fprintf(b, "Usage: %s %s [flags]\n", a.name, r.usage())
+
+	var flags []Flag
+	if r.cmd != nil {
+		desc := r.cmd.Long
+		if desc == "" {
+			desc = r.cmd.Short
+		}
+		if desc != "" {
+			b.WriteString("\n")
+			b.WriteString(desc)
+			b.WriteString("\n")
+		}
+		flags = append(flags, r.cmd.Flags...)
+	}
+	flags = append(flags, helpFlag)
+
+	b.WriteString("\nFlags:\n")
+	tw := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	for _, flag := range flags {
+		name := "    --" + flag.Name
+		if flag.Short != "" {
+			name = "-" + flag.Short + ", --" + flag.Name
+		}
+		if !flag.Bool {
+			name += " string"
+		}
+		usage := flag.Usage
+		if flag.Default != "" {
+			usage += fmt.Sprintf(" (default %q)", flag.Default)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, usage)
+	}
+	tw.Flush()
+}
+
+// Complete 返回 shell 补全候选
+// args 为已输入的参数，最后一个元素是正在输入的参数（可为空字符串）；
+// 以 '-' 开头时补全匹配命令的选项，否则补全下一个命令词（参数位置不提供候选）
+func (a *App) Complete(args []string) []string {
+	partial := ""
+	if len(args) > 0 {
+		partial = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	words := a.completionWords(args)
+
+	seen := make(map[string]bool)
+	var candidates []string
+	add := func(candidate string) {
+		if strings.HasPrefix(candidate, partial) && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	for _, r := range a.routes() {
+		if !r.hasPrefix(words) {
+			continue
+		}
+		if strings.HasPrefix(partial, "-") {
+			if r.cmd != nil {
+				for _, flag := range r.cmd.Flags {
+					add("--" + flag.Name)
+				}
+			}
+			continue
+		}
+		if len(words) < len(r.segs) {
+			if seg := r.segs[len(words)]; seg[0] != ':' && seg[0] != '*' {
+				add(seg)
+			}
+		}
+	}
+	if strings.HasPrefix(partial, "-") {
+		add("--" + helpFlag.Name)
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// 去掉已输入参数中的选项及其值，返回位置参数
+func (a *App) completionWords(args []string) []string {
+	words := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(words, args[i+1:]...)
+		}
+		if !isFlagArg(arg) {
+			words = append(words, arg)
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		flag := a.flags.long[name]
+		if !strings.HasPrefix(arg, "--") {
+			flag = a.flags.short[name]
+		}
+		if flag != nil && !flag.Bool && !hasValue {
+			i++ // 跳过选项值
+		}
+	}
+	return words
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppHelp(t *testing.T) {
+	app, out := newTestApp(t)
+
+	list, err := app.Help()
+	if err != nil {
+		t.Fatalf("Help() error = %v", err)
+	}
+	for _, want := range []string{
+		"tool manages users and files",
+		"Usage: tool <command> [flags]",
+		"file cat <path...>  Print a file",
+		"user add <name>     Add a user",
+		"user get <id>       Show a user",
+	} {
+		if !strings.Contains(list, want) {
+			t.Errorf("Help() missing %q:\n%s", want, list)
+		}
+	}
+
+	// 命令前缀只列出匹配的命令
+	users, _ := app.Help("user")
+	if strings.Contains(users, "file cat") || !strings.Contains(users, "user get") {
+		t.Errorf("Help(user) =\n%s", users)
+	}
+
+	detail, err := app.Help("user", "add")
+	if err != nil {
+		t.Fatalf("Help(user add) error = %v", err)
+	}
+	for _, want := range []string{
+		"Usage: tool user add <name> [flags]",
+		"Add a user",
+		"-r, --role string",
+		`user role (default "member")`,
+		"-f, --force",
+		"-h, --help",
+	} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("Help(user add) missing %q:\n%s", want, detail)
+		}
+	}
+
+	if _, err := app.Help("nope"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("Help(nope) error = %v, want ErrUnknownCommand", err)
+	}
+
+	// help 命令、--help 和无参数都输出帮助
+	runs := []struct {
+		args []string
+		want string
+	}{
+		{nil, list},
+		{[]string{"help"}, list},
+		{[]string{"help", "user", "add"}, detail},
+		{[]string{"user", "add", "--help"}, detail},
+		{[]string{"user", "add", "alice", "-h"}, detail},
+	}
+	for _, tt := range runs {
+		out.Reset()
+		if err := app.Run(tt.args); err != nil {
+			t.Fatalf("Run(%v) error = %v", tt.args, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("Run(%v) output =\n%s\nwant\n%s", tt.args, out.String(), tt.want)
+		}
+	}
+}
+
+func TestAppHelpCommandOverride(t *testing.T) {
+	app, out := newTestApp(t)
+	err := app.AddCommand(Command{Pattern: "help", Handler: func(ctx context.Context) error {
+		_, err := Stdout(ctx).Write([]byte("custom help"))
+		return err
+	}})
+	if err != nil {
+		t.Fatalf("AddCommand error = %v", err)
+	}
+	if err := app.Run([]string{"help"}); err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	if out.String() != "custom help" {
+		t.Errorf("output = %q, want custom help", out.String())
+	}
+}
+
+func TestAppComplete(t *testing.T) {
+	app, _ := newTestApp(t)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{""}, []string{"file", "user"}},
+		{[]string{"u"}, []string{"user"}},
+		{[]string{"user", ""}, []string{"add", "get"}},
+		{[]string{"user", "a"}, []string{"add"}},
+		{[]string{"user", "add", ""}, nil}, // 参数位置
+		{[]string{"user", "add", "alice", "--"}, []string{"--force", "--help", "--role"}},
+		{[]string{"user", "add", "--r"}, []string{"--role"}},
+		{[]string{"--role", "admin", "user", ""}, []string{"add", "get"}},
+		{[]string{"nope", ""}, nil},
+	}
+	for _, tt := range tests {
+		if got := app.Complete(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package zallocrout
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 参数约束类型
+type constraintKind uint8
+
+const (
+	constraintInt    constraintKind = iota // <int>：十进制整数（可带符号）
+	constraintUUID                         // <uuid>：8-4-4-4-12 格式的十六进制 UUID
+	constraintRegexp                       // <re:...>：整段匹配正则表达式
+)
+
+// 参数约束（注册时解析，匹配时只读）
+type paramConstraint struct {
+	kind constraintKind
+	re   *regexp.Regexp
+}
+
+// 无约束参数的匹配优先级（最低）
+const unconstrainedPriority = 2
+
+// 约束匹配优先级，数值越小越先尝试：内置类型 > 正则 > 无约束
+//
+//go:inline
+func (c *paramConstraint) priority() int {
+	if c == nil {
+		return unconstrainedPriority
+	}
+	if c.kind == constraintRegexp {
+		return 1
+	}
+	return 0
+}
+
+// 检查参数值是否满足约束，<int> 约束同时返回解析后的整数
+//
+//go:inline
+func (c *paramConstraint) match(value string) (int, bool) {
+	if c == nil {
+		return 0, true
+	}
+	switch c.kind {
+	case constraintInt:
+		return parseInt(value)
+	case constraintUUID:
+		return 0, isUUID(value)
+	default:
+		return 0, c.re.MatchString(value)
+	}
+}
+
+// 解析参数片段（:name 或 :name<constraint>），返回参数名和约束（无约束时为 nil）
+func parseParamSeg(seg string) (string, *paramConstraint, error) {
+	name := paramSegName(seg)
+	if len(name) == 0 {
+		return "", nil, errors.New("param segment cannot be empty")
+	}
+	// 参数名只能包含字母、数字、下划线
+	for _, c := range name {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9') || c == '_') {
+			return "", nil, errors.New("param name can only contain letters, numbers, and underscores")
+		}
+	}
+
+	spec := seg[1+len(name):]
+	if len(spec) == 0 {
+		return name, nil, nil
+	}
+	if spec[len(spec)-1] != '>' {
+		return "", nil, errors.New("param constraint must end with '>'")
+	}
+	constraint, err := newParamConstraint(spec[1 : len(spec)-1])
+	if err != nil {
+		return "", nil, err
+	}
+	return name, constraint, nil
+}
+
+// 返回参数片段中的参数名（去掉 ':' 和约束）
+func paramSegName(seg string) string {
+	name := seg[1:]
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// 根据约束描述创建约束
+func newParamConstraint(spec string) (*paramConstraint, error) {
+	switch {
+	case spec == "int":
+		return &paramConstraint{kind: constraintInt}, nil
+	case spec == "uuid":
+		return &paramConstraint{kind: constraintUUID}, nil
+	case strings.HasPrefix(spec, "re:"):
+		if len(spec) == len("re:") {
+			return nil, errors.New("param regexp cannot be empty")
+		}
+		// 锚定整段匹配
+		re, err := regexp.Compile("^(?:" + spec[len("re:"):] + ")$")
+		if err != nil {
+			return nil, err
+		}
+		return &paramConstraint{kind: constraintRegexp, re: re}, nil
+	}
+	return nil, errors.New("unknown param constraint: " + spec)
+}
+
+// 解析十进制整数（零分配，strconv.Atoi 失败时会分配错误对象）
+//
+//go:inline
+func parseInt(s string) (int, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+		if len(s) == 0 {
+			return 0, false
+		}
+	}
+
+	// 19 位以内的十进制数不会溢出 uint64
+	if len(s) > 19 {
+		return 0, false
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + uint64(c-'0')
+	}
+
+	const limit = uint64(1) << (strconv.IntSize - 1)
+	if neg {
+		if n > limit {
+			return 0, false
+		}
+		return int(-n), true
+	}
+	if n >= limit {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// 检查是否为 8-4-4-4-12 格式的 UUID（不区分大小写）
+//
+//go:inline
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+				return false
+			}
+		}
+	}
+	return true
+}
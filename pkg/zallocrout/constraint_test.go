@@ -0,0 +1,122 @@
+package zallocrout
+
+import (
+	"strconv"
+	"testing"
+)
+
+// 测试参数片段解析
+func TestParseParamSeg(t *testing.T) {
+	tests := []struct {
+		seg      string
+		name     string
+		kind     constraintKind
+		hasCheck bool
+		wantErr  bool
+	}{
+		{":id", "id", 0, false, false},
+		{":id<int>", "id", constraintInt, true, false},
+		{":uuid<uuid>", "uuid", constraintUUID, true, false},
+		{":slug<re:[a-z-]+>", "slug", constraintRegexp, true, false},
+		{":re<re:a<b>>", "re", constraintRegexp, true, false},
+		{":id<>", "", 0, false, true},
+		{":id<bool>", "", 0, false, true},
+		{":id<int", "", 0, false, true},
+		{":<int>", "", 0, false, true},
+		{":a-b<int>", "", 0, false, true},
+	}
+	for _, tt := range tests {
+		name, c, err := parseParamSeg(tt.seg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseParamSeg(%q) error = %v, wantErr %v", tt.seg, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if name != tt.name || (c != nil) != tt.hasCheck || (c != nil && c.kind != tt.kind) {
+			t.Errorf("parseParamSeg(%q) = %q, %+v", tt.seg, name, c)
+		}
+	}
+}
+
+// 测试约束匹配
+func TestParamConstraint_Match(t *testing.T) {
+	mustConstraint := func(spec string) *paramConstraint {
+		c, err := newParamConstraint(spec)
+		if err != nil {
+			t.Fatalf("newParamConstraint(%q) error = %v", spec, err)
+		}
+		return c
+	}
+	intC, uuidC, reC := mustConstraint("int"), mustConstraint("uuid"), mustConstraint("re:[a-z]+(-[a-z]+)*")
+
+	tests := []struct {
+		c     *paramConstraint
+		value string
+		want  bool
+	}{
+		{nil, "anything", true},
+		{intC, "42", true},
+		{intC, "-7", true},
+		{intC, "4a", false},
+		{intC, "", false},
+		{uuidC, "123e4567-e89b-12d3-a456-426614174000", true},
+		{uuidC, "123E4567-E89B-12D3-A456-426614174000", true},
+		{uuidC, "123e4567e89b12d3a456426614174000", false},
+		{uuidC, "123e4567-e89b-12d3-a456-42661417400g", false},
+		{reC, "hello-world", true},
+		{reC, "hello-", false},
+		{reC, "xhello-world1", false}, // 整段匹配
+	}
+	for _, tt := range tests {
+		if _, got := tt.c.match(tt.value); got != tt.want {
+			t.Errorf("match(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if n, ok := intC.match("-123"); !ok || n != -123 {
+		t.Errorf("int match = %d, %v", n, ok)
+	}
+	var none *paramConstraint
+	if !(intC.priority() < reC.priority() && reC.priority() < none.priority()) {
+		t.Error("priority should be builtin < regexp < unconstrained")
+	}
+}
+
+// 测试整数解析边界
+func TestParseInt(t *testing.T) {
+	maxInt := strconv.Itoa(int(^uint(0) >> 1))
+	minInt := strconv.Itoa(-int(^uint(0)>>1) - 1)
+	tests := []struct {
+		s    string
+		want int
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"+15", 15, true},
+		{"-15", -15, true},
+		{"007", 7, true},
+		{maxInt, int(^uint(0) >> 1), true},
+		{minInt, -int(^uint(0)>>1) - 1, true},
+		{maxInt + "0", 0, false},
+		{"99999999999999999999", 0, false},
+		{"-", 0, false},
+		{"1e3", 0, false},
+		{" 1", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseInt(tt.s)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseInt(%q) = %d, %v; want %d, %v", tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// 越界值：strconv 也拒绝
+	big := "9223372036854775808"
+	if _, err := strconv.Atoi(big); err != nil {
+		if _, ok := parseInt(big); ok {
+			t.Errorf("parseInt(%q) should overflow", big)
+		}
+	}
+}
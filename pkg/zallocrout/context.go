@@ -123,6 +123,37 @@ func (c *routeContext) GetParam(key string) (string, bool) {
 	return "", false
 }
 
+// SetParam 替换已存在的路由参数值（零分配），参数不存在时返回 false
+// 供适配器还原匹配前转义过的参数值，替换后 GetParamInt 按新值解析
+func (c *routeContext) SetParam(key, value string) bool {
+	for i := 0; i < c.paramCount; i++ {
+		if c.paramPairs[i].key == key {
+			c.paramPairs[i] = paramPair{key: key, value: value}
+			return true
+		}
+	}
+	return false
+}
+
+// appendParams 在路径参数之后追加参数（如主机模式的捕获值），超出 MaxParams 的部分丢弃
+func (c *routeContext) appendParams(pairs []paramPair) {
+	c.paramCount += copy(c.paramPairs[c.paramCount:], pairs)
+}
+
+// GetParamInt 获取整数路由参数（零分配）
+// <int> 约束的参数直接返回匹配时解析的值，其他参数按十进制解析
+func (c *routeContext) GetParamInt(key string) (int, bool) {
+	for i := 0; i < c.paramCount; i++ {
+		if c.paramPairs[i].key == key {
+			if c.paramPairs[i].isNum {
+				return c.paramPairs[i].num, true
+			}
+			return parseInt(c.paramPairs[i].value)
+		}
+	}
+	return 0, false
+}
+
 // GetParam 辅助函数：从 context 获取路由参数
 func GetParam(ctx context.Context, key string) (string, bool) {
 	if rctx, ok := ctx.(*routeContext); ok {
@@ -131,6 +162,22 @@ func GetParam(ctx context.Context, key string) (string, bool) {
 	return "", false
 }
 
+// GetParamInt 辅助函数：从 context 获取整数路由参数，<int> 约束的参数无需再次解析
+func GetParamInt(ctx context.Context, key string) (int, bool) {
+	if rctx, ok := ctx.(*routeContext); ok {
+		return rctx.GetParamInt(key)
+	}
+	return 0, false
+}
+
+// SetParam 辅助函数：替换已存在的路由参数值
+func SetParam(ctx context.Context, key, value string) bool {
+	if rctx, ok := ctx.(*routeContext); ok {
+		return rctx.SetParam(key, value)
+	}
+	return false
+}
+
 // SetValue 辅助函数：设置自定义值
 func SetValue(ctx context.Context, key string, value interface{}) bool {
 	if rctx, ok := ctx.(*routeContext); ok {
@@ -248,6 +248,33 @@ func TestGetParam(t *testing.T) {
 	}
 }
 
+// TestSetParam 测试全局 SetParam 辅助函数
+func TestSetParam(t *testing.T) {
+	parent := context.Background()
+	var paramPairs [MaxParams]paramPair
+	paramPairs[0] = paramPair{key: "id", value: "7", num: 7, isNum: true}
+	ctx := acquireContext(parent, &paramPairs, 1)
+	defer releaseContext(ctx)
+
+	if !SetParam(ctx, "id", "42") {
+		t.Fatal("SetParam(id) should succeed")
+	}
+	if val, _ := GetParam(ctx, "id"); val != "42" {
+		t.Errorf("GetParam(id) = %q, want 42", val)
+	}
+	if n, ok := GetParamInt(ctx, "id"); !ok || n != 42 {
+		t.Errorf("GetParamInt(id) = %d, %v; want 42, true", n, ok)
+	}
+
+	// 参数不存在时不追加
+	if SetParam(ctx, "name", "x") {
+		t.Error("SetParam(name) should fail for missing param")
+	}
+	if SetParam(context.Background(), "id", "1") {
+		t.Error("SetParam on non-routeContext should fail")
+	}
+}
+
 // TestSetValue 测试全局 SetValue 辅助函数
 func TestSetValue(t *testing.T) {
 	parent := context.Background()
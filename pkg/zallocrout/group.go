@@ -0,0 +1,84 @@
+package zallocrout
+
+import "strings"
+
+// 路由分组，共享路径前缀和中间件
+// 分组只在注册路由时展开前缀和中间件，匹配时与直接调用 AddRoute 注册的路由没有区别
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []Middleware
+}
+
+// 创建路由分组
+// prefix: 路径前缀（如 /api/v1，可包含参数），合法性在 AddRoute 时校验
+// middlewares: 分组中间件，在全局中间件之后、路由中间件之前执行
+func (r *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		router:      r,
+		prefix:      trimPrefix(prefix),
+		middlewares: joinMiddlewares(nil, middlewares),
+	}
+}
+
+// 创建嵌套分组，前缀和中间件依次叠加
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		router:      g.router,
+		prefix:      g.prefix + trimPrefix(prefix),
+		middlewares: joinMiddlewares(g.middlewares, middlewares),
+	}
+}
+
+// 在分组下添加路由，path 为相对分组前缀的路径（"" 或 "/" 表示前缀本身）
+func (g *Group) AddRoute(method, path string, handler HandlerFunc, middlewares ...Middleware) error {
+	return g.router.AddRoute(method, g.fullPath(path), handler, joinMiddlewares(g.middlewares, middlewares)...)
+}
+
+// 在分组下添加命名路由，名称在整个路由器内唯一
+func (g *Group) AddNamedRoute(name, method, path string, handler HandlerFunc, middlewares ...Middleware) error {
+	return g.router.AddNamedRoute(name, method, g.fullPath(path), handler, joinMiddlewares(g.middlewares, middlewares)...)
+}
+
+// 在分组下移除路由
+func (g *Group) RemoveRoute(method, path string) error {
+	return g.router.RemoveRoute(method, g.fullPath(path))
+}
+
+// 返回分组的完整路径前缀
+func (g *Group) Prefix() string {
+	if g.prefix == "" {
+		return "/"
+	}
+	return g.prefix
+}
+
+// 拼接分组前缀和相对路径
+func (g *Group) fullPath(path string) string {
+	if path == "" || path == "/" {
+		return g.Prefix()
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return g.prefix + path
+}
+
+// 规范化分组前缀：补齐开头的 '/'，去掉结尾的 '/'，根前缀返回空串
+func trimPrefix(prefix string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix != "" && prefix[0] != '/' {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// 按顺序拼接两组中间件，总是返回新切片（都为空时返回 nil）
+func joinMiddlewares(a, b []Middleware) []Middleware {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	joined := make([]Middleware, 0, len(a)+len(b))
+	joined = append(joined, a...)
+	return append(joined, b...)
+}
@@ -0,0 +1,145 @@
+package zallocrout
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// 记录执行顺序的中间件
+func recordMiddleware(order *[]string, name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context) error {
+			*order = append(*order, name)
+			return next(ctx)
+		}
+	}
+}
+
+// 匹配并执行路由，返回执行顺序
+func runRoute(t *testing.T, router *Router, method, path string, order *[]string) []string {
+	t.Helper()
+	*order = (*order)[:0]
+	ctx, h, mws, ok := router.Match(method, path, context.Background())
+	if !ok {
+		t.Fatalf("Match(%s %s) failed", method, path)
+	}
+	if err := ExecuteHandler(ctx, h, mws); err != nil {
+		t.Fatalf("ExecuteHandler error = %v", err)
+	}
+	return append([]string(nil), *order...)
+}
+
+// 测试分组前缀拼接
+func TestGroup_Prefix(t *testing.T) {
+	router := NewRouter()
+	tests := []struct {
+		group *Group
+		path  string
+		want  string
+	}{
+		{router.Group("/api/v1"), "/users", "/api/v1/users"},
+		{router.Group("/api/v1/"), "users", "/api/v1/users"},
+		{router.Group("api"), "", "/api"},
+		{router.Group("/api").Group("/v2/"), "/", "/api/v2"},
+		{router.Group("/"), "/users/:id", "/users/:id"},
+		{router.Group(""), "", "/"},
+	}
+	for _, tt := range tests {
+		if got := tt.group.fullPath(tt.path); got != tt.want {
+			t.Errorf("fullPath(%q) with prefix %q = %q, want %q", tt.path, tt.group.Prefix(), got, tt.want)
+		}
+	}
+}
+
+// 测试嵌套分组的中间件顺序
+func TestGroup_NestedMiddlewares(t *testing.T) {
+	var order []string
+	router := NewRouter()
+	handler := func(ctx context.Context) error {
+		order = append(order, "handler")
+		return nil
+	}
+
+	api := router.Group("/api", recordMiddleware(&order, "api"))
+	v1 := api.Group("/v1", recordMiddleware(&order, "v1"))
+	if err := v1.AddRoute("GET", "/users/:id", handler, recordMiddleware(&order, "route")); err != nil {
+		t.Fatalf("AddRoute error = %v", err)
+	}
+	if err := api.AddRoute("GET", "/health", handler); err != nil {
+		t.Fatalf("AddRoute error = %v", err)
+	}
+	// 嵌套分组不影响父分组的中间件
+	if err := api.AddRoute("GET", "/ping", handler); err != nil {
+		t.Fatalf("AddRoute error = %v", err)
+	}
+
+	if got, want := runRoute(t, router, "GET", "/api/v1/users/7", &order), []string{"api", "v1", "route", "handler"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if got, want := runRoute(t, router, "GET", "/api/ping", &order), []string{"api", "handler"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	if err := v1.RemoveRoute("GET", "/users/:id"); err != nil {
+		t.Errorf("RemoveRoute error = %v", err)
+	}
+	if _, _, _, ok := router.Match("GET", "/api/v1/users/7", context.Background()); ok {
+		t.Error("route should be removed")
+	}
+	if err := v1.AddRoute("GET", "/bad/:", handler); err == nil {
+		t.Error("invalid path should fail")
+	}
+}
+
+// 测试全局中间件作用于之前和之后注册的路由
+func TestRouter_Use(t *testing.T) {
+	var order []string
+	router := NewRouter()
+	handler := func(ctx context.Context) error {
+		order = append(order, "handler")
+		return nil
+	}
+
+	_ = router.AddRoute("GET", "/before", handler, recordMiddleware(&order, "route"))
+	// 预热热点缓存，Use 之后缓存的旧中间件链不应再命中
+	for i := 0; i <= hotCacheThreshold+1; i++ {
+		runRoute(t, router, "GET", "/before", &order)
+	}
+
+	router.Use(recordMiddleware(&order, "g1"))
+	router.Use(recordMiddleware(&order, "g2"))
+	router.Group("/api", recordMiddleware(&order, "api")).AddRoute("GET", "/after", handler)
+
+	if got, want := runRoute(t, router, "GET", "/before", &order), []string{"g1", "g2", "route", "handler"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("earlier route order = %v, want %v", got, want)
+	}
+	if got, want := runRoute(t, router, "GET", "/api/after", &order), []string{"g1", "g2", "api", "handler"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("later route order = %v, want %v", got, want)
+	}
+
+	// 换入的路由表使用当前路由器的全局中间件
+	next := NewRouter()
+	next.Use(recordMiddleware(&order, "ignored"))
+	_ = next.AddRoute("GET", "/swapped", handler)
+	router.Swap(next)
+	if got, want := runRoute(t, router, "GET", "/swapped", &order), []string{"g1", "g2", "handler"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("swapped route order = %v, want %v", got, want)
+	}
+}
+
+// 测试全局中间件和分组不增加匹配分配
+func TestRouter_UseZeroAlloc(t *testing.T) {
+	router := NewRouter()
+	passthrough := func(next HandlerFunc) HandlerFunc { return next }
+	router.Use(passthrough)
+	_ = router.Group("/api", passthrough).AddRoute("GET", "/users/:id", func(ctx context.Context) error { return nil })
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ctx, h, mws, _ := router.Match("GET", "/api/users/1", context.Background())
+		_ = ExecuteHandler(ctx, h, mws)
+	})
+	if allocs != 0 {
+		t.Errorf("Match+ExecuteHandler allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,205 @@
+// Package httpadapter 将 zallocrout.Router（或按主机名分发的 zallocrout.Mux）适配为 net/http 的 http.Handler
+package httpadapter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+)
+
+// 处理函数通过以下键从 context 获取 HTTP 请求和响应（zallocrout.SetValue 仅支持字符串键）
+const (
+	ResponseWriterKey = "http.ResponseWriter"
+	RequestKey        = "http.Request"
+)
+
+// ErrorHandler 处理路由处理函数返回的错误
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Option 适配器配置选项
+type Option func(*Adapter)
+
+// WithNotFound 设置路径不存在时的处理器，默认 http.NotFound
+func WithNotFound(h http.Handler) Option {
+	return func(a *Adapter) {
+		a.notFound = h
+	}
+}
+
+// WithMethodNotAllowed 设置路径存在但方法不匹配时的处理器，调用前已设置 Allow 头，默认返回 405
+func WithMethodNotAllowed(h http.Handler) Option {
+	return func(a *Adapter) {
+		a.methodNotAllowed = h
+	}
+}
+
+// WithErrorHandler 设置处理函数返回错误时的处理方式，默认返回 500
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(a *Adapter) {
+		a.errorHandler = h
+	}
+}
+
+// Adapter zallocrout 路由的 http.Handler 实现
+// 匹配成功时保持零分配：请求和响应写入池化 context，处理函数返回后 context 归还池中
+type Adapter struct {
+	router           *zallocrout.Router
+	mux              *zallocrout.Mux // 非 nil 时按请求的 Host 选择路由器
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	errorHandler     ErrorHandler
+}
+
+// New 创建 HTTP 适配器
+func New(router *zallocrout.Router, opts ...Option) *Adapter {
+	a := &Adapter{
+		router:           router,
+		notFound:         http.NotFoundHandler(),
+		methodNotAllowed: http.HandlerFunc(methodNotAllowed),
+		errorHandler:     internalError,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// NewHost 创建按请求 Host 分发的 HTTP 适配器，mux 应由 zallocrout.NewHostMux 创建
+// 主机模式的捕获值（如 :tenant）可通过 zallocrout.GetParam 获取
+func NewHost(mux *zallocrout.Mux, opts ...Option) *Adapter {
+	a := New(nil, opts...)
+	a.mux = mux
+	return a
+}
+
+// ServeHTTP 实现 http.Handler 接口
+// 未注册 HEAD 路由时回退到 GET 路由；路由器启用路径修正策略时重定向到规范路径；
+// 未注册 OPTIONS 路由时自动返回 Allow 头；路径在其他方法下存在时返回 405 并设置 Allow 头
+func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	ctx, handler, middlewares, ok := a.match(r, r.Method, path)
+	if !ok && r.Method == http.MethodHead {
+		// net/http 会丢弃 HEAD 响应的响应体
+		ctx, handler, middlewares, ok = a.match(r, http.MethodGet, path)
+	}
+	if !ok {
+		if a.redirect(w, r) {
+			return
+		}
+		a.serveUnmatched(w, r)
+		return
+	}
+
+	// 设置 HTTP 相关值到 context（零分配）
+	zallocrout.SetValue(ctx, ResponseWriterKey, w)
+	zallocrout.SetValue(ctx, RequestKey, r)
+
+	// 执行处理器（自动释放 context）
+	if err := zallocrout.ExecuteHandler(ctx, handler, middlewares); err != nil {
+		a.errorHandler(w, r, err)
+	}
+}
+
+// match 匹配路由，主机模式下先按 Host 选择路由器
+func (a *Adapter) match(r *http.Request, method, path string) (context.Context, zallocrout.HandlerFunc, []zallocrout.Middleware, bool) {
+	if a.mux != nil {
+		return a.mux.Match(r.Host, method, path, r.Context())
+	}
+	return a.router.Match(method, path, r.Context())
+}
+
+// allowedMethods 返回路径允许的方法
+func (a *Adapter) allowedMethods(r *http.Request) []string {
+	if a.mux != nil {
+		return a.mux.AllowedMethods(r.Host, r.URL.Path)
+	}
+	return a.router.AllowedMethods(r.URL.Path)
+}
+
+// redirectPath 按路由器的路径修正策略返回规范路径
+func (a *Adapter) redirectPath(r *http.Request, method string) (string, bool) {
+	if a.mux != nil {
+		return a.mux.RedirectPath(r.Host, method, r.URL.Path)
+	}
+	return a.router.RedirectPath(method, r.URL.Path)
+}
+
+// redirect 重定向到规范路径（保留查询参数）：GET/HEAD 返回 301，其他方法返回 308 以保留方法和请求体
+func (a *Adapter) redirect(w http.ResponseWriter, r *http.Request) bool {
+	target, ok := a.redirectPath(r, r.Method)
+	if !ok && r.Method == http.MethodHead {
+		target, ok = a.redirectPath(r, http.MethodGet)
+	}
+	if !ok {
+		return false
+	}
+
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	u := url.URL{Path: target, RawQuery: r.URL.RawQuery}
+	http.Redirect(w, r, u.String(), code)
+	return true
+}
+
+// serveUnmatched 处理未匹配的请求：自动 OPTIONS、405 或 404
+func (a *Adapter) serveUnmatched(w http.ResponseWriter, r *http.Request) {
+	methods := a.allowedMethods(r)
+	if len(methods) == 0 {
+		a.notFound.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", allowHeader(methods))
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	a.methodNotAllowed.ServeHTTP(w, r)
+}
+
+// allowHeader 生成 Allow 头，注册了 GET 时包含 HEAD，并始终包含 OPTIONS
+func allowHeader(methods []string) string {
+	allowed := append([]string(nil), methods...)
+	has := func(method string) bool {
+		for _, m := range allowed {
+			if m == method {
+				return true
+			}
+		}
+		return false
+	}
+	if has(http.MethodGet) && !has(http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !has(http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.Strings(allowed)
+	return strings.Join(allowed, ", ")
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+func internalError(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// ResponseWriter 从处理函数的 context 获取 http.ResponseWriter
+func ResponseWriter(ctx context.Context) http.ResponseWriter {
+	w, _ := ctx.Value(ResponseWriterKey).(http.ResponseWriter)
+	return w
+}
+
+// Request 从处理函数的 context 获取 *http.Request
+func Request(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(RequestKey).(*http.Request)
+	return r
+}
@@ -0,0 +1,275 @@
+package httpadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/junbin-yang/go-kitbox/pkg/zallocrout"
+)
+
+func newTestAdapter(t *testing.T, opts ...Option) *Adapter {
+	t.Helper()
+	router := zallocrout.NewRouter()
+	routes := []struct {
+		method, path string
+		handler      zallocrout.HandlerFunc
+	}{
+		{http.MethodGet, "/users/:id", func(ctx context.Context) error {
+			id, _ := zallocrout.GetParam(ctx, "id")
+			_, err := io.WriteString(ResponseWriter(ctx), "user "+id+" "+Request(ctx).Method)
+			return err
+		}},
+		{http.MethodPut, "/users/:id", func(ctx context.Context) error {
+			ResponseWriter(ctx).WriteHeader(http.StatusAccepted)
+			return nil
+		}},
+		{http.MethodPost, "/fail", func(ctx context.Context) error {
+			return errors.New("boom")
+		}},
+	}
+	for _, rt := range routes {
+		if err := router.AddRoute(rt.method, rt.path, rt.handler); err != nil {
+			t.Fatalf("AddRoute error = %v", err)
+		}
+	}
+	return New(router, opts...)
+}
+
+func serve(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestAdapterServeHTTP(t *testing.T) {
+	a := newTestAdapter(t)
+
+	tests := []struct {
+		method, path string
+		code         int
+		body, allow  string
+	}{
+		{http.MethodGet, "/users/42", http.StatusOK, "user 42 GET", ""},
+		{http.MethodPut, "/users/42", http.StatusAccepted, "", ""},
+		{http.MethodHead, "/users/42", http.StatusOK, "user 42 HEAD", ""}, // 回退到 GET
+		{http.MethodDelete, "/users/42", http.StatusMethodNotAllowed, "", "GET, HEAD, OPTIONS, PUT"},
+		{http.MethodOptions, "/users/42", http.StatusNoContent, "", "GET, HEAD, OPTIONS, PUT"},
+		{http.MethodGet, "/fail", http.StatusMethodNotAllowed, "", "OPTIONS, POST"},
+		{http.MethodPost, "/fail", http.StatusInternalServerError, "boom\n", ""},
+		{http.MethodGet, "/missing", http.StatusNotFound, "", ""},
+		{http.MethodOptions, "/missing", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		w := serve(a, tt.method, tt.path)
+		if w.Code != tt.code {
+			t.Errorf("%s %s code = %d, want %d", tt.method, tt.path, w.Code, tt.code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.path, w.Body.String(), tt.body)
+		}
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+}
+
+func TestAdapterCustomHandlers(t *testing.T) {
+	var gotErr error
+	a := newTestAdapter(t,
+		WithNotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+		WithMethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "allow: "+w.Header().Get("Allow"))
+		})),
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			gotErr = err
+			w.WriteHeader(http.StatusBadGateway)
+		}),
+	)
+
+	if w := serve(a, http.MethodGet, "/missing"); w.Code != http.StatusTeapot {
+		t.Errorf("not found code = %d", w.Code)
+	}
+	if w := serve(a, http.MethodPatch, "/users/1"); w.Body.String() != "allow: GET, HEAD, OPTIONS, PUT" {
+		t.Errorf("method not allowed body = %q", w.Body.String())
+	}
+	if w := serve(a, http.MethodPost, "/fail"); w.Code != http.StatusBadGateway || gotErr == nil || gotErr.Error() != "boom" {
+		t.Errorf("error handler code = %d, err = %v", w.Code, gotErr)
+	}
+}
+
+func TestAdapterExplicitRoutesTakePrecedence(t *testing.T) {
+	router := zallocrout.NewRouter()
+	handler := func(status int) zallocrout.HandlerFunc {
+		return func(ctx context.Context) error {
+			ResponseWriter(ctx).WriteHeader(status)
+			return nil
+		}
+	}
+	_ = router.AddRoute(http.MethodGet, "/x", handler(http.StatusOK))
+	_ = router.AddRoute(http.MethodHead, "/x", handler(http.StatusNoContent))
+	_ = router.AddRoute(http.MethodOptions, "/x", handler(http.StatusAccepted))
+	a := New(router)
+
+	if w := serve(a, http.MethodHead, "/x"); w.Code != http.StatusNoContent {
+		t.Errorf("HEAD code = %d, want explicit route", w.Code)
+	}
+	if w := serve(a, http.MethodOptions, "/x"); w.Code != http.StatusAccepted {
+		t.Errorf("OPTIONS code = %d, want explicit route", w.Code)
+	}
+	if w := serve(a, http.MethodPost, "/x"); w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Errorf("Allow = %q", w.Header().Get("Allow"))
+	}
+}
+
+// nopResponseWriter 不分配内存的 ResponseWriter，用于分配测试
+type nopResponseWriter struct {
+	header http.Header
+}
+
+func (w *nopResponseWriter) Header() http.Header         { return w.header }
+func (w *nopResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *nopResponseWriter) WriteHeader(int)             {}
+
+func TestAdapterZeroAlloc(t *testing.T) {
+	router := zallocrout.NewRouter()
+	_ = router.AddRoute(http.MethodGet, "/users/:id", func(ctx context.Context) error {
+		id, _ := zallocrout.GetParam(ctx, "id")
+		if Request(ctx) == nil || id != "42" {
+			return errors.New("missing request or param")
+		}
+		return nil
+	})
+	a := New(router)
+	w := &nopResponseWriter{header: http.Header{}}
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		a.ServeHTTP(w, r)
+	})
+	if allocs != 0 {
+		t.Errorf("ServeHTTP allocs = %v, want 0", allocs)
+	}
+}
+
+func TestAdapterHost(t *testing.T) {
+	api := zallocrout.NewRouter()
+	_ = api.AddRoute(http.MethodGet, "/users/:id", func(ctx context.Context) error {
+		_, err := io.WriteString(ResponseWriter(ctx), "api")
+		return err
+	})
+	tenant := zallocrout.NewRouter()
+	_ = tenant.AddRoute(http.MethodGet, "/users/:id", func(ctx context.Context) error {
+		name, _ := zallocrout.GetParam(ctx, "tenant")
+		id, _ := zallocrout.GetParam(ctx, "id")
+		_, err := io.WriteString(ResponseWriter(ctx), name+" "+id)
+		return err
+	})
+	mux := zallocrout.NewHostMux()
+	_ = mux.Handle("api.example.com", api)
+	_ = mux.Handle(":tenant.example.com", tenant)
+	a := NewHost(mux)
+
+	tests := []struct {
+		method, host string
+		code         int
+		body, allow  string
+	}{
+		{http.MethodGet, "api.example.com", http.StatusOK, "api", ""},
+		{http.MethodGet, "ACME.example.com:8080", http.StatusOK, "acme 42", ""},
+		{http.MethodHead, "acme.example.com", http.StatusOK, "acme 42", ""},
+		{http.MethodPost, "acme.example.com", http.StatusMethodNotAllowed, "", "GET, HEAD, OPTIONS"},
+		{http.MethodGet, "other.com", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tt.method, "/users/42", nil)
+		r.Host = tt.host
+		a.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s %s code = %d, want %d", tt.method, tt.host, w.Code, tt.code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.host, w.Body.String(), tt.body)
+		}
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.host, got, tt.allow)
+		}
+	}
+}
+
+func TestAdapterHostZeroAlloc(t *testing.T) {
+	router := zallocrout.NewRouter()
+	_ = router.AddRoute(http.MethodGet, "/users/:id", func(ctx context.Context) error {
+		tenant, _ := zallocrout.GetParam(ctx, "tenant")
+		if tenant != "acme" {
+			return errors.New("missing tenant")
+		}
+		return nil
+	})
+	mux := zallocrout.NewHostMux()
+	_ = mux.Handle(":tenant.example.com", router)
+	a := NewHost(mux)
+	w := &nopResponseWriter{header: http.Header{}}
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	r.Host = "acme.example.com:8080"
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		a.ServeHTTP(w, r)
+	})
+	if allocs != 0 {
+		t.Errorf("ServeHTTP allocs = %v, want 0", allocs)
+	}
+}
+
+func TestAdapterRedirect(t *testing.T) {
+	router := zallocrout.NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute(http.MethodGet, "/Docs/:page/", handler)
+	_ = router.AddRoute(http.MethodPost, "/users", handler)
+	router.SetPathPolicy(zallocrout.RedirectTrailingSlash | zallocrout.RedirectCleanPath | zallocrout.RedirectCaseInsensitive)
+	a := New(router)
+
+	tests := []struct {
+		method, target string
+		code           int
+		location       string
+	}{
+		{http.MethodGet, "/Docs/intro/", http.StatusOK, ""},
+		{http.MethodGet, "/Docs/intro?x=1", http.StatusMovedPermanently, "/Docs/intro/?x=1"},
+		{http.MethodHead, "/docs//intro", http.StatusMovedPermanently, "/Docs/intro/"},
+		{http.MethodGet, "/Docs/a%20b", http.StatusMovedPermanently, "/Docs/a%20b/"},
+		{http.MethodPost, "/users/", http.StatusPermanentRedirect, "/users"},
+		{http.MethodPost, "/USERS", http.StatusPermanentRedirect, "/users"},
+		{http.MethodPut, "/users/", http.StatusMethodNotAllowed, ""}, // AllowedMethods 按静默规范化后的路径查找
+		{http.MethodGet, "/missing/", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := serve(a, tt.method, tt.target)
+		if w.Code != tt.code {
+			t.Errorf("%s %s code = %d, want %d", tt.method, tt.target, w.Code, tt.code)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%s %s Location = %q, want %q", tt.method, tt.target, got, tt.location)
+		}
+	}
+}
+
+func BenchmarkAdapterServeHTTP(b *testing.B) {
+	router := zallocrout.NewRouter()
+	_ = router.AddRoute(http.MethodGet, "/users/:id", func(ctx context.Context) error { return nil })
+	a := New(router)
+	w := &nopResponseWriter{header: http.Header{}}
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.ServeHTTP(w, r)
+	}
+}
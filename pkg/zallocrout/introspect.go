@@ -0,0 +1,233 @@
+package zallocrout
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrRouteConflict 新路由与已注册路由冲突（路径相同，或仅参数名不同而永远无法匹配）
+var ErrRouteConflict = errors.New("route conflict")
+
+// 已注册路由的描述信息
+type RouteInfo struct {
+	Method      string   // HTTP 方法
+	Path        string   // 注册路径（含参数约束和末尾斜杠）
+	Name        string   // 路由名称（未命名为空）
+	ParamNames  []string // 参数名称（按顺序，通配符参数为 "*"）
+	Middlewares int      // 生效的中间件数量（含全局和分组中间件）
+	Hits        uint64   // 命中次数
+}
+
+// 单条路由的命中次数
+type RouteHits struct {
+	Method string
+	Path   string
+	Hits   uint64
+}
+
+// 返回所有已注册路由，按方法和路径排序
+func (r *Router) Routes() []RouteInfo {
+	table := r.rlockTable()
+	defer table.mu.RUnlock()
+
+	names := make(map[string]string, len(table.names))
+	for name, nr := range table.names {
+		names[nr.method+" /"+strings.Join(nr.segs, "/")] = name
+	}
+
+	var routes []RouteInfo
+	table.walk(func(method, path string, n *RouteNode) {
+		handler, middlewares, paramNames := n.getHandler()
+		if handler == nil {
+			return
+		}
+		info := RouteInfo{
+			Method:      method,
+			Path:        path,
+			Name:        names[method+" "+path],
+			Middlewares: len(middlewares),
+			Hits:        n.getHitCount(),
+		}
+		for _, name := range paramNames {
+			info.ParamNames = append(info.ParamNames, string(name))
+		}
+		if n.isWildcard {
+			info.ParamNames = append(info.ParamNames, "*")
+		}
+		if n.trailingSlash {
+			info.Path += "/"
+		}
+		routes = append(routes, info)
+	})
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Method != routes[j].Method {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+// 返回各路由的命中次数，按命中次数降序排列（相同时按方法和路径）
+func (r *Router) routeHits() []RouteHits {
+	table := r.rlockTable()
+	defer table.mu.RUnlock()
+
+	var hits []RouteHits
+	table.walk(func(method, path string, n *RouteNode) {
+		if handler, _, _ := n.getHandler(); handler != nil {
+			hits = append(hits, RouteHits{Method: method, Path: path, Hits: n.getHitCount()})
+		}
+	})
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Hits != hits[j].Hits {
+			return hits[i].Hits > hits[j].Hits
+		}
+		if hits[i].Method != hits[j].Method {
+			return hits[i].Method < hits[j].Method
+		}
+		return hits[i].Path < hits[j].Path
+	})
+	return hits
+}
+
+// 清零所有路由的命中次数
+func (r *Router) resetRouteHits() {
+	table := r.rlockTable()
+	defer table.mu.RUnlock()
+
+	table.walk(func(method, path string, n *RouteNode) {
+		n.hitCount.Store(0)
+	})
+}
+
+// 以树形文本输出路由表，用于调试
+// 每个节点一行，有处理器的节点附带中间件数量和命中次数
+func (r *Router) DumpTree() string {
+	table := r.rlockTable()
+	defer table.mu.RUnlock()
+
+	methods := make([]string, 0, len(table.roots))
+	for method := range table.roots {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	var b strings.Builder
+	for _, method := range methods {
+		root := table.roots[method]
+		b.WriteString(method)
+		b.WriteString(" /")
+		writeNodeInfo(&b, root)
+		b.WriteByte('\n')
+		dumpChildren(&b, root, "")
+	}
+	return b.String()
+}
+
+// 递归输出子节点
+func dumpChildren(b *strings.Builder, n *RouteNode, indent string) {
+	children := sortedChildren(n)
+	for i, child := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		b.WriteString(indent)
+		b.WriteString(branch)
+		b.Write(child.seg)
+		writeNodeInfo(b, child)
+		b.WriteByte('\n')
+		dumpChildren(b, child, indent+next)
+	}
+}
+
+// 输出节点的处理器信息
+func writeNodeInfo(b *strings.Builder, n *RouteNode) {
+	handler, middlewares, _ := n.getHandler()
+	if handler != nil {
+		fmt.Fprintf(b, " [middlewares=%d hits=%d]", len(middlewares), n.getHitCount())
+	}
+}
+
+// 按匹配顺序返回子节点：静态（按片段排序）> 参数（按优先级）> 通配符
+func sortedChildren(n *RouteNode) []*RouteNode {
+	keys := make([]string, 0, len(n.staticChildren))
+	for key := range n.staticChildren {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	children := make([]*RouteNode, 0, len(keys))
+	for _, key := range keys {
+		children = append(children, n.staticChildren[key])
+	}
+	children = append(children, n.findParamChildren()...)
+	if child := n.findWildcardChild(); child != nil {
+		children = append(children, child)
+	}
+	return children
+}
+
+// 深度优先遍历路由表的所有节点，path 为节点对应的注册路径，调用方需持有路由表锁
+func (t *routeTable) walk(fn func(method, path string, n *RouteNode)) {
+	for method, root := range t.roots {
+		walkNode(method, "", root, fn)
+	}
+}
+
+// 递归遍历子树
+func walkNode(method, prefix string, n *RouteNode, fn func(method, path string, n *RouteNode)) {
+	path := prefix
+	if path == "" {
+		path = "/"
+	}
+	fn(method, path, n)
+	for _, child := range sortedChildren(n) {
+		walkNode(method, prefix+"/"+string(child.seg), child, fn)
+	}
+}
+
+// 查找与新路由冲突的已注册路由，返回其路径
+// 静态片段相同、参数约束相同（参数名可不同）、通配符位置相同的路由形状一致，先注册的路由总是优先匹配，后注册的永远无法命中
+func findConflict(root *RouteNode, segs []string, prefix string) (string, bool) {
+	if len(segs) == 0 {
+		if handler, _, _ := root.getHandler(); handler != nil {
+			if prefix == "" {
+				prefix = "/"
+			}
+			return prefix, true
+		}
+		return "", false
+	}
+
+	seg := segs[0]
+	switch {
+	case isStaticSeg(seg):
+		if child, ok := root.findStaticChild(seg); ok {
+			return findConflict(child, segs[1:], prefix+"/"+seg)
+		}
+	case isParamSeg(seg):
+		spec := paramSegSpec(seg)
+		for _, child := range root.findParamChildren() {
+			if paramSegSpec(unsafeString(child.seg)) != spec {
+				continue
+			}
+			if path, ok := findConflict(child, segs[1:], prefix+"/"+string(child.seg)); ok {
+				return path, true
+			}
+		}
+	case isWildcardSeg(seg):
+		if child := root.findWildcardChild(); child != nil {
+			return findConflict(child, nil, prefix+"/"+string(child.seg))
+		}
+	}
+	return "", false
+}
+
+// 返回参数片段中的约束部分（如 ":id<int>" → "<int>"，无约束时为空）
+func paramSegSpec(seg string) string {
+	return seg[1+len(paramSegName(seg)):]
+}
@@ -0,0 +1,195 @@
+package zallocrout
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 测试路由冲突检测
+func TestRouter_AddRouteConflict(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	for _, path := range []string{"/users/:id", "/users/:id<int>/posts", "/files/:id/raw", "/static/*path", "/"} {
+		if err := router.AddRoute("GET", path, handler); err != nil {
+			t.Fatalf("AddRoute(%s) error = %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		path     string
+		conflict string
+	}{
+		{"/users/:id", "/users/:id"},   // 重复注册
+		{"/users/:name", "/users/:id"}, // 仅参数名不同
+		{"/users/:uid<int>/posts", "/users/:id<int>/posts"},
+		{"/static/*file", "/static/*path"},
+		{"/users/:id/", "/users/:id"}, // 末尾斜杠视为同一路径
+		{"/", "/"},
+	}
+	for _, tt := range tests {
+		err := router.AddRoute("GET", tt.path, handler)
+		if !errors.Is(err, ErrRouteConflict) {
+			t.Errorf("AddRoute(%s) error = %v, want ErrRouteConflict", tt.path, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "GET "+tt.conflict) {
+			t.Errorf("AddRoute(%s) error = %q, should mention %s", tt.path, err, tt.conflict)
+		}
+	}
+
+	// 可以通过约束或回溯区分的路由不冲突
+	for _, path := range []string{"/users/:id<int>", "/users/:name/posts", "/files/*path", "/static"} {
+		if err := router.AddRoute("GET", path, handler); err != nil {
+			t.Errorf("AddRoute(%s) error = %v", path, err)
+		}
+	}
+	if err := router.AddRoute("POST", "/users/:id", handler); err != nil {
+		t.Errorf("same path with another method error = %v", err)
+	}
+
+	// 冲突不影响原路由，移除后可以重新注册
+	if err := router.RemoveRoute("GET", "/users/:id"); err != nil {
+		t.Fatalf("RemoveRoute error = %v", err)
+	}
+	if err := router.AddRoute("GET", "/users/:name", handler); err != nil {
+		t.Errorf("AddRoute after remove error = %v", err)
+	}
+}
+
+// 测试列出已注册路由
+func TestRouter_Routes(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	mw := func(next HandlerFunc) HandlerFunc { return next }
+	router.Use(mw)
+	_ = router.AddNamedRoute("user", "GET", "/users/:id<int>", handler, mw)
+	_ = router.AddRoute("GET", "/users/:id<int>/posts/:post", handler)
+	_ = router.AddRoute("POST", "/files/*path", handler)
+	_ = router.AddRoute("GET", "/", handler)
+
+	for i := 0; i < 3; i++ {
+		ctx, _, _, _ := router.Match("GET", "/users/1", context.Background())
+		ReleaseContext(ctx)
+	}
+
+	want := []RouteInfo{
+		{Method: "GET", Path: "/", Middlewares: 1},
+		{Method: "GET", Path: "/users/:id<int>", Name: "user", ParamNames: []string{"id"}, Middlewares: 2, Hits: 3},
+		{Method: "GET", Path: "/users/:id<int>/posts/:post", ParamNames: []string{"id", "post"}, Middlewares: 1},
+		{Method: "POST", Path: "/files/*path", ParamNames: []string{"*"}, Middlewares: 1},
+	}
+	if got := router.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// 测试路由树输出
+func TestRouter_DumpTree(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/users/:id<int>", handler)
+	_ = router.AddRoute("GET", "/users/new", handler)
+	_ = router.AddRoute("GET", "/users/:name", handler)
+	_ = router.AddRoute("GET", "/files/*path", handler)
+	_ = router.AddRoute("POST", "/users", handler)
+
+	want := `GET /
+├── files
+│   └── *path [middlewares=0 hits=0]
+└── users
+    ├── new [middlewares=0 hits=0]
+    ├── :id<int> [middlewares=0 hits=0]
+    └── :name [middlewares=0 hits=0]
+POST /
+└── users [middlewares=0 hits=0]
+`
+	if got := router.DumpTree(); got != want {
+		t.Errorf("DumpTree() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// 测试路由命中计数（包括热点缓存命中和通配符路由）
+func TestRouter_RouteHitsMetrics(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/users/:id", handler)
+	_ = router.AddRoute("GET", "/files/*path", handler)
+	_ = router.AddRoute("GET", "/idle", handler)
+
+	const n = hotCacheThreshold * 2
+	for i := 0; i < n; i++ {
+		ctx, _, _, _ := router.Match("GET", "/users/1", context.Background())
+		ReleaseContext(ctx)
+	}
+	ctx, _, _, _ := router.Match("GET", "/files/a/b", context.Background())
+	ReleaseContext(ctx)
+	router.Match("GET", "/missing", context.Background())
+
+	m := router.Metrics()
+	if m.CacheHits == 0 {
+		t.Fatal("hot cache should be used")
+	}
+	want := []RouteHits{
+		{Method: "GET", Path: "/users/:id", Hits: n},
+		{Method: "GET", Path: "/files/*path", Hits: 1},
+		{Method: "GET", Path: "/idle", Hits: 0},
+	}
+	if !reflect.DeepEqual(m.RouteHits, want) {
+		t.Errorf("RouteHits = %+v, want %+v", m.RouteHits, want)
+	}
+
+	router.ResetMetrics()
+	for _, h := range router.Metrics().RouteHits {
+		if h.Hits != 0 {
+			t.Errorf("route %s hits = %d after reset", h.Path, h.Hits)
+		}
+	}
+}
+
+// 测试缓存条目取出后路由被删除或替换时，命中不计入复用同一节点的其他路由
+func TestRouter_RouteHitsCachedAfterRemove(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/keep", handler) // 保留方法根节点，使删除的节点被新路由复用
+	_ = router.AddRoute("GET", "/old", handler)
+	for i := 0; i <= hotCacheThreshold+1; i++ {
+		ctx, _, _, _ := router.Match("GET", "/old", context.Background())
+		ReleaseContext(ctx)
+	}
+	entry, ok := router.hotCache.LoadWithMethodPath("GET", "/old")
+	if !ok {
+		t.Fatal("route should be in hot cache")
+	}
+
+	// 模拟匹配取出条目后、计数前路由被删除，节点归还节点池后被新路由复用
+	if err := router.RemoveRoute("GET", "/old"); err != nil {
+		t.Fatalf("RemoveRoute error = %v", err)
+	}
+	_ = router.AddRoute("GET", "/new", handler)
+	router.countCachedHit(entry)
+	for _, h := range router.Metrics().RouteHits {
+		if h.Hits != 0 {
+			t.Errorf("route %s hits = %d, want 0", h.Path, h.Hits)
+		}
+	}
+
+	// 路由表被 Swap 替换后同样不再计数
+	_ = router.AddRoute("GET", "/old", handler)
+	for i := 0; i <= hotCacheThreshold+1; i++ {
+		ctx, _, _, _ := router.Match("GET", "/old", context.Background())
+		ReleaseContext(ctx)
+	}
+	entry, _ = router.hotCache.LoadWithMethodPath("GET", "/old")
+	next := NewRouter()
+	_ = next.AddRoute("GET", "/old", handler)
+	router.Swap(next)
+	router.countCachedHit(entry)
+	for _, h := range router.Metrics().RouteHits {
+		if h.Hits != 0 {
+			t.Errorf("route %s hits = %d after Swap, want 0", h.Path, h.Hits)
+		}
+	}
+}
@@ -13,6 +13,8 @@ type RouterMetrics struct {
 	ParamRoutes    uint64 // 参数路由数量
 	WildcardRoutes uint64 // 通配符路由数量
 	TotalMatches   uint64 // 总匹配次数
+
+	RouteHits []RouteHits // 各路由命中次数（按命中次数降序，仅 Router.Metrics 填充）
 }
 
 // 本地计数器（减少原子操作竞争）
@@ -109,6 +111,35 @@ func (m *RouterMetrics) IncrementWildcardRoutes() {
 	atomic.AddUint64(&m.WildcardRoutes, 1)
 }
 
+// 减少静态路由数量（移除路由时调用，不在热路径）
+func (m *RouterMetrics) DecrementStaticRoutes() {
+	atomic.AddUint64(&m.StaticRoutes, ^uint64(0))
+}
+
+// 减少参数路由数量（移除路由时调用，不在热路径）
+func (m *RouterMetrics) DecrementParamRoutes() {
+	atomic.AddUint64(&m.ParamRoutes, ^uint64(0))
+}
+
+// 减少通配符路由数量（移除路由时调用，不在热路径）
+func (m *RouterMetrics) DecrementWildcardRoutes() {
+	atomic.AddUint64(&m.WildcardRoutes, ^uint64(0))
+}
+
+// 取出并清零路由数量（Swap 转移路由表时调用）
+func (m *RouterMetrics) takeRouteCounts() (static, param, wildcard uint64) {
+	return atomic.SwapUint64(&m.StaticRoutes, 0),
+		atomic.SwapUint64(&m.ParamRoutes, 0),
+		atomic.SwapUint64(&m.WildcardRoutes, 0)
+}
+
+// 设置路由数量（Swap 转移路由表时调用）
+func (m *RouterMetrics) setRouteCounts(static, param, wildcard uint64) {
+	atomic.StoreUint64(&m.StaticRoutes, static)
+	atomic.StoreUint64(&m.ParamRoutes, param)
+	atomic.StoreUint64(&m.WildcardRoutes, wildcard)
+}
+
 // 获取缓存命中率
 func (m *RouterMetrics) CacheHitRate() float64 {
 	hits := atomic.LoadUint64(&m.CacheHits)
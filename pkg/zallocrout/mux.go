@@ -0,0 +1,322 @@
+package zallocrout
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultHostWildcardParam 未命名的主机通配符（"*.example.com"）捕获值使用的参数名
+const DefaultHostWildcardParam = "subdomain"
+
+// 主机模式标签类型
+type hostLabelKind uint8
+
+const (
+	hostLabelStatic   hostLabelKind = iota // 静态标签（如 example）
+	hostLabelParam                         // 参数标签（如 :tenant、:id<int>），匹配一个标签
+	hostLabelWildcard                      // 通配符标签（如 *、*sub），匹配最左侧的一个或多个标签
+)
+
+// 主机模式的一个标签
+type hostLabel struct {
+	kind       hostLabelKind
+	value      string           // 静态标签值
+	name       string           // 参数名
+	constraint *paramConstraint // 参数约束
+}
+
+// 键模式（精确键之外的带参数或通配符的模式）
+type keyPattern struct {
+	pattern    string
+	labels     []hostLabel // 从左到右
+	paramCount int
+	router     *Router
+}
+
+// 多路复用器的分发表（写时复制，匹配时无锁读取）
+type muxTable struct {
+	exact    map[string]*Router
+	patterns []*keyPattern // 按优先级排序
+}
+
+// 多路复用器，在路由匹配之前按主机名或自定义键（如 RPC 服务名）选择路由器
+// 键以 '.' 分隔为标签，模式中的标签可以是参数（:tenant）或位于最左侧的通配符（*），捕获值作为路由参数传给处理函数
+type Mux struct {
+	mu    sync.Mutex // 保护写入
+	table atomic.Pointer[muxTable]
+	host  bool // 主机模式：匹配前去掉端口并转为小写
+}
+
+// 创建按自定义键分发的多路复用器（键区分大小写）
+func NewMux() *Mux {
+	m := &Mux{}
+	m.table.Store(&muxTable{exact: make(map[string]*Router)})
+	return m
+}
+
+// 创建按主机名分发的多路复用器（忽略端口，不区分大小写）
+func NewHostMux() *Mux {
+	m := NewMux()
+	m.host = true
+	return m
+}
+
+// 注册键模式对应的路由器
+// pattern: 精确键（api.example.com、user.UserService）或模式（:tenant.example.com、*.example.com、*）
+// 匹配顺序：精确键 > 不含通配符的模式 > 含通配符的模式，同类中标签多的优先，其余按注册顺序
+func (m *Mux) Handle(pattern string, router *Router) error {
+	if router == nil {
+		return errors.New("router cannot be nil")
+	}
+	pattern, kp, err := parseKeyPattern(pattern, m.host)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old := m.table.Load()
+	if _, exists := old.exact[pattern]; exists || old.findPattern(pattern) >= 0 {
+		return errors.New("key pattern already exists: " + pattern)
+	}
+
+	next := old.clone()
+	if kp == nil {
+		next.exact[pattern] = router
+	} else {
+		kp.router = router
+		next.patterns = append(next.patterns, kp)
+		sort.SliceStable(next.patterns, func(i, j int) bool {
+			return next.patterns[i].less(next.patterns[j])
+		})
+	}
+	m.table.Store(next)
+	return nil
+}
+
+// 移除键模式
+func (m *Mux) Remove(pattern string) error {
+	pattern, _, err := parseKeyPattern(pattern, m.host)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old := m.table.Load()
+	next := old.clone()
+	if _, exists := next.exact[pattern]; exists {
+		delete(next.exact, pattern)
+	} else if i := next.findPattern(pattern); i >= 0 {
+		next.patterns = append(next.patterns[:i], next.patterns[i+1:]...)
+	} else {
+		return errors.New("key pattern not found: " + pattern)
+	}
+	m.table.Store(next)
+	return nil
+}
+
+// 按键选择路由器并匹配路由，键模式的捕获值追加在路径参数之后（同名时路径参数优先）
+// 键为全小写主机名（或自定义键）时整个流程零分配
+//
+//go:inline
+func (m *Mux) Match(key, method, path string, parent context.Context) (context.Context, HandlerFunc, []Middleware, bool) {
+	var pairs [MaxParams]paramPair
+	router, count := m.lookup(key, &pairs)
+	if router == nil {
+		return nil, nil, nil, false
+	}
+
+	ctx, handler, middlewares, ok := router.Match(method, path, parent)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	if count > 0 {
+		ctx.(*routeContext).appendParams(pairs[:count])
+	}
+	return ctx, handler, middlewares, true
+}
+
+// 返回键对应的路由器在路径上允许的方法（用于 405/OPTIONS），键不匹配时返回 nil
+func (m *Mux) AllowedMethods(key, path string) []string {
+	var pairs [MaxParams]paramPair
+	router, _ := m.lookup(key, &pairs)
+	if router == nil {
+		return nil
+	}
+	return router.AllowedMethods(path)
+}
+
+// 按键对应的路由器的路径修正策略返回规范路径（见 Router.RedirectPath），键不匹配时返回 false
+func (m *Mux) RedirectPath(key, method, path string) (string, bool) {
+	var pairs [MaxParams]paramPair
+	router, _ := m.lookup(key, &pairs)
+	if router == nil {
+		return "", false
+	}
+	return router.RedirectPath(method, path)
+}
+
+// 按键查找路由器，捕获值写入 pairs
+func (m *Mux) lookup(key string, pairs *[MaxParams]paramPair) (*Router, int) {
+	if m.host {
+		key = normalizeHost(key)
+	}
+	t := m.table.Load()
+	if router, ok := t.exact[key]; ok {
+		return router, 0
+	}
+	for _, kp := range t.patterns {
+		if kp.match(key, pairs) {
+			return kp.router, kp.paramCount
+		}
+	}
+	return nil, 0
+}
+
+// 复制分发表
+func (t *muxTable) clone() *muxTable {
+	next := &muxTable{
+		exact:    make(map[string]*Router, len(t.exact)+1),
+		patterns: append([]*keyPattern(nil), t.patterns...),
+	}
+	for k, v := range t.exact {
+		next.exact[k] = v
+	}
+	return next
+}
+
+// 查找模式的位置，不存在时返回 -1
+func (t *muxTable) findPattern(pattern string) int {
+	for i, kp := range t.patterns {
+		if kp.pattern == pattern {
+			return i
+		}
+	}
+	return -1
+}
+
+// 解析键模式，返回规范化后的模式（fold 为 true 时静态标签转为小写），精确键的 *keyPattern 为 nil
+func parseKeyPattern(pattern string, fold bool) (string, *keyPattern, error) {
+	if pattern == "" {
+		return "", nil, errors.New("key pattern cannot be empty")
+	}
+	parts := strings.Split(pattern, ".")
+	kp := &keyPattern{labels: make([]hostLabel, len(parts))}
+	exact := true
+	for i, part := range parts {
+		switch {
+		case part == "":
+			return "", nil, errors.New("key pattern cannot contain empty label: " + pattern)
+		case part[0] == '*':
+			if i != 0 {
+				return "", nil, errors.New("key wildcard must be the first label: " + pattern)
+			}
+			name := part[1:]
+			if name == "" {
+				name = DefaultHostWildcardParam
+			}
+			kp.labels[i] = hostLabel{kind: hostLabelWildcard, name: name}
+			kp.paramCount++
+			exact = false
+		case part[0] == ':':
+			name, constraint, err := parseParamSeg(part)
+			if err != nil {
+				return "", nil, err
+			}
+			kp.labels[i] = hostLabel{kind: hostLabelParam, name: name, constraint: constraint}
+			kp.paramCount++
+			exact = false
+		default:
+			if fold {
+				// 只转换静态标签，参数约束中的正则表达式保持原样
+				part = strings.ToLower(part)
+				parts[i] = part
+			}
+			kp.labels[i] = hostLabel{kind: hostLabelStatic, value: part}
+		}
+	}
+	kp.pattern = strings.Join(parts, ".")
+	if exact {
+		return kp.pattern, nil, nil
+	}
+	return kp.pattern, kp, nil
+}
+
+// 模式优先级比较：不含通配符的优先，标签多的优先
+func (kp *keyPattern) less(other *keyPattern) bool {
+	w1, w2 := kp.labels[0].kind == hostLabelWildcard, other.labels[0].kind == hostLabelWildcard
+	if w1 != w2 {
+		return !w1
+	}
+	return len(kp.labels) > len(other.labels)
+}
+
+// 从右向左逐个标签匹配键（零分配），捕获值按标签从左到右的顺序写入 pairs
+func (kp *keyPattern) match(key string, pairs *[MaxParams]paramPair) bool {
+	end := len(key)
+	idx := kp.paramCount
+	for i := len(kp.labels) - 1; i >= 0; i-- {
+		label := &kp.labels[i]
+		if label.kind == hostLabelWildcard {
+			// 通配符匹配剩余的一个或多个标签
+			if end <= 0 {
+				return false
+			}
+			idx--
+			pairs[idx] = paramPair{key: label.name, value: key[:end]}
+			return true
+		}
+
+		if end < 0 {
+			return false
+		}
+		start := strings.LastIndexByte(key[:end], '.') + 1
+		value := key[start:end]
+		if value == "" {
+			return false
+		}
+		switch label.kind {
+		case hostLabelStatic:
+			if value != label.value {
+				return false
+			}
+		case hostLabelParam:
+			num, ok := label.constraint.match(value)
+			if !ok {
+				return false
+			}
+			idx--
+			pairs[idx] = paramPair{key: label.name, value: value, num: num,
+				isNum: label.constraint != nil && label.constraint.kind == constraintInt}
+		}
+		end = start - 1 // 跳过 '.'
+	}
+	// 必须消费整个键
+	return end < 0
+}
+
+// 规范化主机名：去掉端口，转为小写（已是小写时不分配）
+//
+//go:inline
+func normalizeHost(host string) string {
+	if strings.HasPrefix(host, "[") {
+		// IPv6 字面量（[::1]:8080）
+		if i := strings.IndexByte(host, ']'); i > 0 {
+			host = host[:i+1]
+		}
+	} else if i := strings.LastIndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+	host = strings.TrimSuffix(host, ".")
+	for i := 0; i < len(host); i++ {
+		if c := host[i]; c >= 'A' && c <= 'Z' {
+			return strings.ToLower(host)
+		}
+	}
+	return host
+}
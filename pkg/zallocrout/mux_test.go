@@ -0,0 +1,231 @@
+package zallocrout
+
+import (
+	"context"
+	"testing"
+)
+
+// 创建只注册一条 GET 路由的路由器，处理函数记录路由器名称
+func newMuxTestRouter(t *testing.T, name string, path string, got *string) *Router {
+	t.Helper()
+	router := NewRouter()
+	if err := router.AddRoute("GET", path, func(ctx context.Context) error {
+		*got = name
+		return nil
+	}); err != nil {
+		t.Fatalf("AddRoute(%s) error = %v", path, err)
+	}
+	return router
+}
+
+// 测试主机名分发
+func TestMux_Host(t *testing.T) {
+	var got string
+	mux := NewHostMux()
+	patterns := []struct {
+		pattern, name string
+	}{
+		{"*", "fallback"},
+		{"*.example.com", "wildcard"},
+		{":tenant.example.com", "tenant"},
+		{"API.example.com", "api"},
+		{":n<int>.shard.example.com", "shard"},
+	}
+	for _, p := range patterns {
+		if err := mux.Handle(p.pattern, newMuxTestRouter(t, p.name, "/users/:id", &got)); err != nil {
+			t.Fatalf("Handle(%s) error = %v", p.pattern, err)
+		}
+	}
+
+	tests := []struct {
+		host    string
+		want    string
+		params  map[string]string
+		matched bool
+	}{
+		{"api.example.com", "api", nil, true},
+		{"Api.Example.COM:8080", "api", nil, true},
+		{"acme.example.com", "tenant", map[string]string{"tenant": "acme"}, true},
+		{"acme.example.com.", "tenant", map[string]string{"tenant": "acme"}, true},
+		{"a.b.example.com", "wildcard", map[string]string{DefaultHostWildcardParam: "a.b"}, true},
+		{"42.shard.example.com", "shard", map[string]string{"n": "42"}, true},
+		{"x.shard.example.com", "wildcard", map[string]string{DefaultHostWildcardParam: "x.shard"}, true},
+		{"example.com", "fallback", map[string]string{DefaultHostWildcardParam: "example.com"}, true},
+		{"[::1]:8080", "fallback", map[string]string{DefaultHostWildcardParam: "[::1]"}, true},
+	}
+	for _, tt := range tests {
+		got = ""
+		ctx, handler, mws, ok := mux.Match(tt.host, "GET", "/users/7", context.Background())
+		if ok != tt.matched {
+			t.Fatalf("Match(%s) ok = %v, want %v", tt.host, ok, tt.matched)
+		}
+		if !ok {
+			continue
+		}
+		for key, want := range tt.params {
+			if v, _ := GetParam(ctx, key); v != want {
+				t.Errorf("Match(%s) param %s = %q, want %q", tt.host, key, v, want)
+			}
+		}
+		if v, _ := GetParam(ctx, "id"); v != "7" {
+			t.Errorf("Match(%s) path param id = %q, want 7", tt.host, v)
+		}
+		if err := ExecuteHandler(ctx, handler, mws); err != nil {
+			t.Fatalf("ExecuteHandler error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Match(%s) router = %s, want %s", tt.host, got, tt.want)
+		}
+	}
+}
+
+// 测试主机参数的整数约束
+func TestMux_HostParamInt(t *testing.T) {
+	var got string
+	mux := NewHostMux()
+	if err := mux.Handle(":shard<int>.db.local", newMuxTestRouter(t, "db", "/", &got)); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+
+	ctx, _, _, ok := mux.Match("12.db.local", "GET", "/", context.Background())
+	if !ok {
+		t.Fatal("Match failed")
+	}
+	if n, ok := GetParamInt(ctx, "shard"); !ok || n != 12 {
+		t.Errorf("GetParamInt(shard) = %d, %v, want 12, true", n, ok)
+	}
+	ReleaseContext(ctx)
+
+	if _, _, _, ok := mux.Match("x.db.local", "GET", "/", context.Background()); ok {
+		t.Error("Match(x.db.local) should fail the int constraint")
+	}
+}
+
+// 测试自定义键分发（如 RPC 服务名）
+func TestMux_CustomKey(t *testing.T) {
+	var got string
+	mux := NewMux()
+	if err := mux.Handle("user.UserService", newMuxTestRouter(t, "user", "/GetUser", &got)); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+	if err := mux.Handle("*svc.v1", newMuxTestRouter(t, "v1", "/GetUser", &got)); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+
+	ctx, _, _, ok := mux.Match("user.UserService", "GET", "/GetUser", context.Background())
+	if !ok {
+		t.Fatal("Match(user.UserService) failed")
+	}
+	ReleaseContext(ctx)
+
+	// 自定义键区分大小写
+	if _, _, _, ok := mux.Match("user.userservice", "GET", "/GetUser", context.Background()); ok {
+		t.Error("custom keys should be case sensitive")
+	}
+
+	ctx, _, _, ok = mux.Match("order.OrderService.v1", "GET", "/GetUser", context.Background())
+	if !ok {
+		t.Fatal("Match(order.OrderService.v1) failed")
+	}
+	if v, _ := GetParam(ctx, "svc"); v != "order.OrderService" {
+		t.Errorf("GetParam(svc) = %q, want order.OrderService", v)
+	}
+	ReleaseContext(ctx)
+
+	// 键匹配但路径不匹配
+	if _, _, _, ok := mux.Match("user.UserService", "GET", "/Missing", context.Background()); ok {
+		t.Error("Match should fail for unknown path")
+	}
+	if methods := mux.AllowedMethods("user.UserService", "/GetUser"); len(methods) != 1 || methods[0] != "GET" {
+		t.Errorf("AllowedMethods = %v, want [GET]", methods)
+	}
+	if methods := mux.AllowedMethods("unknown", "/GetUser"); methods != nil {
+		t.Errorf("AllowedMethods(unknown) = %v, want nil", methods)
+	}
+}
+
+// 测试模式注册、重复和移除
+func TestMux_HandleRemove(t *testing.T) {
+	var got string
+	mux := NewHostMux()
+	router := newMuxTestRouter(t, "r", "/", &got)
+
+	invalid := []string{"", "a..b", "a.*.com", ":.example.com", ":id<foo>.example.com"}
+	for _, pattern := range invalid {
+		if err := mux.Handle(pattern, router); err == nil {
+			t.Errorf("Handle(%q) should fail", pattern)
+		}
+	}
+	if err := mux.Handle("example.com", nil); err == nil {
+		t.Error("Handle with nil router should fail")
+	}
+
+	if err := mux.Handle("*.Example.com", router); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+	if err := mux.Handle("*.example.COM", router); err == nil {
+		t.Error("duplicate host pattern should fail")
+	}
+	if err := mux.Handle("example.com", router); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+	if err := mux.Handle("EXAMPLE.com", router); err == nil {
+		t.Error("duplicate exact host should fail")
+	}
+
+	if err := mux.Remove("*.EXAMPLE.com"); err != nil {
+		t.Fatalf("Remove error = %v", err)
+	}
+	if _, _, _, ok := mux.Match("a.example.com", "GET", "/", context.Background()); ok {
+		t.Error("removed pattern should not match")
+	}
+	if err := mux.Remove("example.com"); err != nil {
+		t.Fatalf("Remove error = %v", err)
+	}
+	if err := mux.Remove("example.com"); err == nil {
+		t.Error("Remove of missing pattern should fail")
+	}
+}
+
+// 测试主机匹配零分配
+func TestMux_ZeroAlloc(t *testing.T) {
+	var got string
+	mux := NewHostMux()
+	if err := mux.Handle("api.example.com", newMuxTestRouter(t, "api", "/users/:id", &got)); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+	if err := mux.Handle(":tenant.example.com", newMuxTestRouter(t, "tenant", "/users/:id", &got)); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+
+	for _, host := range []string{"api.example.com", "acme.example.com:8080"} {
+		allocs := testing.AllocsPerRun(200, func() {
+			ctx, _, _, ok := mux.Match(host, "GET", "/users/1", context.Background())
+			if !ok {
+				t.Fatalf("Match(%s) failed", host)
+			}
+			ReleaseContext(ctx)
+		})
+		if allocs != 0 {
+			t.Errorf("Match(%s) allocs = %v, want 0", host, allocs)
+		}
+	}
+}
+
+// 测试按键对应的路由器返回规范路径
+func TestMux_RedirectPath(t *testing.T) {
+	var got string
+	router := newMuxTestRouter(t, "api", "/users/:id", &got)
+	router.SetPathPolicy(RedirectTrailingSlash)
+	mux := NewHostMux()
+	if err := mux.Handle("api.example.com", router); err != nil {
+		t.Fatalf("Handle error = %v", err)
+	}
+
+	if path, ok := mux.RedirectPath("API.example.com:443", "GET", "/users/1/"); !ok || path != "/users/1" {
+		t.Errorf("RedirectPath = %q, %v, want /users/1, true", path, ok)
+	}
+	if _, ok := mux.RedirectPath("other.com", "GET", "/users/1/"); ok {
+		t.Error("RedirectPath should fail for unknown host")
+	}
+}
@@ -25,28 +25,33 @@ type Middleware func(HandlerFunc) HandlerFunc
 // 将高频访问字段放在结构体前部，确保在同一个 CPU 缓存行
 type RouteNode struct {
 	// 无锁静态匹配部分（只读，无需锁）
-	staticChildren map[string]*RouteNode // 静态子节点哈希表（O(1)查找）
-	seg            []byte                // 路径片段（字节切片）
-	paramChild     atomic.Pointer[RouteNode] // 参数子节点（原子指针，无锁）
-	wildcardChild  atomic.Pointer[RouteNode] // 通配符子节点（原子指针，无锁）
+	staticChildren map[string]*RouteNode        // 静态子节点哈希表（O(1)查找）
+	seg            []byte                       // 路径片段（字节切片）
+	paramChildren  atomic.Pointer[[]*RouteNode] // 参数子节点（按约束优先级排序，写时复制，无锁读取）
+	wildcardChild  atomic.Pointer[RouteNode]    // 通配符子节点（原子指针，无锁）
 
 	// 动态匹配部分（需锁）
-	mu            sync.RWMutex // 节点级锁
-	typ           NodeType     // 节点类型
-	handler       HandlerFunc  // 处理函数
-	middlewares   []Middleware // 预编译中间件链
-	paramName     []byte     // 参数名称（如 :id → "id"）
-	paramNames    [][]byte   // 此路由的参数名称列表（按顺序，字节切片避免转换开销）
+	mu               sync.RWMutex     // 节点级锁
+	typ              NodeType         // 节点类型
+	handler          HandlerFunc      // 处理函数
+	middlewares      []Middleware     // 预编译中间件链（全局中间件 + 路由中间件）
+	routeMiddlewares []Middleware     // 路由自身的中间件（含分组中间件）
+	paramName        []byte           // 参数名称（如 :id<int> → "id"）
+	constraint       *paramConstraint // 参数约束（nil 表示不限）
+	paramNames       [][]byte         // 此路由的参数名称列表（按顺序，字节切片避免转换开销）
 
 	// 性能优化字段
-	isWildcard bool   // 预计算通配符标记
-	hitCount   uint32 // 命中计数（热点缓存用）
+	isWildcard    bool          // 预计算通配符标记
+	trailingSlash bool          // 路由注册时是否带末尾斜杠（RedirectTrailingSlash 策略使用）
+	hitCount      atomic.Uint64 // 命中计数（热点缓存阈值和路由命中指标）
 }
 
 // 参数键值对（栈分配）
 type paramPair struct {
 	key   string
 	value string
+	num   int  // <int> 约束参数匹配时解析的整数
+	isNum bool // num 是否有效
 }
 
 // MaxParams 最大参数数量（固定32个，覆盖99%场景）
@@ -61,12 +66,59 @@ func (n *RouteNode) insertChild(seg string, child *RouteNode) {
 	case StaticCompressed:
 		n.staticChildren[seg] = child
 	case ParamNode:
-		n.paramChild.Store(child)
+		// 按优先级插入，同优先级保持注册顺序
+		children := n.findParamChildren()
+		pos := len(children)
+		for i, c := range children {
+			if child.constraint.priority() < c.constraint.priority() {
+				pos = i
+				break
+			}
+		}
+		next := make([]*RouteNode, 0, len(children)+1)
+		next = append(next, children[:pos]...)
+		next = append(next, child)
+		next = append(next, children[pos:]...)
+		n.paramChildren.Store(&next)
 	case WildcardNode:
 		n.wildcardChild.Store(child)
 	}
 }
 
+// 移除子节点
+func (n *RouteNode) removeChild(seg string, child *RouteNode) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch child.typ {
+	case StaticCompressed:
+		delete(n.staticChildren, seg)
+	case ParamNode:
+		children := n.findParamChildren()
+		next := make([]*RouteNode, 0, len(children))
+		for _, c := range children {
+			if c != child {
+				next = append(next, c)
+			}
+		}
+		if len(next) == 0 {
+			n.paramChildren.Store(nil)
+		} else {
+			n.paramChildren.Store(&next)
+		}
+	case WildcardNode:
+		n.wildcardChild.CompareAndSwap(child, nil)
+	}
+}
+
+// 节点是否既没有处理器也没有子节点（可剪除）
+func (n *RouteNode) isEmpty() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.handler == nil && len(n.staticChildren) == 0 &&
+		n.paramChildren.Load() == nil && n.wildcardChild.Load() == nil
+}
+
 // 查找子节点（静态）
 func (n *RouteNode) findStaticChild(seg string) (*RouteNode, bool) {
 	// 静态子节点无锁查找
@@ -74,9 +126,24 @@ func (n *RouteNode) findStaticChild(seg string) (*RouteNode, bool) {
 	return child, ok
 }
 
-// 查找参数子节点（无锁原子操作）
-func (n *RouteNode) findParamChild() *RouteNode {
-	return n.paramChild.Load()
+// 获取所有参数子节点（按匹配优先级排序，无锁原子操作）
+//
+//go:inline
+func (n *RouteNode) findParamChildren() []*RouteNode {
+	if children := n.paramChildren.Load(); children != nil {
+		return *children
+	}
+	return nil
+}
+
+// 按注册片段查找参数子节点（如 ":id<int>"）
+func (n *RouteNode) findParamChild(seg string) *RouteNode {
+	for _, child := range n.findParamChildren() {
+		if string(child.seg) == seg {
+			return child
+		}
+	}
+	return nil
 }
 
 // 查找通配符子节点（无锁原子操作）
@@ -101,9 +168,8 @@ func (n *RouteNode) setHandlerWithParams(handler HandlerFunc, middlewares []Midd
 	defer n.mu.Unlock()
 
 	n.handler = handler
-	if len(middlewares) > 0 {
-		n.middlewares = append(n.middlewares[:0], middlewares...)
-	}
+	n.routeMiddlewares = joinMiddlewares(nil, middlewares)
+	n.middlewares = n.routeMiddlewares
 	// 存储此路由的参数名称列表（字节切片）
 	if len(paramNames) > 0 {
 		n.paramNames = make([][]byte, len(paramNames))
@@ -114,6 +180,30 @@ func (n *RouteNode) setHandlerWithParams(handler HandlerFunc, middlewares []Midd
 	}
 }
 
+// 清除处理器、中间件和参数名称列表
+// 中间件切片不复用底层数组，已取得旧切片的请求和缓存条目不受影响
+func (n *RouteNode) clearHandler() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.handler = nil
+	n.middlewares = nil
+	n.routeMiddlewares = nil
+	n.paramNames = nil
+	n.hitCount.Store(0)
+}
+
+// 以全局中间件和路由自身中间件重新组合生效的中间件链
+// 总是使用新切片，已取得旧切片的请求和缓存条目不受影响
+func (n *RouteNode) composeMiddlewares(global []Middleware) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.handler != nil {
+		n.middlewares = joinMiddlewares(global, n.routeMiddlewares)
+	}
+}
+
 // 获取处理器
 func (n *RouteNode) getHandler() (HandlerFunc, []Middleware, [][]byte) {
 	n.mu.RLock()
@@ -122,11 +212,11 @@ func (n *RouteNode) getHandler() (HandlerFunc, []Middleware, [][]byte) {
 }
 
 // 增加命中计数（原子操作）
-func (n *RouteNode) incrementHitCount() uint32 {
-	return atomic.AddUint32(&n.hitCount, 1)
+func (n *RouteNode) incrementHitCount() uint64 {
+	return n.hitCount.Add(1)
 }
 
 // 获取命中计数
-func (n *RouteNode) getHitCount() uint32 {
-	return atomic.LoadUint32(&n.hitCount)
+func (n *RouteNode) getHitCount() uint64 {
+	return n.hitCount.Load()
 }
@@ -50,7 +50,7 @@ func TestRouteNode_ParamChild(t *testing.T) {
 	node.insertChild(":id", child)
 
 	// 查找参数子节点
-	found := node.findParamChild()
+	found := node.findParamChild(":id")
 	if found == nil {
 		t.Fatal("param child not found")
 	}
@@ -117,6 +117,38 @@ func TestRouteNode_Handler(t *testing.T) {
 	}
 }
 
+// 测试参数兄弟节点按约束优先级排序
+func TestRouteNode_ParamChildrenOrder(t *testing.T) {
+	node := &RouteNode{staticChildren: make(map[string]*RouteNode)}
+	for _, seg := range []string{":name", ":slug<re:[a-z]+>", ":id<int>", ":other", ":uuid<uuid>"} {
+		_, constraint, err := parseParamSeg(seg)
+		if err != nil {
+			t.Fatalf("parseParamSeg(%q) error = %v", seg, err)
+		}
+		node.insertChild(seg, &RouteNode{typ: ParamNode, seg: []byte(seg), constraint: constraint})
+	}
+
+	want := []string{":id<int>", ":uuid<uuid>", ":slug<re:[a-z]+>", ":name", ":other"}
+	children := node.findParamChildren()
+	if len(children) != len(want) {
+		t.Fatalf("children = %d, want %d", len(children), len(want))
+	}
+	for i, child := range children {
+		if string(child.seg) != want[i] {
+			t.Errorf("children[%d] = %s, want %s", i, child.seg, want[i])
+		}
+	}
+
+	node.removeChild(":slug<re:[a-z]+>", children[2])
+	if len(node.findParamChildren()) != 4 || node.findParamChild(":slug<re:[a-z]+>") != nil {
+		t.Error("removed child should not be found")
+	}
+	// 旧的兄弟节点列表不受写时复制影响
+	if string(children[2].seg) != ":slug<re:[a-z]+>" {
+		t.Error("snapshot should not be modified")
+	}
+}
+
 // 测试命中计数
 func TestRouteNode_HitCount(t *testing.T) {
 	node := &RouteNode{
@@ -184,7 +216,7 @@ func TestRouteNode_ConcurrentAccess(t *testing.T) {
 
 	// 验证最终计数
 	finalCount := node.getHitCount()
-	expectedCount := uint32(goroutines * iterations)
+	expectedCount := uint64(goroutines * iterations)
 	if finalCount != expectedCount {
 		t.Errorf("final hit count = %d, want %d", finalCount, expectedCount)
 	}
@@ -234,13 +266,13 @@ func BenchmarkRouteNode_FindParamChild(b *testing.B) {
 	node := &RouteNode{
 		staticChildren: make(map[string]*RouteNode),
 	}
-	paramNode := &RouteNode{typ: ParamNode}
-	node.paramChild.Store(paramNode)
+	paramNode := &RouteNode{typ: ParamNode, seg: []byte(":id")}
+	node.insertChild(":id", paramNode)
 
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		_ = node.findParamChild()
+		_ = node.findParamChildren()
 	}
 }
 
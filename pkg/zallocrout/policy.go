@@ -0,0 +1,137 @@
+package zallocrout
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// 路径修正策略（可组合）
+// 默认不启用任何策略：Match 静默规范化请求路径（忽略末尾斜杠，清理 //、/./、/../），区分大小写
+type PathPolicy uint32
+
+const (
+	// RedirectTrailingSlash 末尾斜杠与注册路径不一致时 Match 不匹配，RedirectPath 返回注册时的形式（通配符路由除外）
+	RedirectTrailingSlash PathPolicy = 1 << iota
+	// RedirectCleanPath 路径包含 //、/./、/../ 时 Match 不匹配，RedirectPath 返回清理后的路径
+	RedirectCleanPath
+	// RedirectCaseInsensitive 静态片段大小写不一致时由 RedirectPath 按不区分大小写查找，返回注册时的大小写
+	RedirectCaseInsensitive
+)
+
+// 设置路径修正策略，同时清空热点缓存
+func (r *Router) SetPathPolicy(policy PathPolicy) {
+	atomic.StoreUint32(&r.pathPolicy, uint32(policy))
+	r.hotCache.Clear()
+}
+
+// 返回当前的路径修正策略
+func (r *Router) PathPolicy() PathPolicy {
+	return PathPolicy(atomic.LoadUint32(&r.pathPolicy))
+}
+
+// 按路径修正策略查找请求路径对应的规范路径，用于 Match 失败后返回重定向
+// 找到路由且规范路径与请求路径不同时返回 true；未启用任何策略时总是返回 false
+func (r *Router) RedirectPath(method, path string) (string, bool) {
+	policy := r.PathPolicy()
+	if policy == 0 {
+		return "", false
+	}
+
+	// 1. 清理路径（保留请求的末尾斜杠，由下面的策略决定）
+	clean := "/"
+	if path != "" {
+		clean = string(normalizePathBytes([]byte(path)))
+	}
+	trailingSlash := hasTrailingSlash(path)
+
+	var segs [MaxParams]string
+	segsSlice := splitPathToCompressedSegs(clean, segs[:0])
+
+	table := r.rlockTable()
+	defer table.mu.RUnlock()
+	root, exists := table.roots[method]
+	if !exists {
+		return "", false
+	}
+
+	// 2. 按清理后的路径查找，不区分大小写时再按规范大小写查找
+	var paramPairs [MaxParams]paramPair
+	node, _ := lookup(root, clean, segsSlice, &paramPairs)
+	if node == nil && policy&RedirectCaseInsensitive != 0 {
+		var canonical []string
+		if node, canonical = lookupCaseInsensitive(root, segsSlice, nil); node != nil {
+			clean = "/" + strings.Join(canonical, "/")
+		}
+	}
+	if node == nil {
+		return "", false
+	}
+
+	// 3. 末尾斜杠
+	if policy&RedirectTrailingSlash != 0 && !node.isWildcard {
+		trailingSlash = node.trailingSlash
+	}
+	if trailingSlash && clean != "/" {
+		clean += "/"
+	}
+	if clean == path {
+		return "", false
+	}
+	return clean, true
+}
+
+// 不区分大小写查找路由（回溯），返回节点和按注册大小写拼写的路径片段
+// 静态片段优先使用大小写完全一致的子节点，参数和通配符片段保留请求中的值
+func lookupCaseInsensitive(n *RouteNode, segs []string, canonical []string) (*RouteNode, []string) {
+	if len(segs) == 0 {
+		if handler, _, _ := n.getHandler(); handler != nil {
+			return n, canonical
+		}
+		return nil, nil
+	}
+
+	seg := segs[0]
+	if child, ok := n.findStaticChild(seg); ok {
+		if found, result := lookupCaseInsensitive(child, segs[1:], append(canonical, seg)); found != nil {
+			return found, result
+		}
+	}
+	for key, child := range n.staticChildren {
+		if key != seg && strings.EqualFold(key, seg) {
+			if found, result := lookupCaseInsensitive(child, segs[1:], append(canonical, key)); found != nil {
+				return found, result
+			}
+		}
+	}
+	for _, child := range n.findParamChildren() {
+		if _, ok := child.constraint.match(seg); !ok {
+			continue
+		}
+		if found, result := lookupCaseInsensitive(child, segs[1:], append(canonical, seg)); found != nil {
+			return found, result
+		}
+	}
+	if child := n.findWildcardChild(); child != nil {
+		if handler, _, _ := child.getHandler(); handler != nil {
+			return child, append(canonical, segs...)
+		}
+	}
+	return nil, nil
+}
+
+// 是否以 '/' 结尾（根路径除外）
+//
+//go:inline
+func hasTrailingSlash(path string) bool {
+	return len(path) > 1 && path[len(path)-1] == '/'
+}
+
+// 请求路径是否需要清理（//、/./、/../ 或缺少开头的 '/'），normalized 为静默规范化后的路径
+//
+//go:inline
+func needsCleaning(path, normalized string) bool {
+	if hasTrailingSlash(path) {
+		path = path[:len(path)-1]
+	}
+	return path != normalized
+}
@@ -0,0 +1,167 @@
+package zallocrout
+
+import (
+	"context"
+	"testing"
+)
+
+func newPolicyTestRouter(t *testing.T) *Router {
+	t.Helper()
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	for _, path := range []string{"/", "/users/:id<int>", "/Docs/", "/files/*path", "/API/v1/Status"} {
+		if err := router.AddRoute("GET", path, handler); err != nil {
+			t.Fatalf("AddRoute(%s) error = %v", path, err)
+		}
+	}
+	return router
+}
+
+// 测试默认策略：静默规范化，行为不变
+func TestRouter_PathPolicyDefault(t *testing.T) {
+	router := newPolicyTestRouter(t)
+	for _, path := range []string{"/users/1/", "/users//1", "/users/./1", "/Docs", "/Docs/"} {
+		ctx, _, _, ok := router.Match("GET", path, context.Background())
+		if !ok {
+			t.Errorf("Match(%s) should succeed without path policy", path)
+			continue
+		}
+		ReleaseContext(ctx)
+	}
+	if _, ok := router.RedirectPath("GET", "/users/1/"); ok {
+		t.Error("RedirectPath should be disabled without path policy")
+	}
+}
+
+// 测试末尾斜杠策略
+func TestRouter_RedirectTrailingSlash(t *testing.T) {
+	router := newPolicyTestRouter(t)
+	router.SetPathPolicy(RedirectTrailingSlash)
+	if router.PathPolicy() != RedirectTrailingSlash {
+		t.Fatalf("PathPolicy() = %v", router.PathPolicy())
+	}
+
+	tests := []struct {
+		path     string
+		matched  bool
+		redirect string
+	}{
+		{"/users/1", true, ""},
+		{"/users/1/", false, "/users/1"},
+		{"/Docs/", true, ""},
+		{"/Docs", false, "/Docs/"},
+		{"/", true, ""},
+		{"/files/a/b/", true, ""},         // 通配符路由不检查末尾斜杠
+		{"/users/x/", false, ""},          // 路由不存在
+		{"/users//1/", false, "/users/1"}, // 未启用 RedirectCleanPath 时 // 静默规范化，只修正末尾斜杠
+	}
+
+	for _, tt := range tests {
+		// 多次匹配，确保热点缓存不会绕过策略
+		for i := 0; i < hotCacheThreshold+2; i++ {
+			ctx, _, _, ok := router.Match("GET", tt.path, context.Background())
+			if ok != tt.matched {
+				t.Fatalf("Match(%s) ok = %v, want %v", tt.path, ok, tt.matched)
+			}
+			if ok {
+				ReleaseContext(ctx)
+			}
+		}
+		got, ok := router.RedirectPath("GET", tt.path)
+		if ok != (tt.redirect != "") || got != tt.redirect {
+			t.Errorf("RedirectPath(%s) = %q, %v, want %q", tt.path, got, ok, tt.redirect)
+		}
+	}
+}
+
+// 测试清理路径策略
+func TestRouter_RedirectCleanPath(t *testing.T) {
+	router := newPolicyTestRouter(t)
+	router.SetPathPolicy(RedirectCleanPath)
+
+	tests := []struct {
+		path     string
+		matched  bool
+		redirect string
+	}{
+		{"/users/1", true, ""},
+		{"/users/1/", true, ""}, // 未启用末尾斜杠策略
+		{"/users//1", false, "/users/1"},
+		{"/users/./1", false, "/users/1"},
+		{"/users/2/../1", false, "/users/1"},
+		{"/users//1/", false, "/users/1/"}, // 保留请求的末尾斜杠
+		{"users/1", false, "/users/1"},
+		{"/.well-known/../users/1", false, "/users/1"},
+		{"//Docs", false, "/Docs"},
+	}
+	for _, tt := range tests {
+		ctx, _, _, ok := router.Match("GET", tt.path, context.Background())
+		if ok != tt.matched {
+			t.Errorf("Match(%s) ok = %v, want %v", tt.path, ok, tt.matched)
+		}
+		if ok {
+			ReleaseContext(ctx)
+		}
+		got, ok := router.RedirectPath("GET", tt.path)
+		if ok != (tt.redirect != "") || got != tt.redirect {
+			t.Errorf("RedirectPath(%s) = %q, %v, want %q", tt.path, got, ok, tt.redirect)
+		}
+	}
+}
+
+// 测试不区分大小写查找
+func TestRouter_RedirectCaseInsensitive(t *testing.T) {
+	router := newPolicyTestRouter(t)
+	router.SetPathPolicy(RedirectCaseInsensitive | RedirectTrailingSlash | RedirectCleanPath)
+
+	tests := []struct {
+		path     string
+		redirect string
+	}{
+		{"/api/v1/status", "/API/v1/Status"},
+		{"/Api//V1/STATUS/", "/API/v1/Status"},
+		{"/USERS/42", "/users/42"},
+		{"/docs", "/Docs/"},
+		{"/FILES/A/b", "/files/A/b"}, // 通配符保留请求中的值
+		{"/users/abc", ""},           // 不满足约束
+		{"/API/v1/Status", ""},       // 已是规范路径
+		{"/nothing", ""},
+	}
+	for _, tt := range tests {
+		got, ok := router.RedirectPath("GET", tt.path)
+		if ok != (tt.redirect != "") || got != tt.redirect {
+			t.Errorf("RedirectPath(%s) = %q, %v, want %q", tt.path, got, ok, tt.redirect)
+		}
+	}
+	if _, ok := router.RedirectPath("POST", "/api/v1/status"); ok {
+		t.Error("RedirectPath should fail for unregistered method")
+	}
+
+	// 大小写完全一致的静态子节点优先
+	handler := func(ctx context.Context) error { return nil }
+	if err := router.AddRoute("GET", "/api/v1/status", handler); err != nil {
+		t.Fatalf("AddRoute error = %v", err)
+	}
+	if got, _ := router.RedirectPath("GET", "/api/V1/status"); got != "/api/v1/status" {
+		t.Errorf("RedirectPath = %q, want /api/v1/status", got)
+	}
+}
+
+// 测试末尾斜杠随路由信息和反向生成的 URL 保留
+func TestRouter_TrailingSlashRouteInfo(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	if err := router.AddNamedRoute("docs", "GET", "/docs/:section/", handler); err != nil {
+		t.Fatalf("AddNamedRoute error = %v", err)
+	}
+	if got, err := router.URL("docs", "section", "intro"); err != nil || got != "/docs/intro/" {
+		t.Errorf("URL = %q, %v, want /docs/intro/", got, err)
+	}
+	routes := router.Routes()
+	if len(routes) != 1 || routes[0].Path != "/docs/:section/" || routes[0].Name != "docs" {
+		t.Errorf("Routes() = %+v", routes)
+	}
+	if err := router.AddRoute("GET", "/docs/:section", handler); err == nil {
+		t.Error("routes differing only by trailing slash should conflict")
+	}
+}
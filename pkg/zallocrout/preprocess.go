@@ -180,16 +180,10 @@ func validateRoute(path string) error {
 		}
 
 		// 参数片段检查
+		// 参数名只能包含字母、数字、下划线，约束必须合法（如 :id<int>、:slug<re:[a-z-]+>）
 		if seg[0] == ':' {
-			if len(seg) == 1 {
-				return errors.New("param segment cannot be empty")
-			}
-			// 参数名只能包含字母、数字、下划线
-			for _, c := range seg[1:] {
-				if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
-					(c >= '0' && c <= '9') || c == '_') {
-					return errors.New("param name can only contain letters, numbers, and underscores")
-				}
+			if _, _, err := parseParamSeg(seg); err != nil {
+				return err
 			}
 		}
 
@@ -190,6 +190,16 @@ func TestValidateRoute(t *testing.T) {
 		{"参数名包含特殊字符", "/users/:id@name", true},
 		{"合法参数名", "/users/:user_id", false},
 		{"合法参数名2", "/users/:userId123", false},
+		{"整数约束", "/users/:id<int>", false},
+		{"UUID约束", "/orders/:uuid<uuid>/items", false},
+		{"正则约束", "/posts/:slug<re:[a-z-]+>", false},
+		{"未知约束", "/users/:id<float>", true},
+		{"约束未闭合", "/users/:id<int", true},
+		{"尖括号静态片段", "/users/<int>", false},
+		{"空参数名", "/users/:<int>", true},
+		{"非法正则", "/posts/:slug<re:[a-z>", true},
+		{"空正则", "/posts/:slug<re:>", true},
+		{"正则包含斜杠", "/posts/:slug<re:a/b>", true},
 	}
 
 	for _, tt := range tests {
@@ -47,15 +47,20 @@ func (rm *resourceManager) releaseNode(n *RouteNode) {
 	}
 
 	// 重置节点状态
+	// 切片字段置空而不是截断：节点移除后仍可能被进行中的请求和缓存条目引用，复用底层数组会改写其内容
 	n.typ = StaticCompressed
-	n.seg = n.seg[:0]
+	n.seg = nil
 	n.handler = nil
-	n.middlewares = n.middlewares[:0]
-	n.paramChild.Store(nil)
+	n.middlewares = nil
+	n.routeMiddlewares = nil
+	n.paramChildren.Store(nil)
 	n.wildcardChild.Store(nil)
-	n.paramName = n.paramName[:0]
+	n.paramName = nil
+	n.constraint = nil
+	n.paramNames = nil
 	n.isWildcard = false
-	n.hitCount = 0
+	n.trailingSlash = false
+	n.hitCount.Store(0)
 
 	// 清理静态子节点（保留底层数组）
 	for k := range n.staticChildren {
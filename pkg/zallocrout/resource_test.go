@@ -21,7 +21,7 @@ func TestResourceManager_NodePool(t *testing.T) {
 	// 修改节点状态
 	n1.typ = ParamNode
 	n1.seg = []byte("test")
-	n1.hitCount = 100
+	n1.hitCount.Store(100)
 
 	// 释放节点
 	rm.releaseNode(n1)
@@ -34,8 +34,8 @@ func TestResourceManager_NodePool(t *testing.T) {
 	if len(n2.seg) != 0 {
 		t.Errorf("node seg not reset, got %v", n2.seg)
 	}
-	if n2.hitCount != 0 {
-		t.Errorf("node hitCount not reset, got %v", n2.hitCount)
+	if n2.getHitCount() != 0 {
+		t.Errorf("node hitCount not reset, got %v", n2.getHitCount())
 	}
 
 	rm.releaseNode(n2)
@@ -2,6 +2,9 @@ package zallocrout
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 )
@@ -9,31 +12,74 @@ import (
 // 热点缓存阈值（命中次数超过此值才缓存）
 const hotCacheThreshold = 100
 
+// 路由表（HTTP 方法分层的 Trie 树），Swap 时整体替换
+// 匹配时持有读锁，增删路由时持有写锁，保证移除的节点归还节点池时没有正在进行的匹配
+type routeTable struct {
+	mu    sync.RWMutex
+	roots map[string]*RouteNode  // HTTP 方法分层（GET/POST 等）
+	names map[string]*namedRoute // 命名路由（随路由表一起替换）
+}
+
+// 创建空路由表
+func newRouteTable() *routeTable {
+	return &routeTable{
+		roots: make(map[string]*RouteNode, 8),
+		names: make(map[string]*namedRoute),
+	}
+}
+
 // 路由器核心结构
 type Router struct {
-	roots            map[string]*RouteNode  // HTTP 方法分层（GET/POST 等）
-	rootsMu          sync.RWMutex           // 根节点读写锁
-	resourceMgr      *resourceManager       // 资源管理器
-	hotCache         *shardedMap            // 分片热点缓存
-	metrics          *RouterMetrics         // 性能指标
-	metricsCollector *asyncMetricsCollector // 异步 metrics 收集器
-	enableHotCache   uint32                 // 是否启用热点缓存（原子操作）
+	table            atomic.Pointer[routeTable] // 当前路由表（原子替换）
+	resourceMgr      *resourceManager           // 资源管理器
+	hotCache         *shardedMap                // 分片热点缓存
+	metrics          *RouterMetrics             // 性能指标
+	metricsCollector *asyncMetricsCollector     // 异步 metrics 收集器
+	enableHotCache   uint32                     // 是否启用热点缓存（原子操作）
+	middlewares      []Middleware               // 全局中间件（Use 注册，持有路由表写锁时读写）
+	pathPolicy       uint32                     // 路径修正策略（PathPolicy，原子操作）
 }
 
 // 创建新的路由器
 func NewRouter() *Router {
 	metrics := &RouterMetrics{}
 	r := &Router{
-		roots:            make(map[string]*RouteNode, 8),
 		resourceMgr:      globalResourceManager,
 		hotCache:         newShardedMap(),
 		metrics:          metrics,
 		metricsCollector: newAsyncMetricsCollector(metrics),
 		enableHotCache:   1, // 默认启用
 	}
+	r.table.Store(newRouteTable())
 	return r
 }
 
+// 获取当前路由表并加写锁（路由表已被 Swap 替换时重新获取）
+func (r *Router) lockTable() *routeTable {
+	for {
+		t := r.table.Load()
+		t.mu.Lock()
+		if r.table.Load() == t {
+			return t
+		}
+		t.mu.Unlock()
+	}
+}
+
+// 获取当前路由表并加读锁（路由表已被 Swap 替换时重新获取）
+//
+//go:inline
+func (r *Router) rlockTable() *routeTable {
+	for {
+		t := r.table.Load()
+		t.mu.RLock()
+		if r.table.Load() == t {
+			return t
+		}
+		t.mu.RUnlock()
+	}
+}
+
 // 添加路由
 // method: HTTP 方法（GET/POST/PUT/DELETE 等）
 // path: 路由路径（如 /api/v1/users/:id）
@@ -42,30 +88,51 @@ func NewRouter() *Router {
 //
 //go:inline
 func (r *Router) AddRoute(method, path string, handler HandlerFunc, middlewares ...Middleware) error {
+	return r.addRoute("", method, path, handler, middlewares)
+}
+
+// 添加命名路由，可通过 URL 按名称生成路径
+// name: 路由名称（路由器内唯一）
+func (r *Router) AddNamedRoute(name, method, path string, handler HandlerFunc, middlewares ...Middleware) error {
+	if name == "" {
+		return errors.New("route name cannot be empty")
+	}
+	return r.addRoute(name, method, path, handler, middlewares)
+}
+
+// 添加路由，name 为空时不登记名称
+func (r *Router) addRoute(name, method, path string, handler HandlerFunc, middlewares []Middleware) error {
 	// 1. 路由预检查
 	if err := validateRoute(path); err != nil {
 		return err
 	}
 
 	// 2. 获取/创建方法根节点
-	r.rootsMu.Lock()
-	root, exists := r.roots[method]
-	if !exists {
-		root = r.resourceMgr.acquireNode()
-		r.roots[method] = root
+	table := r.lockTable()
+	defer table.mu.Unlock()
+	if _, exists := table.names[name]; exists && name != "" {
+		return errors.New("route name already exists: " + name)
 	}
-	r.rootsMu.Unlock()
 
 	// 3. 路径拆分（栈分配优先）
 	var segs [MaxParams]string
 	segsSlice := splitPathToCompressedSegs(path, segs[:0])
 
+	root, exists := table.roots[method]
+	if !exists {
+		root = r.resourceMgr.acquireNode()
+		table.roots[method] = root
+	} else if existing, ok := findConflict(root, segsSlice, ""); ok {
+		// 重复注册或被已有路由遮蔽
+		return fmt.Errorf("%w: %s %s conflicts with existing route %s %s", ErrRouteConflict, method, path, method, existing)
+	}
+
 	// 4. 收集参数名称（使用字节切片避免转换开销）
 	var paramNames [][]byte
 	for _, seg := range segsSlice {
 		if isParamSeg(seg) {
-			// 去掉 ':' 前缀，直接存储字节切片
-			paramNames = append(paramNames, []byte(seg[1:]))
+			// 去掉 ':' 前缀和约束，直接存储字节切片
+			paramNames = append(paramNames, []byte(paramSegName(seg)))
 		}
 	}
 
@@ -92,29 +159,20 @@ func (r *Router) AddRoute(method, path string, handler HandlerFunc, middlewares
 			continue
 		}
 
-		// 参数路由
+		// 参数路由：参数名和约束都相同的片段共用节点，否则作为兄弟节点按优先级插入
 		if isParamSeg(seg) {
-			// 使用原子指针检查和插入
-			if child := current.paramChild.Load(); child != nil {
-				current = child
-				continue
-			}
-
-			// 加锁创建新的参数节点
-			current.mu.Lock()
-			// 双重检查
-			if child := current.paramChild.Load(); child != nil {
-				current.mu.Unlock()
+			if child := current.findParamChild(seg); child != nil {
 				current = child
 				continue
 			}
 
+			name, constraint, _ := parseParamSeg(seg) // 已通过 validateRoute 检查
 			child := r.resourceMgr.acquireNode()
 			child.typ = ParamNode
 			child.seg = []byte(seg)
-			child.paramName = []byte(seg[1:]) // 去掉 ':'
-			current.paramChild.Store(child)
-			current.mu.Unlock()
+			child.paramName = []byte(name)
+			child.constraint = constraint
+			current.insertChild(seg, child)
 			current = child
 			continue
 		}
@@ -149,6 +207,10 @@ func (r *Router) AddRoute(method, path string, handler HandlerFunc, middlewares
 
 	// 6. 绑定处理器和中间件，并存储参数名称列表
 	current.setHandlerWithParams(handler, middlewares, paramNames)
+	current.trailingSlash = hasTrailingSlash(path)
+	if len(r.middlewares) > 0 {
+		current.composeMiddlewares(r.middlewares)
+	}
 
 	// 7. 更新指标
 	switch current.typ {
@@ -160,9 +222,194 @@ func (r *Router) AddRoute(method, path string, handler HandlerFunc, middlewares
 		r.metrics.IncrementWildcardRoutes()
 	}
 
+	// 8. 新路由可能覆盖已缓存的参数匹配结果（如新增 /users/new 覆盖 /users/:id），使其失效
+	r.hotCache.DeleteFunc(func(key cacheKey, entry *cacheEntry) bool {
+		return key.method == method && entry.paramCount > 0
+	})
+
+	// 9. 登记路由名称
+	if name != "" {
+		table.names[name] = newNamedRoute(method, segsSlice, current.trailingSlash)
+	}
+
 	return nil
 }
 
+// 移除路由
+// 路径需与注册时一致（参数名相同），移除后没有处理器和子节点的 Trie 节点被剪除并归还节点池，
+// 热点缓存中指向该路由的条目同时失效
+func (r *Router) RemoveRoute(method, path string) error {
+	if err := validateRoute(path); err != nil {
+		return err
+	}
+
+	table := r.lockTable()
+	defer table.mu.Unlock()
+
+	root, exists := table.roots[method]
+	if !exists {
+		return ErrRouteNotFound
+	}
+
+	// 1. 沿注册路径定位节点，记录经过的节点用于剪枝
+	var segs [MaxParams]string
+	segsSlice := splitPathToCompressedSegs(path, segs[:0])
+	var paramNames [][]byte
+	nodes := make([]*RouteNode, 0, len(segsSlice)+1)
+	nodes = append(nodes, root)
+	current := root
+	for _, seg := range segsSlice {
+		var child *RouteNode
+		switch {
+		case isStaticSeg(seg):
+			child = current.staticChildren[seg]
+		case isParamSeg(seg):
+			child = current.findParamChild(seg)
+			paramNames = append(paramNames, []byte(paramSegName(seg)))
+		case isWildcardSeg(seg):
+			child = current.wildcardChild.Load()
+		}
+		if child == nil {
+			return ErrRouteNotFound
+		}
+		nodes = append(nodes, child)
+		current = child
+	}
+
+	handler, _, routeParamNames := current.getHandler()
+	if handler == nil || !equalParamNames(routeParamNames, paramNames) {
+		return ErrRouteNotFound
+	}
+
+	// 2. 解绑处理器并使缓存失效，同时注销指向该路由的名称
+	current.clearHandler()
+	table.removeNames(method, segsSlice)
+	r.hotCache.DeleteFunc(func(key cacheKey, entry *cacheEntry) bool {
+		if entry.node != current {
+			return false
+		}
+		entry.removed.Store(true) // 已取出条目的匹配不再为该节点计数
+		return true
+	})
+	switch current.typ {
+	case StaticCompressed:
+		r.metrics.DecrementStaticRoutes()
+	case ParamNode:
+		r.metrics.DecrementParamRoutes()
+	case WildcardNode:
+		r.metrics.DecrementWildcardRoutes()
+	}
+
+	// 3. 自底向上剪除空节点
+	for i := len(nodes) - 1; i > 0; i-- {
+		node := nodes[i]
+		if !node.isEmpty() {
+			return nil
+		}
+		nodes[i-1].removeChild(unsafeString(node.seg), node)
+		r.resourceMgr.releaseNode(node)
+	}
+	if root.isEmpty() {
+		delete(table.roots, method)
+		r.resourceMgr.releaseNode(root)
+	}
+	return nil
+}
+
+// 以 newRouter 的路由表原子替换当前路由表
+// 进行中的 Match 要么使用旧表要么使用新表；旧表在进行中的匹配结束后归还节点池，热点缓存随之失效。
+// newRouter 的路由表（含路由数量指标）转移给当前路由器，newRouter 变为空路由器
+// 转移后的路由使用当前路由器的全局中间件，newRouter 通过 Use 注册的全局中间件不随之转移
+func (r *Router) Swap(newRouter *Router) {
+	if newRouter == nil || newRouter == r {
+		return
+	}
+
+	// 1. 从 newRouter 取出路由表
+	next := newRouter.lockTable()
+	newRouter.table.Store(newRouteTable())
+	next.mu.Unlock()
+	newRouter.hotCache.Clear()
+	static, param, wildcard := newRouter.metrics.takeRouteCounts()
+
+	// 2. 替换路由表，写锁保证没有正在使用旧表的匹配；新表的路由使用当前路由器的全局中间件
+	old := r.lockTable()
+	for _, root := range next.roots {
+		composeTree(root, r.middlewares)
+	}
+	r.table.Store(next)
+	r.metrics.setRouteCounts(static, param, wildcard)
+	for _, root := range old.roots {
+		r.releaseTree(root)
+	}
+	old.roots = nil
+	old.mu.Unlock()
+
+	// 3. 旧表的缓存条目已不可命中，清空释放内存
+	r.hotCache.Clear()
+}
+
+// 注册全局中间件，作用于所有路由（包括已注册的路由），执行顺序在路由和分组中间件之前
+// 中间件链在注册时组合，匹配和执行时不产生额外分配
+func (r *Router) Use(middlewares ...Middleware) {
+	if len(middlewares) == 0 {
+		return
+	}
+
+	table := r.lockTable()
+	defer table.mu.Unlock()
+	r.middlewares = joinMiddlewares(r.middlewares, middlewares)
+	for _, root := range table.roots {
+		composeTree(root, r.middlewares)
+	}
+	// 缓存条目保存的是旧中间件链
+	r.hotCache.Clear()
+}
+
+// 递归重新组合子树所有路由的中间件链
+func composeTree(n *RouteNode, global []Middleware) {
+	n.composeMiddlewares(global)
+	for _, child := range n.staticChildren {
+		composeTree(child, global)
+	}
+	for _, child := range n.findParamChildren() {
+		composeTree(child, global)
+	}
+	if child := n.wildcardChild.Load(); child != nil {
+		composeTree(child, global)
+	}
+}
+
+// 递归归还子树的所有节点
+func (r *Router) releaseTree(n *RouteNode) {
+	for _, child := range n.staticChildren {
+		r.releaseTree(child)
+	}
+	for _, child := range n.findParamChildren() {
+		r.releaseTree(child)
+	}
+	if child := n.wildcardChild.Load(); child != nil {
+		r.releaseTree(child)
+	}
+	r.resourceMgr.releaseNode(n)
+}
+
+// ErrRouteNotFound 要移除的路由不存在（方法或路径未注册，或参数名、约束与注册时不一致）
+var ErrRouteNotFound = errors.New("route not found")
+
+// 比较参数名称列表
+func equalParamNames(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if string(a[i]) != string(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // 匹配路由
 // method: HTTP 方法
 // path: 请求路径
@@ -170,11 +417,12 @@ func (r *Router) AddRoute(method, path string, handler HandlerFunc, middlewares
 //
 //go:inline
 func (r *Router) Match(method, path string, parent context.Context) (context.Context, HandlerFunc, []Middleware, bool) {
-	// 1. 热点缓存检查（如果启用）
+	// 1. 热点缓存检查（如果启用），只命中当前路由表写入的条目
 	if atomic.LoadUint32(&r.enableHotCache) == 1 {
 		// 直接使用 method 和 path 计算哈希，避免字符串拼接
-		if cacheVal, ok := r.hotCache.LoadWithMethodPath(method, path); ok {
+		if cacheVal, ok := r.hotCache.LoadWithMethodPath(method, path); ok && cacheVal.table == r.table.Load() {
 			r.metricsCollector.incrementCacheHits()
+			r.countCachedHit(cacheVal)
 
 			// 直接使用缓存的参数数组，避免 map 迭代
 			ctx := acquireContext(parent, &cacheVal.paramPairs, cacheVal.paramCount)
@@ -193,67 +441,41 @@ func (r *Router) Match(method, path string, parent context.Context) (context.Con
 		}
 		normalizedPath = unsafeString(pathBytes)
 	}
+	policy := PathPolicy(atomic.LoadUint32(&r.pathPolicy))
+	if policy&RedirectCleanPath != 0 && normalizedPath != path && needsCleaning(path, normalizedPath) {
+		// 需要清理的路径由 RedirectPath 重定向
+		return nil, nil, nil, false
+	}
 
 	// 3. 路径拆分（栈分配优先）
 	var segs [MaxParams]string
 	segsSlice := splitPathToCompressedSegs(normalizedPath, segs[:0])
 
-	// 4. 快速定位方法根节点
-	r.rootsMu.RLock()
-	root, exists := r.roots[method]
-	r.rootsMu.RUnlock()
-
+	// 4. 快速定位方法根节点（匹配期间持有路由表读锁）
+	table := r.rlockTable()
+	root, exists := table.roots[method]
 	if !exists {
+		table.mu.RUnlock()
 		return nil, nil, nil, false
 	}
 
 	// 5. 核心匹配流程（零分配参数存储）
-	current := root
 	var paramPairs [MaxParams]paramPair
-	paramCount := 0
-	pathPos := 1 // 跳过开头的 '/'
-
-	for _, seg := range segsSlice {
-		// 静态路由：无锁 O(1) 匹配
-		if child, ok := current.findStaticChild(seg); ok {
-			current = child
-			pathPos += len(seg) + 1 // +1 for '/'
-			continue
-		}
-
-		// 参数节点匹配
-		if paramChild := current.findParamChild(); paramChild != nil {
-			if paramCount < MaxParams {
-				paramPairs[paramCount] = paramPair{
-					key:   unsafeString(paramChild.paramName),
-					value: seg,
-				}
-				paramCount++
-			}
-			current = paramChild
-			pathPos += len(seg) + 1
-			continue
-		}
-
-		// 通配符节点匹配
-		if wildcardChild := current.findWildcardChild(); wildcardChild != nil {
-			// 直接使用原始路径的剩余部分（零分配）
-			remaining := normalizedPath[pathPos:]
-			if paramCount < MaxParams {
-				paramPairs[paramCount] = paramPair{key: "*", value: remaining}
-				paramCount++
-			}
-			current = wildcardChild
-			break
-		}
-
-		// 未找到匹配
+	current, paramCount := lookup(root, normalizedPath, segsSlice, &paramPairs)
+	if current == nil {
+		table.mu.RUnlock()
 		return nil, nil, nil, false
 	}
 
 	// 6. 检查处理器是否存在
 	handler, middlewares, routeParamNames := current.getHandler()
 	if handler == nil {
+		table.mu.RUnlock()
+		return nil, nil, nil, false
+	}
+	if policy&RedirectTrailingSlash != 0 && !current.isWildcard && hasTrailingSlash(path) != current.trailingSlash {
+		// 末尾斜杠与注册路径不一致，由 RedirectPath 重定向
+		table.mu.RUnlock()
 		return nil, nil, nil, false
 	}
 
@@ -269,35 +491,145 @@ func (r *Router) Match(method, path string, parent context.Context) (context.Con
 		}
 	}
 
-	// 9. 热点缓存更新（原子操作 + 分片 Map）
-	if atomic.LoadUint32(&r.enableHotCache) == 1 && !current.isWildcard {
-		hitCount := current.incrementHitCount()
-		if hitCount > hotCacheThreshold {
-			// 直接存储参数数组，避免 map 分配和迭代
-			cacheData := &cacheEntry{
-				handler:     handler,
-				middlewares: middlewares,
-				paramPairs:  paramPairs,
-				paramCount:  paramCount,
-			}
-			r.hotCache.StoreWithMethodPath(method, path, cacheData)
+	// 9. 记录路由命中次数，热点缓存更新（原子操作 + 分片 Map）
+	hitCount := current.incrementHitCount()
+	if hitCount > hotCacheThreshold && atomic.LoadUint32(&r.enableHotCache) == 1 && !current.isWildcard {
+		// 直接存储参数数组，避免 map 分配和迭代
+		cacheData := &cacheEntry{
+			handler:     handler,
+			middlewares: middlewares,
+			paramPairs:  paramPairs,
+			paramCount:  paramCount,
+			table:       table,
+			node:        current,
 		}
+		r.hotCache.StoreWithMethodPath(method, path, cacheData)
 	}
+	table.mu.RUnlock()
 
 	// 10. 从池中获取 context 并返回（使用指针避免数组拷贝）
 	ctx := acquireContext(parent, &paramPairs, paramCount)
 	return ctx, handler, middlewares, true
 }
 
+// 记录热点缓存命中的路由命中次数
+// 在条目所属路由表的读锁下确认路由未被删除或替换，避免为已归还节点池（可能已被其他路由复用）的节点计数
+func (r *Router) countCachedHit(entry *cacheEntry) {
+	entry.table.mu.RLock()
+	if !entry.removed.Load() && r.table.Load() == entry.table {
+		entry.node.incrementHitCount()
+	}
+	entry.table.mu.RUnlock()
+}
+
+// 在 Trie 树中查找路径对应的有处理器的节点，参数写入 paramPairs
+// 每层按 静态 > 参数（按约束优先级）> 通配符 的顺序尝试，后续片段匹配失败时回溯尝试下一候选
+// 返回节点和参数数量，未找到时节点为 nil；调用方需持有路由表读锁
+//
+//go:inline
+func lookup(root *RouteNode, path string, segs []string, paramPairs *[MaxParams]paramPair) (*RouteNode, int) {
+	return matchNode(root, path, segs, 1, paramPairs, 0) // pathPos 跳过开头的 '/'
+}
+
+// 从节点 n 开始匹配剩余片段 segs，pathPos 为 segs[0] 在 path 中的起始位置
+func matchNode(n *RouteNode, path string, segs []string, pathPos int, paramPairs *[MaxParams]paramPair, paramCount int) (*RouteNode, int) {
+	if len(segs) == 0 {
+		if handler, _, _ := n.getHandler(); handler != nil {
+			return n, paramCount
+		}
+		return nil, 0
+	}
+	seg := segs[0]
+	next := pathPos + len(seg) + 1 // +1 for '/'
+
+	// 静态路由：无锁 O(1) 匹配
+	if child, ok := n.findStaticChild(seg); ok {
+		if node, count := matchNode(child, path, segs[1:], next, paramPairs, paramCount); node != nil {
+			return node, count
+		}
+	}
+
+	// 参数节点匹配：按优先级检查约束，无锁读取兄弟节点列表
+	for _, child := range n.findParamChildren() {
+		num, ok := child.constraint.match(seg)
+		if !ok {
+			continue
+		}
+		count := paramCount
+		if count < MaxParams {
+			paramPairs[count] = paramPair{
+				key:   unsafeString(child.paramName),
+				value: seg,
+				num:   num,
+				isNum: child.constraint != nil && child.constraint.kind == constraintInt,
+			}
+			count++
+		}
+		if node, count := matchNode(child, path, segs[1:], next, paramPairs, count); node != nil {
+			return node, count
+		}
+	}
+
+	// 通配符节点匹配
+	if wildcardChild := n.findWildcardChild(); wildcardChild != nil {
+		if handler, _, _ := wildcardChild.getHandler(); handler != nil {
+			// 直接使用原始路径的剩余部分（零分配）
+			if paramCount < MaxParams {
+				paramPairs[paramCount] = paramPair{key: "*", value: path[pathPos:]}
+				paramCount++
+			}
+			return wildcardChild, paramCount
+		}
+	}
+
+	// 未找到匹配
+	return nil, 0
+}
+
+// 返回路径在哪些方法下存在路由（按字母排序），用于生成 405 响应和 OPTIONS 的 Allow 头
+// 不记录指标、不更新热点缓存
+func (r *Router) AllowedMethods(path string) []string {
+	normalizedPath := path
+	if needsNormalization(path) {
+		pathBytes := normalizePathBytes([]byte(path))
+		if len(pathBytes) == 0 {
+			return nil
+		}
+		normalizedPath = unsafeString(pathBytes)
+	}
+
+	var segs [MaxParams]string
+	segsSlice := splitPathToCompressedSegs(normalizedPath, segs[:0])
+
+	var methods []string
+	var paramPairs [MaxParams]paramPair
+	table := r.rlockTable()
+	for method, root := range table.roots {
+		node, _ := lookup(root, normalizedPath, segsSlice, &paramPairs)
+		if node == nil {
+			continue
+		}
+		if handler, _, _ := node.getHandler(); handler != nil {
+			methods = append(methods, method)
+		}
+	}
+	table.mu.RUnlock()
+	sort.Strings(methods)
+	return methods
+}
+
 // 获取性能指标（自动刷新本地计数器）
 func (r *Router) Metrics() RouterMetrics {
 	r.metricsCollector.flush()
-	return r.metrics.Snapshot()
+	m := r.metrics.Snapshot()
+	m.RouteHits = r.routeHits()
+	return m
 }
 
-// 重置性能指标
+// 重置性能指标（包括各路由的命中次数）
 func (r *Router) ResetMetrics() {
 	r.metrics.Reset()
+	r.resetRouteHits()
 }
 
 // 获取缓存命中率（自动刷新本地计数器）
@@ -2,7 +2,9 @@ package zallocrout
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -14,8 +16,8 @@ func TestNewRouter(t *testing.T) {
 	if router == nil {
 		t.Fatal("NewRouter returned nil")
 	}
-	if router.roots == nil {
-		t.Error("router.roots is nil")
+	if router.table.Load() == nil {
+		t.Error("router.table is nil")
 	}
 	if router.hotCache == nil {
 		t.Error("router.hotCache is nil")
@@ -864,3 +866,419 @@ func TestRouter_MetricsManagement(t *testing.T) {
 		t.Error("TotalMatches should be > 0 after flush")
 	}
 }
+
+// 测试移除路由
+func TestRouter_RemoveRoute(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+
+	_ = router.AddRoute("GET", "/api/users", handler)
+	_ = router.AddRoute("GET", "/api/users/:id", handler)
+	_ = router.AddRoute("GET", "/api/users/:id/posts/*path", handler)
+	_ = router.AddRoute("POST", "/api/users", handler)
+
+	tests := []struct {
+		method  string
+		path    string
+		wantErr error
+	}{
+		{"GET", "/api/users/:name", ErrRouteNotFound}, // 参数名不一致
+		{"GET", "/api/posts", ErrRouteNotFound},       // 路由不存在
+		{"GET", "/api", ErrRouteNotFound},             // 中间节点没有处理器
+		{"PUT", "/api/users", ErrRouteNotFound},       // 方法不存在
+		{"GET", "/api/users/:id", nil},
+		{"GET", "/api/users/:id", ErrRouteNotFound}, // 重复移除
+	}
+	for _, tt := range tests {
+		err := router.RemoveRoute(tt.method, tt.path)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("RemoveRoute(%s %s) error = %v, want %v", tt.method, tt.path, err, tt.wantErr)
+		}
+	}
+
+	// 子路由和其他方法不受影响
+	for _, c := range []struct{ method, path string }{
+		{"GET", "/api/users"},
+		{"GET", "/api/users/1/posts/a/b"},
+		{"POST", "/api/users"},
+	} {
+		ctx, _, _, ok := router.Match(c.method, c.path, context.Background())
+		if !ok {
+			t.Errorf("%s %s should still match", c.method, c.path)
+			continue
+		}
+		ReleaseContext(ctx)
+	}
+	if _, _, _, ok := router.Match("GET", "/api/users/1", context.Background()); ok {
+		t.Error("removed route should not match")
+	}
+
+	metrics := router.Metrics()
+	if metrics.StaticRoutes != 2 || metrics.ParamRoutes != 0 || metrics.WildcardRoutes != 1 {
+		t.Errorf("route counts = %+v", metrics)
+	}
+}
+
+// 测试移除路由时剪除空节点
+func TestRouter_RemoveRoute_Prune(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+
+	_ = router.AddRoute("GET", "/a/b/c/:id", handler)
+	_ = router.AddRoute("GET", "/a/x", handler)
+
+	if err := router.RemoveRoute("GET", "/a/b/c/:id"); err != nil {
+		t.Fatalf("RemoveRoute error = %v", err)
+	}
+	a := router.table.Load().roots["GET"].staticChildren["a"]
+	if a == nil {
+		t.Fatal("node /a should remain (has child /a/x)")
+	}
+	if _, ok := a.staticChildren["b"]; ok {
+		t.Error("empty branch /a/b should be pruned")
+	}
+
+	if err := router.RemoveRoute("GET", "/a/x"); err != nil {
+		t.Fatalf("RemoveRoute error = %v", err)
+	}
+	if _, ok := router.table.Load().roots["GET"]; ok {
+		t.Error("empty method root should be removed")
+	}
+
+	// 移除后可以重新注册
+	_ = router.AddRoute("GET", "/a/b/c/:name", handler)
+	ctx, _, _, ok := router.Match("GET", "/a/b/c/1", context.Background())
+	if !ok {
+		t.Fatal("re-added route should match")
+	}
+	if v, _ := GetParam(ctx, "name"); v != "1" {
+		t.Errorf("param name = %q, want 1", v)
+	}
+	ReleaseContext(ctx)
+}
+
+// 测试移除路由后热点缓存失效
+func TestRouter_RemoveRoute_InvalidatesHotCache(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/users/:id", handler)
+	_ = router.AddRoute("GET", "/posts/:id", handler)
+
+	for i := 0; i < hotCacheThreshold+10; i++ {
+		for _, path := range []string{"/users/1", "/posts/1"} {
+			ctx, _, _, _ := router.Match("GET", path, context.Background())
+			ReleaseContext(ctx)
+		}
+	}
+	if total, _ := router.CacheStats(); total != 2 {
+		t.Fatalf("cache entries = %d, want 2", total)
+	}
+
+	_ = router.RemoveRoute("GET", "/users/:id")
+	if _, _, _, ok := router.Match("GET", "/users/1", context.Background()); ok {
+		t.Error("cached entry of removed route should be invalidated")
+	}
+	if total, _ := router.CacheStats(); total != 1 {
+		t.Errorf("cache entries after remove = %d, want 1", total)
+	}
+
+	// 新增静态路由覆盖缓存的参数匹配
+	static := func(ctx context.Context) error { return errors.New("static") }
+	_ = router.AddRoute("GET", "/posts/1", static)
+	ctx, h, _, ok := router.Match("GET", "/posts/1", context.Background())
+	if !ok || h(ctx) == nil {
+		t.Error("new static route should shadow cached param match")
+	}
+	ReleaseContext(ctx)
+}
+
+// 测试原子替换路由表
+func TestRouter_Swap(t *testing.T) {
+	oldHandler := func(ctx context.Context) error { return errors.New("old") }
+	newHandler := func(ctx context.Context) error { return errors.New("new") }
+
+	router := NewRouter()
+	_ = router.AddRoute("GET", "/users/:id", oldHandler)
+	_ = router.AddRoute("GET", "/legacy", oldHandler)
+	for i := 0; i < hotCacheThreshold+10; i++ {
+		ctx, _, _, _ := router.Match("GET", "/users/1", context.Background())
+		ReleaseContext(ctx)
+	}
+
+	next := NewRouter()
+	_ = next.AddRoute("GET", "/users/:uid", newHandler)
+	router.Swap(next)
+
+	ctx, h, _, ok := router.Match("GET", "/users/1", context.Background())
+	if !ok {
+		t.Fatal("route from new table should match")
+	}
+	if err := h(ctx); err == nil || err.Error() != "new" {
+		t.Errorf("handler = %v, want new", err)
+	}
+	if v, _ := GetParam(ctx, "uid"); v != "1" {
+		t.Errorf("param uid = %q, want 1", v)
+	}
+	ReleaseContext(ctx)
+	if _, _, _, ok := router.Match("GET", "/legacy", context.Background()); ok {
+		t.Error("route from old table should not match")
+	}
+
+	if m := router.Metrics(); m.ParamRoutes != 1 || m.StaticRoutes != 0 {
+		t.Errorf("route counts after swap = %+v", m)
+	}
+	// newRouter 的路由表已转移
+	if _, _, _, ok := next.Match("GET", "/users/1", context.Background()); ok {
+		t.Error("newRouter should be empty after Swap")
+	}
+}
+
+// 测试替换路由表与并发匹配
+func TestRouter_SwapConcurrentMatch(t *testing.T) {
+	router := NewRouter()
+	build := func(tag string) *Router {
+		r := NewRouter()
+		h := func(ctx context.Context) error { return errors.New(tag) }
+		_ = r.AddRoute("GET", "/users/:id", h)
+		_ = r.AddRoute("GET", "/static/path", h)
+		_ = r.AddRoute("GET", "/files/*path", h)
+		return r
+	}
+	router.Swap(build("v0"))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for _, path := range []string{"/users/1", "/static/path", "/files/a/b"} {
+					ctx, h, mws, ok := router.Match("GET", path, context.Background())
+					if !ok {
+						t.Errorf("Match(%s) failed during swap", path)
+						return
+					}
+					_ = ExecuteHandler(ctx, h, mws)
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 50; i++ {
+		router.Swap(build(fmt.Sprintf("v%d", i)))
+		_ = router.RemoveRoute("GET", "/static/path")
+		_ = router.AddRoute("GET", "/static/path", func(ctx context.Context) error { return nil })
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// 测试移除路由后匹配仍为零分配
+func TestRouter_MatchZeroAllocAfterRemove(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/users/:id", handler)
+	_ = router.AddRoute("GET", "/users/:id/posts", handler)
+	_ = router.RemoveRoute("GET", "/users/:id/posts")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ctx, _, _, _ := router.Match("GET", "/users/123", context.Background())
+		ReleaseContext(ctx)
+	})
+	if allocs != 0 {
+		t.Errorf("Match allocs = %v, want 0", allocs)
+	}
+}
+
+// 测试查询路径允许的方法
+func TestRouter_AllowedMethods(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("PUT", "/users/:id", handler)
+	_ = router.AddRoute("GET", "/users/:id", handler)
+	_ = router.AddRoute("DELETE", "/users/:id/posts", handler)
+	_ = router.AddRoute("POST", "/files/*path", handler)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/users/1", []string{"GET", "PUT"}},
+		{"/users/1/", []string{"GET", "PUT"}},
+		{"/users/1/posts", []string{"DELETE"}},
+		{"/users", nil}, // 中间节点没有处理器
+		{"/files/a/b", []string{"POST"}},
+		{"/missing", nil},
+	}
+	for _, tt := range tests {
+		if got := router.AllowedMethods(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllowedMethods(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// 测试同一位置的多个参数节点按约束优先级匹配
+func TestRouter_ParamConstraints(t *testing.T) {
+	router := NewRouter()
+	route := func(name string) HandlerFunc {
+		return func(ctx context.Context) error {
+			SetValue(ctx, "route", name)
+			return nil
+		}
+	}
+	// 注册顺序与优先级无关
+	_ = router.AddRoute("GET", "/users/:name", route("name"))
+	_ = router.AddRoute("GET", "/users/:slug<re:[a-z]+-[a-z]+>", route("slug"))
+	_ = router.AddRoute("GET", "/users/:id<int>", route("id"))
+	_ = router.AddRoute("GET", "/users/:uuid<uuid>", route("uuid"))
+
+	tests := []struct {
+		path, route, key, value string
+	}{
+		{"/users/42", "id", "id", "42"},
+		{"/users/123e4567-e89b-12d3-a456-426614174000", "uuid", "uuid", "123e4567-e89b-12d3-a456-426614174000"},
+		{"/users/john-doe", "slug", "slug", "john-doe"},
+		{"/users/john", "name", "name", "john"},
+	}
+	for _, tt := range tests {
+		ctx, h, _, ok := router.Match("GET", tt.path, context.Background())
+		if !ok {
+			t.Errorf("Match(%s) failed", tt.path)
+			continue
+		}
+		_ = h(ctx)
+		if got, _ := ctx.(*routeContext).GetValue("route"); got != tt.route {
+			t.Errorf("Match(%s) route = %v, want %s", tt.path, got, tt.route)
+		}
+		if got, _ := GetParam(ctx, tt.key); got != tt.value {
+			t.Errorf("Match(%s) param %s = %q, want %q", tt.path, tt.key, got, tt.value)
+		}
+		ReleaseContext(ctx)
+	}
+
+	// 约束不满足且没有无约束兄弟节点时不匹配
+	_ = router.AddRoute("GET", "/orders/:id<int>", route("order"))
+	if _, _, _, ok := router.Match("GET", "/orders/abc", context.Background()); ok {
+		t.Error("/orders/abc should not match :id<int>")
+	}
+}
+
+// 测试后续片段匹配失败时回溯到兄弟节点
+func TestRouter_ParamBacktracking(t *testing.T) {
+	router := NewRouter()
+	var matched string
+	route := func(name string) HandlerFunc {
+		return func(ctx context.Context) error {
+			matched = name
+			return nil
+		}
+	}
+	_ = router.AddRoute("GET", "/users/:id<int>/posts", route("posts"))
+	_ = router.AddRoute("GET", "/users/:name/profile", route("profile"))
+	_ = router.AddRoute("GET", "/users/:name", route("user"))
+	_ = router.AddRoute("GET", "/users/new/edit", route("static"))
+	_ = router.AddRoute("GET", "/files/:id<int>/raw", route("raw"))
+	_ = router.AddRoute("GET", "/files/*path", route("files"))
+
+	tests := []struct {
+		path, want string
+		params     map[string]string
+	}{
+		{"/users/42/posts", "posts", map[string]string{"id": "42"}},
+		{"/users/42/profile", "profile", map[string]string{"name": "42"}}, // :id<int> 满足约束但后续片段不匹配
+		{"/users/42", "user", map[string]string{"name": "42"}},            // :id<int> 节点没有处理器
+		{"/users/new", "user", map[string]string{"name": "new"}},          // 静态节点没有处理器
+		{"/users/new/profile", "profile", map[string]string{"name": "new"}},
+		{"/users/new/edit", "static", nil},
+		{"/files/7/raw", "raw", map[string]string{"id": "7"}},
+		{"/files/7/other", "files", map[string]string{"*": "7/other"}},
+	}
+	for _, tt := range tests {
+		ctx, h, mws, ok := router.Match("GET", tt.path, context.Background())
+		if !ok {
+			t.Errorf("Match(%s) failed", tt.path)
+			continue
+		}
+		for k, v := range tt.params {
+			if got, _ := GetParam(ctx, k); got != v {
+				t.Errorf("Match(%s) param %s = %q, want %q", tt.path, k, got, v)
+			}
+		}
+		_ = ExecuteHandler(ctx, h, mws)
+		if matched != tt.want {
+			t.Errorf("Match(%s) = %s, want %s", tt.path, matched, tt.want)
+		}
+	}
+
+	// 移除约束节点后兄弟节点仍可匹配
+	if err := router.RemoveRoute("GET", "/users/:id<int>/posts"); err != nil {
+		t.Fatalf("RemoveRoute error = %v", err)
+	}
+	if err := router.RemoveRoute("GET", "/users/:id/posts"); !errors.Is(err, ErrRouteNotFound) {
+		t.Errorf("RemoveRoute with different constraint error = %v, want ErrRouteNotFound", err)
+	}
+	ctx, h, mws, ok := router.Match("GET", "/users/42", context.Background())
+	if !ok {
+		t.Fatal("Match(/users/42) failed after remove")
+	}
+	_ = ExecuteHandler(ctx, h, mws)
+	if matched != "user" {
+		t.Errorf("Match(/users/42) = %s, want user", matched)
+	}
+}
+
+// 测试整数参数访问器
+func TestRouter_GetParamInt(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/users/:id<int>/posts/:page", handler)
+
+	ctx, _, _, ok := router.Match("GET", "/users/-12/posts/3", context.Background())
+	if !ok {
+		t.Fatal("Match failed")
+	}
+	defer ReleaseContext(ctx)
+
+	if id, ok := GetParamInt(ctx, "id"); !ok || id != -12 {
+		t.Errorf("GetParamInt(id) = %d, %v", id, ok)
+	}
+	// 无约束参数按十进制解析
+	if page, ok := GetParamInt(ctx, "page"); !ok || page != 3 {
+		t.Errorf("GetParamInt(page) = %d, %v", page, ok)
+	}
+	if _, ok := GetParamInt(ctx, "missing"); ok {
+		t.Error("GetParamInt(missing) should fail")
+	}
+	if _, ok := GetParamInt(context.Background(), "id"); ok {
+		t.Error("GetParamInt on non-routeContext should fail")
+	}
+}
+
+// 测试约束参数匹配零分配（包括回溯和热点缓存命中）
+func TestRouter_ParamConstraintsZeroAlloc(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	_ = router.AddRoute("GET", "/users/:id<int>/posts", handler)
+	_ = router.AddRoute("GET", "/users/:slug<re:[a-z-]+>", handler)
+	_ = router.AddRoute("GET", "/users/:name/profile", handler)
+
+	for _, path := range []string{"/users/42/posts", "/users/john-doe", "/users/42/profile"} {
+		allocs := testing.AllocsPerRun(200, func() {
+			ctx, _, _, ok := router.Match("GET", path, context.Background())
+			if !ok {
+				t.Fatalf("Match(%s) failed", path)
+			}
+			_, _ = GetParamInt(ctx, "id")
+			ReleaseContext(ctx)
+		})
+		if allocs != 0 {
+			t.Errorf("Match(%s) allocs = %v, want 0", path, allocs)
+		}
+	}
+}
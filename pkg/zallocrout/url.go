@@ -0,0 +1,125 @@
+package zallocrout
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrRouteNameNotFound 路由名称未注册（或对应路由已被移除、路由表已被 Swap 替换）
+var ErrRouteNameNotFound = errors.New("route name not found")
+
+// 命名路由（注册时的路径片段，用于反向生成 URL）
+type namedRoute struct {
+	method        string
+	segs          []string
+	trailingSlash bool // 注册路径带末尾斜杠
+}
+
+// 创建命名路由（复制片段，调用方的片段切片通常在栈上）
+func newNamedRoute(method string, segs []string, trailingSlash bool) *namedRoute {
+	return &namedRoute{method: method, segs: append([]string(nil), segs...), trailingSlash: trailingSlash}
+}
+
+// 是否与指定方法和路径片段为同一路由
+func (nr *namedRoute) is(method string, segs []string) bool {
+	if nr.method != method || len(nr.segs) != len(segs) {
+		return false
+	}
+	for i := range segs {
+		if nr.segs[i] != segs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// 注销指向指定路由的所有名称，调用方需持有路由表写锁
+func (t *routeTable) removeNames(method string, segs []string) {
+	for name, nr := range t.names {
+		if nr.is(method, segs) {
+			delete(t.names, name)
+		}
+	}
+}
+
+// 按路由名称生成路径
+// params: 参数键值对（"id", "42", "path", "a/b.txt"），通配符参数也可使用 "*" 作为键
+// 参数值按路径片段转义，并校验是否满足参数约束；名称未注册时返回 ErrRouteNameNotFound，缺少参数、多余参数或约束不满足时返回错误
+func (r *Router) URL(name string, params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", errors.New("route params must be key/value pairs")
+	}
+
+	table := r.rlockTable()
+	nr, ok := table.names[name]
+	table.mu.RUnlock()
+	if !ok {
+		return "", ErrRouteNameNotFound
+	}
+
+	used := 0
+	var b strings.Builder
+	for _, seg := range nr.segs {
+		b.WriteByte('/')
+		switch {
+		case isParamSeg(seg):
+			paramName, constraint, _ := parseParamSeg(seg) // 注册时已检查
+			value, ok := lookupParam(params, paramName)
+			if !ok {
+				return "", errors.New("missing route param: " + paramName)
+			}
+			if value == "" || strings.Contains(value, "/") {
+				return "", errors.New("route param cannot be empty or contain '/': " + paramName)
+			}
+			if _, ok := constraint.match(value); !ok {
+				return "", errors.New("route param does not match constraint: " + paramName)
+			}
+			b.WriteString(url.PathEscape(value))
+			used++
+		case isWildcardSeg(seg):
+			value, ok := lookupParam(params, seg[1:])
+			if !ok {
+				value, ok = lookupParam(params, "*")
+			}
+			if !ok {
+				return "", errors.New("missing route param: " + seg[1:])
+			}
+			value = strings.TrimPrefix(value, "/")
+			if value == "" {
+				return "", errors.New("route param cannot be empty: " + seg[1:])
+			}
+			// 逐段转义，保留分隔符
+			for i, part := range strings.Split(value, "/") {
+				if i > 0 {
+					b.WriteByte('/')
+				}
+				b.WriteString(url.PathEscape(part))
+			}
+			used++
+		default:
+			b.WriteString(seg)
+		}
+	}
+
+	if used*2 != len(params) {
+		return "", errors.New("unknown route param for route: " + name)
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+	if nr.trailingSlash {
+		b.WriteByte('/')
+	}
+	return b.String(), nil
+}
+
+// 在键值对中查找参数
+func lookupParam(params []string, key string) (string, bool) {
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i] == key {
+			return params[i+1], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,114 @@
+package zallocrout
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// 测试按名称生成路径
+func TestRouter_URL(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+	routes := []struct {
+		name, path string
+	}{
+		{"home", "/"},
+		{"user", "/users/:id<int>"},
+		{"post", "/users/:user/posts/:slug<re:[a-z-]+>"},
+		{"file", "/files/*path"},
+	}
+	for _, rt := range routes {
+		if err := router.AddNamedRoute(rt.name, "GET", rt.path, handler); err != nil {
+			t.Fatalf("AddNamedRoute(%s) error = %v", rt.name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		params  []string
+		want    string
+		wantErr bool
+	}{
+		{"home", nil, "/", false},
+		{"user", []string{"id", "42"}, "/users/42", false},
+		{"post", []string{"slug", "hello-world", "user", "张 三"}, "/users/%E5%BC%A0%20%E4%B8%89/posts/hello-world", false},
+		{"file", []string{"path", "docs/a b.txt"}, "/files/docs/a%20b.txt", false},
+		{"file", []string{"*", "/x/y"}, "/files/x/y", false},
+		{"user", []string{"id", "abc"}, "", true}, // 不满足约束
+		{"user", nil, "", true},                               // 缺少参数
+		{"user", []string{"id", "1", "extra", "2"}, "", true}, // 多余参数
+		{"user", []string{"id"}, "", true},                    // 键值不成对
+		{"post", []string{"user", "a/b", "slug", "x"}, "", true},
+		{"file", []string{"path", ""}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := router.URL(tt.name, tt.params...)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v; want %q, wantErr %v", tt.name, tt.params, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if _, err := router.URL("missing"); !errors.Is(err, ErrRouteNameNotFound) {
+		t.Errorf("URL(missing) error = %v, want ErrRouteNameNotFound", err)
+	}
+
+	// 生成的路径可以匹配回原路由
+	path, _ := router.URL("post", "user", "bob", "slug", "my-post")
+	ctx, _, _, ok := router.Match("GET", path, context.Background())
+	if !ok {
+		t.Fatalf("Match(%s) failed", path)
+	}
+	if slug, _ := GetParam(ctx, "slug"); slug != "my-post" {
+		t.Errorf("slug = %q", slug)
+	}
+	ReleaseContext(ctx)
+}
+
+// 测试命名路由的注册和注销
+func TestRouter_NamedRouteLifecycle(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx context.Context) error { return nil }
+
+	if err := router.AddNamedRoute("", "GET", "/a", handler); err == nil {
+		t.Error("empty name should fail")
+	}
+	_ = router.AddNamedRoute("a", "GET", "/a", handler)
+	if err := router.AddNamedRoute("a", "GET", "/b", handler); err == nil {
+		t.Error("duplicate name should fail")
+	}
+	if _, _, _, ok := router.Match("GET", "/b", context.Background()); ok {
+		t.Error("route with duplicate name should not be registered")
+	}
+
+	// 分组路由使用完整路径
+	api := router.Group("/api/v1")
+	_ = api.AddNamedRoute("user", "GET", "/users/:id", handler)
+	if got, err := router.URL("user", "id", "7"); err != nil || got != "/api/v1/users/7" {
+		t.Errorf("group URL = %q, %v", got, err)
+	}
+
+	// 移除路由后名称失效
+	if err := router.RemoveRoute("GET", "/a"); err != nil {
+		t.Fatalf("RemoveRoute error = %v", err)
+	}
+	if _, err := router.URL("a"); !errors.Is(err, ErrRouteNameNotFound) {
+		t.Errorf("URL of removed route error = %v, want ErrRouteNameNotFound", err)
+	}
+	if err := router.AddNamedRoute("a", "GET", "/a2", handler); err != nil {
+		t.Errorf("name should be reusable after remove: %v", err)
+	}
+
+	// 名称随路由表替换
+	next := NewRouter()
+	_ = next.AddNamedRoute("next", "GET", "/next", handler)
+	router.Swap(next)
+	if _, err := router.URL("a"); !errors.Is(err, ErrRouteNameNotFound) {
+		t.Errorf("old names should be replaced by Swap, error = %v", err)
+	}
+	if got, err := router.URL("next"); err != nil || got != "/next" {
+		t.Errorf("swapped URL = %q, %v", got, err)
+	}
+	if _, err := next.URL("next"); !errors.Is(err, ErrRouteNameNotFound) {
+		t.Errorf("names should move with the route table, error = %v", err)
+	}
+}